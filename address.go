@@ -0,0 +1,23 @@
+package gomerk
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// ToChecksumAddress returns the EIP-55 mixed-case form of a 20-byte hex address.
+func ToChecksumAddress(addr string) (string, error) {
+	s := strings.ToLower(strings.TrimPrefix(addr, "0x"))
+	if data, err := hex.DecodeString(s); err != nil || len(data) != 20 {
+		return "", ErrInvalidAddress
+	}
+	h := Keccak256([]byte(s))
+	out := []byte(s)
+	for i, c := range out {
+		nibble := h[i/2] >> (4 * (1 - i%2)) & 0xf
+		if c >= 'a' && nibble >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out), nil
+}
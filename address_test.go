@@ -0,0 +1,34 @@
+package gomerk_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestToChecksumAddress(t *testing.T) {
+	tests := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+	for _, want := range tests {
+		got, err := gomerk.ToChecksumAddress(strings.ToLower(want))
+		if err != nil {
+			t.Fatalf("%s: %v", want, err)
+		}
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestToChecksumAddressInvalid(t *testing.T) {
+	for _, addr := range []string{"", "0x1234", "0xzz" + strings.Repeat("0", 38)} {
+		if _, err := gomerk.ToChecksumAddress(addr); err != gomerk.ErrInvalidAddress {
+			t.Errorf("%q: got %v, want ErrInvalidAddress", addr, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package gomerk
+
+import "runtime"
+
+// LeafProof is a leaf hash and the proof claiming its inclusion.
+type LeafProof struct {
+	Leaf  Bytes32
+	Proof []string
+}
+
+// BatchResult is the outcome of verifying a batch of proofs.
+type BatchResult struct {
+	// Valid reports, for each item, whether its proof verified.
+	Valid []bool
+	// Errs holds, for each item, the error that kept it from being
+	// verified, such as a malformed proof, or nil.
+	Errs []error
+	// AllValid reports whether every item verified.
+	AllValid bool
+}
+
+// VerifyBatch checks each item's proof against root, as Verify does, on
+// GOMAXPROCS goroutines.
+func VerifyBatch(root string, items []LeafProof) BatchResult {
+	return (&Verifier{}).VerifyBatch(root, items)
+}
+
+// VerifyStandardBatch checks each item's value and proof against root, as
+// VerifyStandard does, on GOMAXPROCS goroutines.
+func VerifyStandardBatch(root string, leafEncoding []string, items []ProofItem) BatchResult {
+	return (&Verifier{}).VerifyStandardBatch(root, leafEncoding, items)
+}
+
+// VerifyBatch is VerifyBatch with the verifier's options. WithParallelism
+// sets the number of goroutines.
+func (v *Verifier) VerifyBatch(root string, items []LeafProof) BatchResult {
+	return v.verifyBatch(len(items), func(i int) (bool, error) {
+		return v.Verify(root, items[i].Leaf, items[i].Proof)
+	})
+}
+
+// VerifyStandardBatch is VerifyStandardBatch with the verifier's options.
+// WithParallelism sets the number of goroutines.
+func (v *Verifier) VerifyStandardBatch(root string, leafEncoding []string, items []ProofItem) BatchResult {
+	return v.verifyBatch(len(items), func(i int) (bool, error) {
+		return v.VerifyStandard(root, leafEncoding, items[i].Value, items[i].Proof)
+	})
+}
+
+func (v *Verifier) verifyBatch(n int, verify func(i int) (bool, error)) BatchResult {
+	workers := v.opts.parallelism
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	r := BatchResult{Valid: make([]bool, n), Errs: make([]error, n)}
+	parallelFor(n, workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			r.Valid[i], r.Errs[i] = verify(i)
+		}
+	})
+	r.AllValid = true
+	for _, ok := range r.Valid {
+		r.AllValid = r.AllValid && ok
+	}
+	return r
+}
@@ -0,0 +1,89 @@
+package gomerk_test
+
+import (
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func batchItems(b testing.TB, n int) (*gomerk.StandardMerkleTree, []gomerk.ProofItem) {
+	b.Helper()
+	vals := airdropData(n)
+	tree, err := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
+	if err != nil {
+		b.Fatal(err)
+	}
+	items := make([]gomerk.ProofItem, n)
+	for i, v := range vals {
+		proof, err := tree.GetProof(v)
+		if err != nil {
+			b.Fatal(err)
+		}
+		items[i] = gomerk.ProofItem{Value: v, Proof: proof}
+	}
+	return tree, items
+}
+
+func TestVerifyBatch(t *testing.T) {
+	// Large enough to be split across goroutines.
+	tree, items := batchItems(t, 3000)
+	enc := tree.LeafEncoding()
+	leaves := make([]gomerk.LeafProof, len(items))
+	for i, it := range items {
+		h, err := tree.LeafHash(it.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaves[i] = gomerk.LeafProof{Leaf: h, Proof: it.Proof}
+	}
+
+	for _, r := range []gomerk.BatchResult{
+		gomerk.VerifyStandardBatch(tree.Root(), enc, items),
+		gomerk.VerifyBatch(tree.Root(), leaves),
+		gomerk.NewVerifier(gomerk.WithParallelism(3)).VerifyBatch(tree.Root(), leaves),
+	} {
+		if !r.AllValid || len(r.Valid) != len(items) {
+			t.Fatal("valid batch rejected")
+		}
+	}
+
+	items[10].Proof = items[11].Proof
+	items[20].Proof = []string{"0xzz"}
+	items[30].Value = []any{"not an address", "1"}
+	leaves[40].Proof = leaves[41].Proof
+	r := gomerk.VerifyStandardBatch(tree.Root(), enc, items)
+	if r.AllValid {
+		t.Fatal("invalid batch accepted")
+	}
+	for i := range items {
+		want := i != 10 && i != 20 && i != 30
+		if r.Valid[i] != want || (r.Errs[i] != nil) != (i == 20 || i == 30) {
+			t.Fatalf("item %d: valid %v, err %v", i, r.Valid[i], r.Errs[i])
+		}
+	}
+	r = gomerk.VerifyBatch(tree.Root(), leaves)
+	if r.AllValid || r.Valid[40] || !r.Valid[41] {
+		t.Fatal("wrong leaf proof accepted")
+	}
+
+	if r := gomerk.VerifyBatch(tree.Root(), nil); !r.AllValid || len(r.Valid) != 0 {
+		t.Fatal("empty batch")
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	tree, items := batchItems(b, 20000)
+	enc := tree.LeafEncoding()
+	b.Run("loop", func(b *testing.B) {
+		for b.Loop() {
+			for _, it := range items {
+				gomerk.VerifyStandard(tree.Root(), enc, it.Value, it.Proof)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for b.Loop() {
+			gomerk.VerifyStandardBatch(tree.Root(), enc, items)
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package gomerk
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// maxBitcoinTransactions bounds the transaction count of a partial merkle
+// tree, as Bitcoin Core does: a block of 4M weight units holds at most one
+// transaction per 240 units.
+const maxBitcoinTransactions = 4_000_000 / 240
+
+// DoubleSHA256 returns SHA-256(SHA-256(data)), Bitcoin's hash function.
+func DoubleSHA256(data []byte) Bytes32 {
+	h := sha256.Sum256(data)
+	return sha256.Sum256(h[:])
+}
+
+// bitcoinNode hashes two children in position order.
+func bitcoinNode(a, b Bytes32) Bytes32 {
+	var buf [64]byte
+	copy(buf[:32], a[:])
+	copy(buf[32:], b[:])
+	return DoubleSHA256(buf[:])
+}
+
+// TxIDFromHex parses a transaction ID as Bitcoin displays it, byte-reversed,
+// into the internal byte order hashed into merkle trees.
+func TxIDFromHex(s string) (Bytes32, error) {
+	b, err := HexToBytes32(s)
+	slices.Reverse(b[:])
+	return b, err
+}
+
+// TxIDHex formats an internal-order hash as Bitcoin displays it.
+func TxIDHex(b Bytes32) string {
+	slices.Reverse(b[:])
+	return b.Hex()[2:]
+}
+
+// BitcoinMerkleRoot returns the merkle root of a block's transaction IDs, in
+// internal byte order, pairing the last node of each odd level with itself.
+// mutated reports that some level ends with two equal nodes, so that another
+// transaction list has the same root (CVE-2012-2459); such blocks are
+// invalid.
+func BitcoinMerkleRoot(txids []Bytes32) (root Bytes32, mutated bool, err error) {
+	if len(txids) == 0 {
+		return Bytes32{}, false, ErrEmptyTree
+	}
+	level := slices.Clone(txids)
+	for len(level) > 1 {
+		for i := 0; i+1 < len(level); i += 2 {
+			mutated = mutated || level[i] == level[i+1]
+		}
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		for i := range len(level) / 2 {
+			level[i] = bitcoinNode(level[2*i], level[2*i+1])
+		}
+		level = level[:len(level)/2]
+	}
+	return level[0], mutated, nil
+}
+
+// PartialMerkleTree is the proof carried by Bitcoin's merkleblock message
+// (BIP 37): the transaction count of a block and a depth-first traversal of
+// its merkle tree, with one flag per visited node and the hashes of the
+// subtrees that were not descended into.
+type PartialMerkleTree struct {
+	Transactions uint32
+	Hashes       []Bytes32
+	Flags        []bool
+}
+
+// NewPartialMerkleTree builds the partial merkle tree of a block's
+// transaction IDs that proves those whose matches entry is true.
+func NewPartialMerkleTree(txids []Bytes32, matches []bool) (*PartialMerkleTree, error) {
+	if len(txids) == 0 {
+		return nil, ErrEmptyTree
+	}
+	if len(matches) != len(txids) {
+		return nil, ErrMismatchedCount
+	}
+	p := &PartialMerkleTree{Transactions: uint32(len(txids))}
+	p.build(p.height(), 0, txids, matches)
+	return p, nil
+}
+
+// height returns the number of levels above the transactions.
+func (p *PartialMerkleTree) height() int {
+	return bits.Len(uint(p.Transactions - 1))
+}
+
+// width returns the number of nodes at height h.
+func (p *PartialMerkleTree) width(h int) int {
+	return (int(p.Transactions) + 1<<h - 1) >> h
+}
+
+// hash returns the node at height h and position pos of the full tree.
+func (p *PartialMerkleTree) hash(h, pos int, txids []Bytes32) Bytes32 {
+	if h == 0 {
+		return txids[pos]
+	}
+	left := p.hash(h-1, 2*pos, txids)
+	right := left
+	if 2*pos+1 < p.width(h-1) {
+		right = p.hash(h-1, 2*pos+1, txids)
+	}
+	return bitcoinNode(left, right)
+}
+
+func (p *PartialMerkleTree) build(h, pos int, txids []Bytes32, matches []bool) {
+	parent := false
+	for i := pos << h; i < min((pos+1)<<h, len(txids)); i++ {
+		parent = parent || matches[i]
+	}
+	p.Flags = append(p.Flags, parent)
+	if h == 0 || !parent {
+		p.Hashes = append(p.Hashes, p.hash(h, pos, txids))
+		return
+	}
+	p.build(h-1, 2*pos, txids, matches)
+	if 2*pos+1 < p.width(h-1) {
+		p.build(h-1, 2*pos+1, txids, matches)
+	}
+}
+
+// ExtractMatches checks the tree's structure and returns the merkle root it
+// commits to, in internal byte order, with the matched transaction IDs and
+// their positions in the block. Compare the root with the block header's.
+func (p *PartialMerkleTree) ExtractMatches() (root Bytes32, matches []Bytes32, indices []int, err error) {
+	switch {
+	case p.Transactions == 0:
+		return Bytes32{}, nil, nil, ErrEmptyTree
+	case p.Transactions > maxBitcoinTransactions:
+		return Bytes32{}, nil, nil, fmt.Errorf("%w: %d transactions", ErrInvalidProof, p.Transactions)
+	case len(p.Hashes) > int(p.Transactions):
+		return Bytes32{}, nil, nil, fmt.Errorf("%w: more hashes than transactions", ErrInvalidProof)
+	case len(p.Flags) < len(p.Hashes):
+		return Bytes32{}, nil, nil, fmt.Errorf("%w: fewer flags than hashes", ErrInvalidProof)
+	}
+	x := &pmtExtractor{p: p}
+	root = x.extract(p.height(), 0)
+	switch {
+	case x.bad:
+		return Bytes32{}, nil, nil, fmt.Errorf("%w: malformed traversal", ErrInvalidProof)
+	case (x.flags+7)/8 != (len(p.Flags)+7)/8:
+		return Bytes32{}, nil, nil, fmt.Errorf("%w: unused flags", ErrInvalidProof)
+	case x.hashes != len(p.Hashes):
+		return Bytes32{}, nil, nil, fmt.Errorf("%w: unused hashes", ErrInvalidProof)
+	}
+	return root, x.matches, x.indices, nil
+}
+
+// pmtExtractor walks a partial merkle tree, consuming its flags and hashes.
+type pmtExtractor struct {
+	p             *PartialMerkleTree
+	flags, hashes int
+	matches       []Bytes32
+	indices       []int
+	bad           bool
+}
+
+func (x *pmtExtractor) extract(h, pos int) Bytes32 {
+	if x.flags >= len(x.p.Flags) {
+		x.bad = true
+		return Bytes32{}
+	}
+	parent := x.p.Flags[x.flags]
+	x.flags++
+	if h == 0 || !parent {
+		if x.hashes >= len(x.p.Hashes) {
+			x.bad = true
+			return Bytes32{}
+		}
+		hash := x.p.Hashes[x.hashes]
+		x.hashes++
+		if h == 0 && parent {
+			x.matches = append(x.matches, hash)
+			x.indices = append(x.indices, pos)
+		}
+		return hash
+	}
+	left := x.extract(h-1, 2*pos)
+	right := left
+	if 2*pos+1 < x.p.width(h-1) {
+		right = x.extract(h-1, 2*pos+1)
+		// Equal siblings would let a mutated transaction list match.
+		if right == left {
+			x.bad = true
+		}
+	}
+	return bitcoinNode(left, right)
+}
+
+// MarshalBinary encodes the tree as in a merkleblock message after the block
+// header: the transaction count, the hashes and the flag bits, least
+// significant bit first.
+func (p *PartialMerkleTree) MarshalBinary() ([]byte, error) {
+	flags := make([]byte, (len(p.Flags)+7)/8)
+	for i, f := range p.Flags {
+		if f {
+			flags[i/8] |= 1 << (i % 8)
+		}
+	}
+	b := binary.LittleEndian.AppendUint32(nil, p.Transactions)
+	b = appendCompactSize(b, uint64(len(p.Hashes)))
+	for _, h := range p.Hashes {
+		b = append(b, h[:]...)
+	}
+	b = appendCompactSize(b, uint64(len(flags)))
+	return append(b, flags...), nil
+}
+
+// UnmarshalBinary decodes a tree encoded by MarshalBinary. Flags is padded
+// to a whole number of bytes with false.
+func (p *PartialMerkleTree) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("%w: short partial merkle tree", ErrInvalidFormat)
+	}
+	q := PartialMerkleTree{Transactions: binary.LittleEndian.Uint32(data)}
+	data = data[4:]
+	n, data, err := readCompactSize(data)
+	if err != nil {
+		return err
+	}
+	if n > uint64(len(data))/32 {
+		return fmt.Errorf("%w: truncated hashes", ErrInvalidFormat)
+	}
+	q.Hashes = make([]Bytes32, n)
+	for i := range q.Hashes {
+		q.Hashes[i] = Bytes32(data[:32])
+		data = data[32:]
+	}
+	n, data, err = readCompactSize(data)
+	if err != nil {
+		return err
+	}
+	if n != uint64(len(data)) {
+		return fmt.Errorf("%w: %d flag bytes, have %d", ErrInvalidFormat, n, len(data))
+	}
+	q.Flags = make([]bool, 8*len(data))
+	for i := range q.Flags {
+		q.Flags[i] = data[i/8]&(1<<(i%8)) != 0
+	}
+	*p = q
+	return nil
+}
+
+// appendCompactSize appends n in Bitcoin's variable-length integer format.
+func appendCompactSize(b []byte, n uint64) []byte {
+	switch {
+	case n < 0xfd:
+		return append(b, byte(n))
+	case n <= 0xffff:
+		return binary.LittleEndian.AppendUint16(append(b, 0xfd), uint16(n))
+	case n <= 0xffffffff:
+		return binary.LittleEndian.AppendUint32(append(b, 0xfe), uint32(n))
+	default:
+		return binary.LittleEndian.AppendUint64(append(b, 0xff), n)
+	}
+}
+
+// readCompactSize reads a canonically encoded variable-length integer.
+func readCompactSize(b []byte) (uint64, []byte, error) {
+	if len(b) == 0 {
+		return 0, nil, fmt.Errorf("%w: truncated compact size", ErrInvalidFormat)
+	}
+	size := map[byte]int{0xfd: 2, 0xfe: 4, 0xff: 8}[b[0]]
+	if size == 0 {
+		return uint64(b[0]), b[1:], nil
+	}
+	if len(b) < 1+size {
+		return 0, nil, fmt.Errorf("%w: truncated compact size", ErrInvalidFormat)
+	}
+	var buf [8]byte
+	copy(buf[:], b[1:1+size])
+	n := binary.LittleEndian.Uint64(buf[:])
+	if n < map[int]uint64{2: 0xfd, 4: 0x10000, 8: 0x100000000}[size] {
+		return 0, nil, fmt.Errorf("%w: non-canonical compact size", ErrInvalidFormat)
+	}
+	return n, b[1+size:], nil
+}
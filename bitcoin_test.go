@@ -0,0 +1,166 @@
+package gomerk_test
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+// Block 100000 of the Bitcoin main chain.
+var (
+	block100000 = []string{
+		"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+		"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+		"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+		"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+	}
+	block100000Root = "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766"
+)
+
+func txids(t *testing.T, hexes []string) []gomerk.Bytes32 {
+	t.Helper()
+	ids := make([]gomerk.Bytes32, len(hexes))
+	for i, s := range hexes {
+		var err error
+		if ids[i], err = gomerk.TxIDFromHex(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ids
+}
+
+func randomTxIDs(r *rand.Rand, n int) []gomerk.Bytes32 {
+	ids := make([]gomerk.Bytes32, n)
+	for i := range ids {
+		for j := range ids[i] {
+			ids[i][j] = byte(r.Uint32())
+		}
+	}
+	return ids
+}
+
+func TestBitcoinMerkleRoot(t *testing.T) {
+	ids := txids(t, block100000)
+	root, mutated, err := gomerk.BitcoinMerkleRoot(ids)
+	if err != nil || mutated {
+		t.Fatal(err, mutated)
+	}
+	if got := gomerk.TxIDHex(root); got != block100000Root {
+		t.Fatalf("root = %s, want %s", got, block100000Root)
+	}
+
+	if root, _, _ := gomerk.BitcoinMerkleRoot(ids[:1]); root != ids[0] {
+		t.Fatal("single transaction root is not its txid")
+	}
+	if _, _, err := gomerk.BitcoinMerkleRoot(nil); !errors.Is(err, gomerk.ErrEmptyTree) {
+		t.Fatal(err)
+	}
+
+	// Three transactions hash like four with the last repeated, which is
+	// reported as mutated.
+	odd, mutated, _ := gomerk.BitcoinMerkleRoot(ids[:3])
+	if mutated {
+		t.Fatal("odd level reported as mutated")
+	}
+	dup, mutated, _ := gomerk.BitcoinMerkleRoot(append(ids[:3:3], ids[2]))
+	if odd != dup || !mutated {
+		t.Fatal("duplicated transaction not detected")
+	}
+}
+
+func TestPartialMerkleTree(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, n := range []int{1, 2, 3, 4, 5, 7, 9, 16, 17, 56, 100} {
+		ids := randomTxIDs(r, n)
+		want, _, _ := gomerk.BitcoinMerkleRoot(ids)
+		for _, p := range []float64{0, 0.01, 0.1, 0.5, 1} {
+			matches := make([]bool, n)
+			var wantIDs []gomerk.Bytes32
+			var wantIdx []int
+			for i := range matches {
+				if matches[i] = r.Float64() < p; matches[i] {
+					wantIDs = append(wantIDs, ids[i])
+					wantIdx = append(wantIdx, i)
+				}
+			}
+			pmt, err := gomerk.NewPartialMerkleTree(ids, matches)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, _ := pmt.MarshalBinary()
+			var dec gomerk.PartialMerkleTree
+			if err := dec.UnmarshalBinary(b); err != nil {
+				t.Fatal(err)
+			}
+			root, got, idx, err := dec.ExtractMatches()
+			if err != nil {
+				t.Fatalf("n=%d p=%v: %v", n, p, err)
+			}
+			if root != want || !slices.Equal(got, wantIDs) || !slices.Equal(idx, wantIdx) {
+				t.Fatalf("n=%d p=%v: extracted %v at %v", n, p, got, idx)
+			}
+		}
+	}
+}
+
+func TestPartialMerkleTreeEncoding(t *testing.T) {
+	ids := txids(t, block100000)
+	pmt, _ := gomerk.NewPartialMerkleTree(ids, []bool{false, false, true, false})
+	b, _ := pmt.MarshalBinary()
+	// Count, three hashes (the left pair's parent and both right leaves) and
+	// one flag byte 0b1101 for root, left pair, right pair, match.
+	if len(b) != 4+1+3*32+1+1 || b[0] != 4 || b[4] != 3 || b[len(b)-2] != 1 || b[len(b)-1] != 0b1101 {
+		t.Fatalf("encoding = %x", b)
+	}
+
+	var dec gomerk.PartialMerkleTree
+	for _, bad := range [][]byte{
+		b[:3],
+		b[:len(b)-1],
+		append(slices.Clone(b), 0),
+		{4, 0, 0, 0, 0xfd, 1, 0},
+	} {
+		if err := dec.UnmarshalBinary(bad); !errors.Is(err, gomerk.ErrInvalidFormat) {
+			t.Errorf("UnmarshalBinary(%x) = %v", bad, err)
+		}
+	}
+}
+
+func TestPartialMerkleTreeInvalid(t *testing.T) {
+	ids := txids(t, block100000)
+	valid := func() *gomerk.PartialMerkleTree {
+		p, _ := gomerk.NewPartialMerkleTree(ids, []bool{false, true, false, false})
+		return p
+	}
+	for name, mutate := range map[string]func(*gomerk.PartialMerkleTree){
+		"no transactions": func(p *gomerk.PartialMerkleTree) { p.Transactions = 0 },
+		"too many":        func(p *gomerk.PartialMerkleTree) { p.Transactions = 1 << 30 },
+		"extra hash":      func(p *gomerk.PartialMerkleTree) { p.Hashes = append(p.Hashes, gomerk.Bytes32{}) },
+		"missing hash":    func(p *gomerk.PartialMerkleTree) { p.Hashes = p.Hashes[:len(p.Hashes)-1] },
+		"missing flags":   func(p *gomerk.PartialMerkleTree) { p.Flags = p.Flags[:2] },
+		"extra flag byte": func(p *gomerk.PartialMerkleTree) { p.Flags = append(p.Flags, make([]bool, 8)...) },
+		"equal siblings":  func(p *gomerk.PartialMerkleTree) { p.Hashes[1] = p.Hashes[0] },
+	} {
+		p := valid()
+		mutate(p)
+		if _, _, _, err := p.ExtractMatches(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+
+	// A changed hash still extracts, but to another root.
+	p := valid()
+	p.Hashes[0][0] ^= 1
+	root, _, _, err := p.ExtractMatches()
+	want, _, _ := gomerk.BitcoinMerkleRoot(ids)
+	if err != nil || root == want {
+		t.Fatal(err)
+	}
+
+	if _, err := gomerk.NewPartialMerkleTree(ids, []bool{true}); !errors.Is(err, gomerk.ErrMismatchedCount) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/pyroth/gomerk/boltstore
+
+go 1.25.5
+
+require (
+	github.com/pyroth/gomerk v0.0.0
+	go.etcd.io/bbolt v1.4.3
+)
+
+require (
+	golang.org/x/crypto v0.46.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+)
+
+replace github.com/pyroth/gomerk => ../
@@ -0,0 +1,112 @@
+// Package boltstore implements gomerk.TreeStore on a bbolt database. It is a
+// separate module so that only its users depend on bbolt.
+package boltstore
+
+import (
+	"encoding/binary"
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/pyroth/gomerk"
+)
+
+// batchSize is the number of buffered SetNode writes committed per
+// transaction.
+const batchSize = 1 << 14
+
+// lenKey holds the node count. Node keys are 8 bytes, so it cannot collide.
+var lenKey = []byte("len")
+
+// Store is a gomerk.TreeStore keeping nodes in a bbolt bucket, keyed by their
+// big-endian tree index. Writes are buffered and committed in batches; call
+// Flush after the last SetNode. Reads flush pending writes first.
+type Store struct {
+	db      *bolt.DB
+	bucket  []byte
+	n       int
+	pending map[int]gomerk.Bytes32
+}
+
+var _ gomerk.TreeStore = (*Store)(nil)
+
+// Open returns a Store using bucket in db, creating the bucket if needed.
+func Open(db *bolt.DB, bucket string) (*Store, error) {
+	s := &Store{db: db, bucket: []byte(bucket), pending: make(map[int]gomerk.Bytes32)}
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+		if v := b.Get(lenKey); v != nil {
+			if len(v) != 8 {
+				return gomerk.ErrInvalidFormat
+			}
+			s.n = int(binary.BigEndian.Uint64(v))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) GetNode(i int) (gomerk.Bytes32, error) {
+	var node gomerk.Bytes32
+	if i < 0 || i >= s.n {
+		return node, gomerk.ErrIndexOutOfBounds
+	}
+	if err := s.Flush(); err != nil {
+		return node, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(s.bucket).Get(key(i))
+		if len(v) != len(node) {
+			return gomerk.ErrInvalidNodeLength
+		}
+		copy(node[:], v)
+		return nil
+	})
+	return node, err
+}
+
+func (s *Store) SetNode(i int, node gomerk.Bytes32) error {
+	if i < 0 {
+		return gomerk.ErrIndexOutOfBounds
+	}
+	s.pending[i] = node
+	s.n = max(s.n, i+1)
+	if len(s.pending) >= batchSize {
+		return s.Flush()
+	}
+	return nil
+}
+
+func (s *Store) Len() int { return s.n }
+
+// Flush commits buffered writes.
+func (s *Store) Flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return errors.New("boltstore: bucket deleted")
+		}
+		for i, node := range s.pending {
+			if err := b.Put(key(i), node[:]); err != nil {
+				return err
+			}
+		}
+		return b.Put(lenKey, binary.BigEndian.AppendUint64(nil, uint64(s.n)))
+	})
+	if err != nil {
+		return err
+	}
+	clear(s.pending)
+	return nil
+}
+
+func key(i int) []byte { return binary.BigEndian.AppendUint64(nil, uint64(i)) }
@@ -0,0 +1,73 @@
+package boltstore_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/boltstore"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := make([]gomerk.Bytes32, 100)
+	for i := range leaves {
+		leaves[i][0], leaves[i][1] = byte(i), 1
+	}
+	tree, err := gomerk.BuildSimpleMerkleTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := boltstore.Open(db, "nodes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.WriteStore(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// Reopen to serve proofs from disk alone.
+	db, err = bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if s, err = boltstore.Open(db, "nodes"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 2*len(leaves)-1 {
+		t.Fatalf("Len = %d, want %d", s.Len(), 2*len(leaves)-1)
+	}
+	if err := gomerk.ValidateStore(s); err != nil {
+		t.Fatal(err)
+	}
+	root, _ := s.GetNode(0)
+	if root != tree.RootBytes() {
+		t.Errorf("root = %s, want %s", root.Hex(), tree.Root())
+	}
+	for i, v := range tree.Dump().Values {
+		got, err := gomerk.GetProofFromStore(s, v.TreeIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := tree.GetProofByIndex(i)
+		if !slices.Equal(got, want) {
+			t.Errorf("proof %d = %v, want %v", i, got, want)
+		}
+	}
+	if _, err := s.GetNode(s.Len()); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+}
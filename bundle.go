@@ -0,0 +1,156 @@
+package gomerk
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ProofBundle is a self-contained set of claims: values with their proofs,
+// together with the root and leaf encoding they were issued under. The
+// remaining fields record the tree's hashing settings as StandardTreeData
+// does, and verification applies them.
+type ProofBundle struct {
+	Root         string      `json:"root"`
+	LeafEncoding []string    `json:"leafEncoding"`
+	Entries      []ProofItem `json:"entries"`
+
+	NumberEncoding string      `json:"numberEncoding,omitempty"`
+	Hasher         string      `json:"hasher,omitempty"`
+	Encoding       string      `json:"encoding,omitempty"`
+	EIP712         *EIP712Type `json:"eip712,omitempty"`
+}
+
+// Verify reports whether every entry verifies against the bundle's own root.
+// This only shows that the bundle is internally consistent: anyone can build
+// a tree over fake values and ship its root with matching proofs. Use
+// VerifyBundleAgainstTrustedRoot to establish authenticity.
+func (b ProofBundle) Verify() (bool, error) {
+	v := &Verifier{}
+	if err := v.opts.applySettings(b.Hasher, b.NumberEncoding, b.Encoding, b.EIP712, b.LeafEncoding); err != nil {
+		return false, err
+	}
+	for i, e := range b.Entries {
+		ok, err := v.VerifyStandard(b.Root, b.LeafEncoding, e.Value, e.Proof)
+		if err != nil {
+			return false, fmt.Errorf("entry %d: %w", i, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// VerifyBundleAgainstTrustedRoot checks that the bundle's embedded root is
+// trustedRoot, returning ErrRootMismatch otherwise, and then verifies every
+// entry against it.
+func VerifyBundleAgainstTrustedRoot(bundle ProofBundle, trustedRoot string) (bool, error) {
+	if !RootsEqual(bundle.Root, trustedRoot) {
+		return false, fmt.Errorf("%w: bundle %s, trusted %s", ErrRootMismatch, bundle.Root, trustedRoot)
+	}
+	return bundle.Verify()
+}
+
+// ExportProofs returns a ProofBundle with the proof of every value, in value
+// order, recording the tree's hasher and encoding settings.
+func (t *StandardMerkleTree) ExportProofs() (ProofBundle, error) {
+	if !t.HasValues() {
+		return ProofBundle{}, ErrValuesNotRetained
+	}
+	if !commutative(t.opts.hash()) {
+		return ProofBundle{}, ErrPositionalProof
+	}
+	b := ProofBundle{
+		Root:         t.Root(),
+		LeafEncoding: slices.Clone(t.leafEncoding),
+		Entries:      make([]ProofItem, len(t.values)),
+		Hasher:       dumpHasherID(t.opts.hash()),
+		EIP712:       t.opts.eip712,
+	}
+	b.NumberEncoding, b.Encoding = t.opts.encodingSettings()
+	for i, proof := range t.AllProofs(t.opts.parallelism) {
+		value, _ := canonicalFields(t.leafEncoding, t.values[i].Value)
+		b.Entries[i] = ProofItem{Value: value, Proof: proof}
+	}
+	return b, nil
+}
+
+// VerifyProofBundle reports whether every entry of bundle verifies against
+// its root. See ProofBundle.Verify.
+func VerifyProofBundle(bundle ProofBundle) (bool, error) { return bundle.Verify() }
+
+// CompactProofBundle holds independent single proofs for several values,
+// storing each distinct sibling node once in a shared pool.
+type CompactProofBundle struct {
+	Nodes []string       `json:"nodes"`
+	Items []CompactProof `json:"items"`
+}
+
+// CompactProof is a value with its proof given as indices into the bundle's
+// node pool.
+type CompactProof struct {
+	Value []any `json:"value"`
+	Proof []int `json:"proof"`
+}
+
+// GetProofBundleCompact returns the proofs of the values at indices as a
+// CompactProofBundle. Unlike a multiproof, each proof verifies on its own.
+func (t *StandardMerkleTree) GetProofBundleCompact(indices []int) (*CompactProofBundle, error) {
+	if !t.HasValues() {
+		return nil, ErrValuesNotRetained
+	}
+	if !commutative(t.opts.hash()) {
+		return nil, ErrPositionalProof
+	}
+	b := &CompactProofBundle{Nodes: []string{}, Items: make([]CompactProof, len(indices))}
+	pool := make(map[string]int)
+	for k, i := range indices {
+		proof, err := t.GetProofByIndex(i)
+		if err != nil {
+			return nil, err
+		}
+		refs := make([]int, len(proof))
+		for j, node := range proof {
+			ref, ok := pool[node]
+			if !ok {
+				ref = len(b.Nodes)
+				pool[node] = ref
+				b.Nodes = append(b.Nodes, node)
+			}
+			refs[j] = ref
+		}
+		b.Items[k] = CompactProof{Value: t.values[i].Value, Proof: refs}
+	}
+	return b, nil
+}
+
+// Proof returns the sibling nodes of the item at i.
+func (b *CompactProofBundle) Proof(i int) ([]string, error) {
+	if i < 0 || i >= len(b.Items) {
+		return nil, ErrIndexOutOfBounds
+	}
+	proof := make([]string, len(b.Items[i].Proof))
+	for j, ref := range b.Items[i].Proof {
+		if ref < 0 || ref >= len(b.Nodes) {
+			return nil, ErrInvalidProofEncoding
+		}
+		proof[j] = b.Nodes[ref]
+	}
+	return proof, nil
+}
+
+// VerifyCompactProofBundle verifies every item of b against root, reporting
+// true only if all of them verify.
+func VerifyCompactProofBundle(root string, leafEncoding []string, b *CompactProofBundle) (bool, error) {
+	for i, it := range b.Items {
+		proof, err := b.Proof(i)
+		if err != nil {
+			return false, err
+		}
+		ok, err := VerifyStandard(root, leafEncoding, it.Value, proof)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
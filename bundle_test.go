@@ -0,0 +1,176 @@
+package gomerk_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestCompactProofBundle(t *testing.T) {
+	vals := airdropData(16)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+
+	indices := []int{0, 1, 5, 9, 15}
+	b, err := tree.GetProofBundleCompact(indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for k, i := range indices {
+		want, _ := tree.GetProofByIndex(i)
+		got, err := b.Proof(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("item %d: proof length %d, want %d", k, len(got), len(want))
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Errorf("item %d: node %d differs", k, j)
+			}
+		}
+		total += len(want)
+	}
+	if len(b.Nodes) >= total {
+		t.Errorf("pool has %d nodes, expected fewer than %d", len(b.Nodes), total)
+	}
+
+	raw, _ := json.Marshal(b)
+	var back gomerk.CompactProofBundle
+	if err := json.Unmarshal(raw, &back); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := gomerk.VerifyCompactProofBundle(tree.Root(), enc, &back); err != nil || !ok {
+		t.Errorf("got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	back.Items[2].Value = vals[3]
+	if ok, _ := gomerk.VerifyCompactProofBundle(tree.Root(), enc, &back); ok {
+		t.Error("bundle with a wrong value verified")
+	}
+	back.Items[2].Proof[0] = len(back.Nodes)
+	if _, err := gomerk.VerifyCompactProofBundle(tree.Root(), enc, &back); err != gomerk.ErrInvalidProofEncoding {
+		t.Errorf("got %v, want ErrInvalidProofEncoding", err)
+	}
+	if _, err := tree.GetProofBundleCompact([]int{16}); err != gomerk.ErrIndexOutOfBounds {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+}
+
+func TestProofBundleTrustedRoot(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	bundleFor := func(vals [][]any) gomerk.ProofBundle {
+		tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+		b := gomerk.ProofBundle{Root: tree.Root(), LeafEncoding: enc}
+		for i, v := range tree.All() {
+			proof, _ := tree.GetProofByIndex(i)
+			b.Entries = append(b.Entries, gomerk.ProofItem{Value: v, Proof: proof})
+		}
+		return b
+	}
+
+	genuine := bundleFor(airdropData(4))
+	if ok, err := gomerk.VerifyBundleAgainstTrustedRoot(genuine, genuine.Root); err != nil || !ok {
+		t.Errorf("genuine: got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	forged := bundleFor([][]any{{"0x" + padAddr(9), 1_000_000}, {"0x" + padAddr(8), 1}})
+	if ok, err := forged.Verify(); err != nil || !ok {
+		t.Errorf("forged bundle should be internally consistent: got (%v, %v)", ok, err)
+	}
+	if ok, err := gomerk.VerifyBundleAgainstTrustedRoot(forged, genuine.Root); ok || !errors.Is(err, gomerk.ErrRootMismatch) {
+		t.Errorf("forged: got (%v, %v), want ErrRootMismatch", ok, err)
+	}
+
+	forged.Root = genuine.Root
+	if ok, err := gomerk.VerifyBundleAgainstTrustedRoot(forged, genuine.Root); err != nil || ok {
+		t.Errorf("forged proofs under trusted root: got (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestStandardMerkleTreeExportProofs(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	vals := airdropData(5)
+	tree, err := gomerk.BuildStandardMerkleTree(vals, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := tree.ExportProofs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Root != tree.Root() || len(b.Entries) != len(vals) {
+		t.Fatalf("bundle has root %s and %d entries, want %s and %d", b.Root, len(b.Entries), tree.Root(), len(vals))
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back gomerk.ProofBundle
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := gomerk.VerifyProofBundle(back); err != nil || !ok {
+		t.Errorf("round-tripped bundle: got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	back.Entries[3].Value = b.Entries[2].Value
+	if ok, err := gomerk.VerifyProofBundle(back); err != nil || ok {
+		t.Errorf("tampered bundle: got (%v, %v), want (false, nil)", ok, err)
+	}
+
+	bare, _ := gomerk.BuildStandardMerkleTree(vals, enc, gomerk.WithoutValues())
+	if _, err := bare.ExportProofs(); !errors.Is(err, gomerk.ErrValuesNotRetained) {
+		t.Errorf("got %v, want ErrValuesNotRetained", err)
+	}
+}
+
+func TestProofBundleSettings(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := make([][]any, 6)
+	for i := range values {
+		values[i] = mailValue(fmt.Sprintf("message %d", i))
+	}
+	for _, tc := range []struct {
+		name   string
+		values [][]any
+		enc    []string
+		opts   []gomerk.Option
+	}{
+		{"packed", airdropData(5), enc, []gomerk.Option{gomerk.WithPackedLeaves()}},
+		{"sha256", airdropData(5), enc, []gomerk.Option{gomerk.WithHasher(gomerk.SHA256Hasher)}},
+		{"little-endian", airdropData(5), enc, []gomerk.Option{gomerk.WithLittleEndianNumbers()}},
+		{"eip712", values, nil, []gomerk.Option{gomerk.WithEIP712Leaves(mailType())}},
+	} {
+		tree, err := gomerk.BuildStandardMerkleTree(tc.values, tc.enc, tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := tree.ExportProofs()
+		data, _ := json.Marshal(b)
+		var back gomerk.ProofBundle
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := gomerk.VerifyProofBundle(back); err != nil || !ok {
+			t.Errorf("%s: got (%v, %v), want (true, nil)", tc.name, ok, err)
+		}
+	}
+
+	tree, _ := gomerk.BuildStandardMerkleTree(airdropData(5), enc, gomerk.WithPackedLeaves())
+	b, _ := tree.ExportProofs()
+	b.Encoding = ""
+	if ok, _ := gomerk.VerifyProofBundle(b); ok {
+		t.Error("packed bundle verified as abi.encode leaves")
+	}
+	b.Encoding = "rlp"
+	if _, err := gomerk.VerifyProofBundle(b); !errors.Is(err, gomerk.ErrInvalidFormat) {
+		t.Errorf("got %v, want ErrInvalidFormat", err)
+	}
+}
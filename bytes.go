@@ -15,7 +15,20 @@ func (a Bytes32) Compare(b Bytes32) int { return bytes.Compare(a[:], b[:]) }
 func (a Bytes32) Less(b Bytes32) bool   { return a.Compare(b) < 0 }
 
 func HexToBytes32(s string) (b Bytes32, err error) {
-	data, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	s = strings.TrimPrefix(s, "0x")
+	// Decode nodes in place; DecodeString would allocate a slice per node.
+	if len(s) == 64 {
+		for i := range b {
+			hi, ok1 := fromHexChar(s[2*i])
+			lo, ok2 := fromHexChar(s[2*i+1])
+			if !ok1 || !ok2 {
+				return Bytes32{}, ErrInvalidHex
+			}
+			b[i] = hi<<4 | lo
+		}
+		return b, nil
+	}
+	data, err := hex.DecodeString(s)
 	if err != nil {
 		return b, ErrInvalidHex
 	}
@@ -25,6 +38,18 @@ func HexToBytes32(s string) (b Bytes32, err error) {
 	return Bytes32(data), nil
 }
 
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
 func MustHexToBytes32(s string) Bytes32 {
 	b, err := HexToBytes32(s)
 	if err != nil {
@@ -33,6 +58,17 @@ func MustHexToBytes32(s string) Bytes32 {
 	return b
 }
 
+// RootsEqual reports whether two hex nodes hold the same 32 bytes, ignoring
+// case and the 0x prefix. Malformed values are never equal.
+func RootsEqual(a, b string) bool {
+	x, err := HexToBytes32(a)
+	if err != nil {
+		return false
+	}
+	y, err := HexToBytes32(b)
+	return err == nil && x == y
+}
+
 func ConcatSorted(a, b Bytes32) []byte {
 	if a.Less(b) {
 		return append(a[:], b[:]...)
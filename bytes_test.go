@@ -1,6 +1,7 @@
 package gomerk_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/pyroth/gomerk"
@@ -74,8 +75,10 @@ func TestHexToBytes32(t *testing.T) {
 	}{
 		{"0x0000000000000000000000000000000000000000000000000000000000000001", gomerk.Bytes32{31: 1}, false},
 		{"0000000000000000000000000000000000000000000000000000000000000001", gomerk.Bytes32{31: 1}, false},
+		{"0xABCDEF00000000000000000000000000000000000000000000000000000000ff", gomerk.Bytes32{0xab, 0xcd, 0xef, 31: 0xff}, false},
 		{"0x00", gomerk.Bytes32{}, true},
 		{"invalid", gomerk.Bytes32{}, true},
+		{"0x000000000000000000000000000000000000000000000000000000000000000g", gomerk.Bytes32{}, true},
 	}
 	for _, tc := range tests {
 		got, err := gomerk.HexToBytes32(tc.input)
@@ -141,3 +144,22 @@ func TestConcatSorted(t *testing.T) {
 		t.Error("ConcatSorted(b, a) order wrong")
 	}
 }
+
+func TestRootsEqual(t *testing.T) {
+	lower := "0x00000000000000000000000000000000000000000000000000000000000000ab"
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{lower, lower, true},
+		{lower, strings.ToUpper(lower[2:]), true},
+		{lower, "0x" + strings.Repeat("0", 64), false},
+		{lower, "0xab", false},
+		{"invalid", "invalid", false},
+	}
+	for _, tc := range tests {
+		if got := gomerk.RootsEqual(tc.a, tc.b); got != tc.want {
+			t.Errorf("RootsEqual(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package gomerk
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EncodeCalldata returns the calldata of a call with the given arguments: the
+// selector followed by their abi.encode encoding. Unlike leaf encoding,
+// strings and bytes are encoded in full.
+func EncodeCalldata(selector [4]byte, types []string, args []any) ([]byte, error) {
+	c := leafCodec{calldata: true}
+	if len(types) != len(args) {
+		return nil, &EncodeError{Index: -1, Field: -1, Err: ErrMismatchedCount}
+	}
+	encs := make([][]byte, len(types))
+	for i, typ := range types {
+		b, err := c.encodeValue(typ, args[i])
+		if err != nil {
+			return nil, &EncodeError{Index: -1, Field: i, Type: typ, Err: err}
+		}
+		encs[i] = b
+	}
+	return append(selector[:], c.headTail(types, encs)...), nil
+}
+
+// EncodeClaimCalldata returns, as 0x-prefixed hex, the calldata of a call to
+// a claim function taking the fields of value followed by a bytes32[] proof,
+// such as claim(address,uint256,bytes32[]). The function is given as its
+// signature, whose parameter types must match, or as a hex 4-byte selector.
+func EncodeClaimCalldata(function string, leafEncoding []string, value []any, proof []string) (string, error) {
+	types := append(slices.Clone(leafEncoding), "bytes32[]")
+	var selector [4]byte
+	if open := strings.IndexByte(function, '('); open >= 0 {
+		params, ok := strings.CutSuffix(function[open+1:], ")")
+		if !ok || params != strings.Join(types, ",") {
+			return "", fmt.Errorf("%w: %s does not take (%s)", ErrMismatchedCount, function, strings.Join(types, ","))
+		}
+		selector = Selector(function)
+	} else {
+		b, err := hex.DecodeString(strings.TrimPrefix(function, "0x"))
+		if err != nil || len(b) != 4 {
+			return "", fmt.Errorf("%w: selector %q", ErrInvalidHex, function)
+		}
+		selector = [4]byte(b)
+	}
+	if len(value) != len(leafEncoding) {
+		return "", &EncodeError{Index: -1, Field: -1, Err: ErrMismatchedCount}
+	}
+	data, err := EncodeCalldata(selector, types, append(slices.Clone(value), proof))
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(data), nil
+}
+
+// EncodeClaimCalldata returns the calldata of a claim of value with proof,
+// using the tree's leaf encoding. See the package-level EncodeClaimCalldata.
+func (t *StandardMerkleTree) EncodeClaimCalldata(function string, value []any, proof []string) (string, error) {
+	return EncodeClaimCalldata(function, t.leafEncoding, value, proof)
+}
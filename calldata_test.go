@@ -0,0 +1,75 @@
+package gomerk_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func word(n int) []byte {
+	b := make([]byte, 32)
+	b[28], b[29], b[30], b[31] = byte(n>>24), byte(n>>16), byte(n>>8), byte(n)
+	return b
+}
+
+func TestEncodeClaimCalldata(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(4), []string{"address", "uint256"}, true)
+	value, _ := tree.At(1)
+	proof, _ := tree.GetProofByIndex(1)
+
+	got, err := tree.EncodeClaimCalldata("claim(address,uint256,bytes32[])", value, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := gomerk.Selector("claim(address,uint256,bytes32[])")
+	addr, _ := gomerk.ABIEncodePacked([]string{"address", "uint256"}, value)
+	want := slices.Concat(sel[:], addr, word(0x60), word(len(proof)))
+	for _, p := range proof {
+		b, _ := gomerk.HexToBytes32(p)
+		want = append(want, b[:]...)
+	}
+	if got != "0x"+hex.EncodeToString(want) {
+		t.Errorf("calldata = %s\nwant       0x%x", got, want)
+	}
+
+	bySelector, err := tree.EncodeClaimCalldata("0x"+hex.EncodeToString(sel[:]), value, proof)
+	if err != nil || bySelector != got {
+		t.Errorf("by selector = %s, %v", bySelector, err)
+	}
+}
+
+func TestEncodeCalldataDynamic(t *testing.T) {
+	sel := gomerk.Selector("f(string,uint256,bytes)")
+	got, err := gomerk.EncodeCalldata(sel, []string{"string", "uint256", "bytes"}, []any{"abc", 1, "0x" + strings.Repeat("ff", 33)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pad := func(b []byte) []byte { return append(b, make([]byte, (32-len(b)%32)%32)...) }
+	want := slices.Concat(sel[:], word(0x60), word(1), word(0xa0),
+		word(3), pad([]byte("abc")),
+		word(33), pad(slices.Repeat([]byte{0xff}, 33)))
+	if !slices.Equal(got, want) {
+		t.Errorf("calldata = %x\nwant       %x", got, want)
+	}
+}
+
+func TestEncodeClaimCalldataErrors(t *testing.T) {
+	value := []any{"0x" + padAddr(1), 100}
+	enc := []string{"address", "uint256"}
+	if _, err := gomerk.EncodeClaimCalldata("claim(address,bytes32[])", enc, value, nil); !errors.Is(err, gomerk.ErrMismatchedCount) {
+		t.Errorf("wrong signature: err = %v", err)
+	}
+	if _, err := gomerk.EncodeClaimCalldata("claim(", enc, value, nil); !errors.Is(err, gomerk.ErrMismatchedCount) {
+		t.Errorf("unterminated signature: err = %v", err)
+	}
+	if _, err := gomerk.EncodeClaimCalldata("0x1234", enc, value, nil); !errors.Is(err, gomerk.ErrInvalidHex) {
+		t.Errorf("short selector: err = %v", err)
+	}
+	if _, err := gomerk.EncodeClaimCalldata("0x12345678", enc, value, []string{"0x12"}); !errors.Is(err, gomerk.ErrInvalidNodeLength) {
+		t.Errorf("bad proof node: err = %v", err)
+	}
+}
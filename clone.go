@@ -0,0 +1,53 @@
+package gomerk
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of the tree that can be read and updated
+// independently of t, for example from another goroutine.
+func (t *StandardMerkleTree) Clone() *StandardMerkleTree {
+	c := *t
+	c.tree = slices.Clone(t.tree)
+	c.values = slices.Clone(t.values)
+	for i := range c.values {
+		c.values[i].Value = slices.Clone(c.values[i].Value)
+	}
+	c.leafEncoding = slices.Clone(t.leafEncoding)
+	c.index = maps.Clone(t.index)
+	return &c
+}
+
+// Equal reports whether t and other have the same leaf encoding, nodes and
+// value positions. Values are compared through their leaf hashes.
+func (t *StandardMerkleTree) Equal(other *StandardMerkleTree) bool {
+	return slices.Equal(t.leafEncoding, other.leafEncoding) &&
+		slices.Equal(t.tree, other.tree) &&
+		slices.EqualFunc(t.values, other.values, func(a, b StandardValue) bool { return a.TreeIndex == b.TreeIndex })
+}
+
+// RootEqual reports whether t and other have the same root.
+func (t *StandardMerkleTree) RootEqual(other *StandardMerkleTree) bool {
+	return t.tree[0] == other.tree[0]
+}
+
+// Clone returns a deep copy of the tree that can be read and updated
+// independently of t, for example from another goroutine.
+func (t *SimpleMerkleTree) Clone() *SimpleMerkleTree {
+	c := *t
+	c.tree = slices.Clone(t.tree)
+	c.values = slices.Clone(t.values)
+	c.index = maps.Clone(t.index)
+	return &c
+}
+
+// Equal reports whether t and other have the same nodes and value positions.
+func (t *SimpleMerkleTree) Equal(other *SimpleMerkleTree) bool {
+	return slices.Equal(t.tree, other.tree) && slices.Equal(t.values, other.values)
+}
+
+// RootEqual reports whether t and other have the same root.
+func (t *SimpleMerkleTree) RootEqual(other *SimpleMerkleTree) bool {
+	return t.tree[0] == other.tree[0]
+}
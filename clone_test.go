@@ -0,0 +1,74 @@
+package gomerk_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestStandardClone(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(8), enc, true)
+	c := tree.Clone()
+	if !c.Equal(tree) || !c.RootEqual(tree) {
+		t.Fatal("clone differs from the original")
+	}
+
+	root := tree.Root()
+	if _, err := c.UpdateLeafByIndex(2, []any{padAddr(99), "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Append([]any{padAddr(100), "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Root() != root || tree.Len() != 8 {
+		t.Fatal("updating the clone changed the original")
+	}
+	if v, _ := tree.At(2); v[0] == padAddr(99) {
+		t.Error("original value replaced")
+	}
+	if i, err := tree.IndexOf(airdropData(8)[2]); err != nil || i != 2 {
+		t.Errorf("original IndexOf = %d, %v", i, err)
+	}
+	if c.Equal(tree) || c.RootEqual(tree) {
+		t.Error("updated clone still equal")
+	}
+	if err := tree.SelfTest(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStandardEqual(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := airdropData(8)
+	a, _ := gomerk.NewStandardMerkleTree(values, enc, true)
+
+	// Same leaves in another value order: same root, different positions.
+	reversed := slices.Clone(values)
+	slices.Reverse(reversed)
+	b, _ := gomerk.NewStandardMerkleTree(reversed, enc, true)
+	if !a.RootEqual(b) || a.Equal(b) {
+		t.Errorf("reordered values: RootEqual = %v, Equal = %v", a.RootEqual(b), a.Equal(b))
+	}
+
+	c, _ := gomerk.NewStandardMerkleTree(values, enc, true)
+	if !a.Equal(c) {
+		t.Error("trees built from the same values differ")
+	}
+}
+
+func TestSimpleClone(t *testing.T) {
+	tree, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(5), true)
+	c := tree.Clone()
+	if !c.Equal(tree) || !c.RootEqual(tree) {
+		t.Fatal("clone differs from the original")
+	}
+	root := tree.Root()
+	if _, err := c.UpdateLeafByIndex(0, gomerk.Keccak256([]byte("x"))); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Root() != root || c.Equal(tree) || c.RootEqual(tree) {
+		t.Error("updating the clone changed the original or left it equal")
+	}
+}
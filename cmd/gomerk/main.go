@@ -0,0 +1,363 @@
+// Command gomerk builds standard merkle trees and works with their dumps.
+//
+//	gomerk build -encoding address,uint256 -in values.csv -out tree.json
+//	gomerk prove -tree tree.json -index 3
+//	gomerk prove -tree tree.json -value '["0x1111...", "5000"]'
+//	gomerk verify -root 0x... -encoding address,uint256 -value '[...]' -proof '[...]'
+//	gomerk multiprove -tree tree.json -indices 1,4,7
+//	gomerk render -tree tree.json
+//	gomerk export-proofs -tree tree.json -out proofs.json
+//
+// Values and proofs are JSON arrays. Input and output files default to
+// stdin and stdout.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/ingest"
+)
+
+// errInvalid is returned by verify for a proof that does not verify, so
+// that the command exits non-zero.
+var errInvalid = errors.New("invalid proof")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if !errors.Is(err, errInvalid) {
+			fmt.Fprintln(os.Stderr, "gomerk:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+const usage = `usage: gomerk <command> [flags]
+
+commands:
+  build          build a tree dump from CSV or JSON values
+  prove          print the proof of a value or index
+  verify         verify a proof against a root
+  multiprove     print a multiproof of several indices
+  render         draw a tree
+  export-proofs  write every value with its proof
+
+Run gomerk <command> -h for the flags of a command.`
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	cmds := map[string]func([]string, io.Reader, io.Writer) error{
+		"build":         build,
+		"prove":         prove,
+		"verify":        verify,
+		"multiprove":    multiprove,
+		"render":        render,
+		"export-proofs": exportProofs,
+	}
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+	return cmd(args[1:], stdin, stdout)
+}
+
+func build(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	encoding := fs.String("encoding", "", "comma-separated leaf encoding, e.g. address,uint256")
+	in := fs.String("in", "-", "values file: .csv, .jsonl or a JSON array of values")
+	out := fs.String("out", "-", "tree dump output file")
+	format := fs.String("format", "", "input format, csv, jsonl or json (default from the file extension)")
+	header := fs.Bool("header", true, "skip the first CSV row")
+	unsorted := fs.Bool("unsorted", false, "keep leaves in input order")
+	progress := fs.Bool("progress", false, "report build progress on stderr")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *encoding == "" {
+		return errors.New("build: -encoding is required")
+	}
+	types := strings.Split(*encoding, ",")
+
+	r, closeIn, err := openIn(*in, stdin)
+	if err != nil {
+		return err
+	}
+	defer closeIn()
+	if *format == "" {
+		*format = "json"
+		if ext := strings.ToLower(filepath.Ext(*in)); ext == ".csv" || ext == ".jsonl" {
+			*format = ext[1:]
+		}
+	}
+	var values [][]any
+	switch *format {
+	case "csv":
+		values, err = ingest.FromCSV(r, ingest.Schema{Types: types, Header: *header}).ReadAll()
+	case "jsonl":
+		values, err = ingest.FromJSONL(r, ingest.Schema{Types: types}).ReadAll()
+	case "json":
+		values, err = readJSON(r)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+
+	opts := []gomerk.Option{gomerk.WithSortLeaves(!*unsorted)}
+	if *progress {
+		opts = append(opts, gomerk.WithProgress(reportProgress))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	tree, err := gomerk.BuildStandardMerkleTreeContext(ctx, values, types, opts...)
+	if *progress {
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+	return writeOut(*out, stdout, func(w io.Writer) error {
+		if _, err := tree.WriteTo(w); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	})
+}
+
+// reportProgress overwrites a progress line on stderr.
+func reportProgress(p gomerk.Progress) {
+	fmt.Fprintf(os.Stderr, "\rhashed %d/%d leaves, built %d/%d levels", p.LeavesHashed, p.Leaves, p.LevelsBuilt, p.Levels)
+}
+
+func prove(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("prove", flag.ContinueOnError)
+	treePath := fs.String("tree", "", "tree dump file")
+	index := fs.Int("index", -1, "index of the value to prove")
+	value := fs.String("value", "", "value to prove, as a JSON array")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tree, err := loadTree(*treePath)
+	if err != nil {
+		return err
+	}
+	var (
+		v     []any
+		proof []string
+	)
+	switch {
+	case *value != "":
+		if v, err = parseValue(*value); err != nil {
+			return err
+		}
+		proof, err = tree.GetProof(v)
+	case *index >= 0:
+		var ok bool
+		if v, ok = tree.At(*index); !ok {
+			return fmt.Errorf("prove: %w", gomerk.ErrIndexOutOfBounds)
+		}
+		proof, err = tree.GetProofByIndex(*index)
+	default:
+		return errors.New("prove: -index or -value is required")
+	}
+	if err != nil {
+		return fmt.Errorf("prove: %w", err)
+	}
+	return writeJSON(stdout, gomerk.ProofItem{Value: v, Proof: proof})
+}
+
+func verify(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	treePath := fs.String("tree", "", "tree dump file providing the root and encoding")
+	root := fs.String("root", "", "root to verify against")
+	encoding := fs.String("encoding", "", "comma-separated leaf encoding")
+	value := fs.String("value", "", "value, as a JSON array")
+	proofJSON := fs.String("proof", "", "proof, as a JSON array")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *treePath != "" {
+		tree, err := loadTree(*treePath)
+		if err != nil {
+			return err
+		}
+		if *root == "" {
+			*root = tree.Root()
+		}
+		if *encoding == "" {
+			*encoding = strings.Join(tree.LeafEncoding(), ",")
+		}
+	}
+	if *root == "" || *encoding == "" || *value == "" || *proofJSON == "" {
+		return errors.New("verify: -root and -encoding (or -tree), -value and -proof are required")
+	}
+	v, err := parseValue(*value)
+	if err != nil {
+		return err
+	}
+	var proof []string
+	if err := json.Unmarshal([]byte(*proofJSON), &proof); err != nil {
+		return fmt.Errorf("verify: proof: %w", err)
+	}
+	ok, err := gomerk.VerifyStandard(*root, strings.Split(*encoding, ","), v, proof)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	if !ok {
+		fmt.Fprintln(stdout, "invalid")
+		return errInvalid
+	}
+	fmt.Fprintln(stdout, "valid")
+	return nil
+}
+
+func multiprove(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("multiprove", flag.ContinueOnError)
+	treePath := fs.String("tree", "", "tree dump file")
+	indices := fs.String("indices", "", "comma-separated value indices")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tree, err := loadTree(*treePath)
+	if err != nil {
+		return err
+	}
+	if *indices == "" {
+		return errors.New("multiprove: -indices is required")
+	}
+	var values [][]any
+	for _, s := range strings.Split(*indices, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("multiprove: index %q: %w", s, err)
+		}
+		v, ok := tree.At(i)
+		if !ok {
+			return fmt.Errorf("multiprove: index %d: %w", i, gomerk.ErrIndexOutOfBounds)
+		}
+		values = append(values, v)
+	}
+	mp, err := tree.GetMultiProof(values)
+	if err != nil {
+		return fmt.Errorf("multiprove: %w", err)
+	}
+	return writeJSON(stdout, mp)
+}
+
+func render(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	treePath := fs.String("tree", "", "tree dump file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tree, err := loadTree(*treePath)
+	if err != nil {
+		return err
+	}
+	s, err := tree.Render()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, s)
+	return err
+}
+
+func exportProofs(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export-proofs", flag.ContinueOnError)
+	treePath := fs.String("tree", "", "tree dump file")
+	out := fs.String("out", "-", "proofs output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tree, err := loadTree(*treePath)
+	if err != nil {
+		return err
+	}
+	bundle, err := tree.ExportProofs()
+	if err != nil {
+		return fmt.Errorf("export-proofs: %w", err)
+	}
+	return writeOut(*out, stdout, func(w io.Writer) error { return writeJSON(w, bundle) })
+}
+
+func loadTree(path string) (*gomerk.StandardMerkleTree, error) {
+	if path == "" {
+		return nil, errors.New("-tree is required")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tree, err := gomerk.LoadStandardMerkleTreeFrom(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tree, nil
+}
+
+// parseValue decodes a JSON array value, keeping numbers exact.
+func parseValue(s string) ([]any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v []any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("value: %w", err)
+	}
+	return v, nil
+}
+
+func readJSON(r io.Reader) ([][]any, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var values [][]any
+	if err := dec.Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func openIn(path string, stdin io.Reader) (io.Reader, func(), error) {
+	if path == "-" {
+		return stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
+
+// writeOut writes to path, or to stdout if path is "-". Files are written
+// only once fn succeeds.
+func writeOut(path string, stdout io.Writer, fn func(io.Writer) error) error {
+	if path == "-" {
+		return fn(stdout)
+	}
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
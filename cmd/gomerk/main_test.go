@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+const csvValues = `address,amount
+0x1111111111111111111111111111111111111111,5000000000000000000
+0x2222222222222222222222222222222222222222,2500000000000000000
+0x3333333333333333333333333333333333333333,100
+`
+
+func runCmd(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &out)
+	return out.String(), err
+}
+
+func buildTree(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tree.json")
+	if _, err := runCmd(t, csvValues, "build", "-encoding", "address,uint256", "-format", "csv", "-out", path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBuildProveVerify(t *testing.T) {
+	path := buildTree(t)
+	data, _ := os.ReadFile(path)
+	var dump gomerk.StandardTreeData
+	if err := json.Unmarshal(data, &dump); err != nil {
+		t.Fatal(err)
+	}
+	tree, err := gomerk.LoadStandardMerkleTree(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 3 {
+		t.Fatalf("tree has %d values, want 3", tree.Len())
+	}
+
+	out, err := runCmd(t, "", "prove", "-tree", path, "-value", `["0x3333333333333333333333333333333333333333", 100]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item gomerk.ProofItem
+	if err := json.Unmarshal([]byte(out), &item); err != nil {
+		t.Fatal(err)
+	}
+	proof, _ := json.Marshal(item.Proof)
+
+	out, err = runCmd(t, "", "verify", "-root", tree.Root(), "-encoding", "address,uint256",
+		"-value", `["0x3333333333333333333333333333333333333333", "100"]`, "-proof", string(proof))
+	if err != nil || out != "valid\n" {
+		t.Errorf("verify = %q, %v", out, err)
+	}
+	out, err = runCmd(t, "", "verify", "-tree", path,
+		"-value", `["0x3333333333333333333333333333333333333333", "101"]`, "-proof", string(proof))
+	if !errors.Is(err, errInvalid) || out != "invalid\n" {
+		t.Errorf("verify tampered value = %q, %v", out, err)
+	}
+}
+
+func TestMultiproveAndExport(t *testing.T) {
+	path := buildTree(t)
+	tree, _ := loadTree(path)
+
+	out, err := runCmd(t, "", "multiprove", "-tree", path, "-indices", "0,2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mp gomerk.StandardMultiProof
+	if err := json.Unmarshal([]byte(out), &mp); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := tree.VerifyMultiProofValues(&mp); err != nil || !ok {
+		t.Errorf("multiproof does not verify: %v", err)
+	}
+
+	out, err = runCmd(t, "", "export-proofs", "-tree", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle gomerk.ProofBundle
+	if err := json.Unmarshal([]byte(out), &bundle); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := gomerk.VerifyBundleAgainstTrustedRoot(bundle, tree.Root()); err != nil || !ok {
+		t.Errorf("exported bundle does not verify: %v", err)
+	}
+
+	out, err = runCmd(t, "", "render", "-tree", path)
+	if err != nil || !strings.HasPrefix(out, "0) "+tree.Root()) {
+		t.Errorf("render = %q, %v", out, err)
+	}
+}
+
+func TestBuildJSON(t *testing.T) {
+	out, err := runCmd(t, `[["0x1111111111111111111111111111111111111111", 5], ["0x2222222222222222222222222222222222222222", "6"]]`,
+		"build", "-encoding", "address,uint256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := gomerk.LoadStandardMerkleTreeFrom(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 2 {
+		t.Errorf("tree has %d values, want 2", tree.Len())
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := runCmd(t, ""); err == nil {
+		t.Error("expected usage error")
+	}
+	if _, err := runCmd(t, "", "bogus"); err == nil {
+		t.Error("expected unknown command error")
+	}
+	if _, err := runCmd(t, "a,b\n0x12,x\n", "build", "-encoding", "address,uint256", "-format", "csv"); err == nil {
+		t.Error("expected error for an invalid address")
+	}
+	if _, err := runCmd(t, "", "prove", "-tree", buildTree(t), "-index", "9"); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("out of range index: err = %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package gomerk
+
+import "encoding/binary"
+
+// EncodeProof packs a proof as a 4-byte big-endian sibling count followed by
+// the raw 32-byte siblings.
+func EncodeProof(proof []string) ([]byte, error) {
+	out := binary.BigEndian.AppendUint32(make([]byte, 0, 4+32*len(proof)), uint32(len(proof)))
+	for _, node := range proof {
+		b, err := HexToBytes32(node)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b[:]...)
+	}
+	return out, nil
+}
+
+// DecodeProof unpacks a proof produced by EncodeProof.
+func DecodeProof(data []byte) ([]string, error) {
+	if len(data) < 4 {
+		return nil, ErrInvalidProofEncoding
+	}
+	n := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(len(data)) != 32*uint64(n) {
+		return nil, ErrInvalidProofEncoding
+	}
+	proof := make([]string, n)
+	for i := range proof {
+		proof[i] = Bytes32(data[32*i : 32*i+32]).Hex()
+	}
+	return proof, nil
+}
+
+// ProofToBytes32Array converts a proof to the [][32]byte that abigen
+// bindings take for bytes32[] parameters.
+func ProofToBytes32Array(proof []string) ([][32]byte, error) {
+	out := make([][32]byte, len(proof))
+	for i, node := range proof {
+		b, err := HexToBytes32(node)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+// ProofFromBytes32Array converts a bytes32[] proof from abigen bindings back
+// to hex nodes.
+func ProofFromBytes32Array(proof [][32]byte) []string {
+	out := make([]string, len(proof))
+	for i, node := range proof {
+		out[i] = Bytes32(node).Hex()
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package gomerk_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestEncodeDecodeProof(t *testing.T) {
+	tree, _ := gomerk.MakeTree(testLeaves(9))
+	for i := range gomerk.TreeLeaves(tree) {
+		proof, _ := gomerk.GetProof(tree, i)
+		data, err := gomerk.EncodeProof(proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 4+32*len(proof) {
+			t.Errorf("i=%d: got %d bytes, want %d", i, len(data), 4+32*len(proof))
+		}
+		got, err := gomerk.DecodeProof(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, proof) {
+			t.Errorf("i=%d: roundtrip mismatch", i)
+		}
+	}
+}
+
+func TestEncodeDecodeProofEmpty(t *testing.T) {
+	data, _ := gomerk.EncodeProof(nil)
+	got, err := gomerk.DecodeProof(data)
+	if err != nil || len(got) != 0 {
+		t.Errorf("got (%v, %v), want empty proof", got, err)
+	}
+}
+
+func TestEncodeProofInvalid(t *testing.T) {
+	if _, err := gomerk.EncodeProof([]string{"invalid"}); err == nil {
+		t.Error("expected error for invalid node")
+	}
+}
+
+func TestDecodeProofMalformed(t *testing.T) {
+	valid, _ := gomerk.EncodeProof([]string{testLeaves(1)[0].Hex()})
+	tests := [][]byte{
+		nil,
+		{0, 0, 1},
+		valid[:len(valid)-1],
+		append(valid, 0),
+		{0xff, 0xff, 0xff, 0xff},
+	}
+	for _, data := range tests {
+		if _, err := gomerk.DecodeProof(data); err != gomerk.ErrInvalidProofEncoding {
+			t.Errorf("%x: got %v, want ErrInvalidProofEncoding", data, err)
+		}
+	}
+}
+
+func TestProofToBytes32Array(t *testing.T) {
+	tree, _ := gomerk.MakeTree(testLeaves(5))
+	proof, _ := gomerk.GetProof(tree, 5)
+	arr, err := gomerk.ProofToBytes32Array(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, node := range arr {
+		if gomerk.Bytes32(node).Hex() != proof[i] {
+			t.Errorf("node %d = %x, want %s", i, node, proof[i])
+		}
+	}
+	if got := gomerk.ProofFromBytes32Array(arr); !slices.Equal(got, proof) {
+		t.Errorf("ProofFromBytes32Array() = %v, want %v", got, proof)
+	}
+	if _, err := gomerk.ProofToBytes32Array([]string{"0x12"}); err == nil {
+		t.Error("expected error for a short node")
+	}
+}
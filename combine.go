@@ -0,0 +1,156 @@
+package gomerk
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CombineMultiProofs merges multiproofs for the same root into a single
+// multiproof covering the union of their leaves. Each input is verified
+// against root first. Leaves must be leaf hashes, as produced by GetMultiProof
+// and StandardMerkleTree.GetMultiProofByIndices.
+//
+// The multiproofs only reveal which nodes hash together, not whether a node
+// is a left or right child, so the combined proof is laid out over an
+// equivalent tree in which every known internal node precedes every leaf, as
+// in the trees built by MakeTree. Because pair hashing is commutative, it
+// verifies against the same root.
+func CombineMultiProofs(root string, mps ...*MultiProof) (*MultiProof, error) {
+	rootHash, err := HexToBytes32(root)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[Bytes32][2]Bytes32)
+	leaves := make(map[Bytes32]bool)
+	for i, mp := range mps {
+		r, err := processMultiProof(Keccak256Hasher, mp, func(parent, a, b Bytes32) {
+			children[parent] = [2]Bytes32{a, b}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("multiproof %d: %w", i, err)
+		}
+		if !RootsEqual(r, root) {
+			return nil, fmt.Errorf("multiproof %d: %w", i, ErrRootMismatch)
+		}
+		for _, leaf := range mp.Leaves {
+			b, _ := HexToBytes32(leaf)
+			leaves[b] = true
+		}
+	}
+
+	if len(leaves) == 0 {
+		return &MultiProof{Leaves: []string{}, Proof: []string{rootHash.Hex()}, ProofFlags: []bool{}}, nil
+	}
+
+	top, err := newCombineNode(rootHash, 0, children, leaves)
+	if err != nil {
+		return nil, err
+	}
+	top.mark(top.maxLeafDepth())
+
+	nodes := make(map[int]string)
+	var indices []int
+	maxInternal := -1
+	top.place(0, nodes, &indices, &maxInternal)
+
+	slices.SortFunc(indices, func(a, b int) int { return b - a })
+	if indices[len(indices)-1] <= maxInternal {
+		return nil, ErrInvariant
+	}
+
+	mp, err := multiProof(indices, func(i int) (string, bool) { v, ok := nodes[i]; return v, ok })
+	if err != nil {
+		return nil, err
+	}
+	if r, err := ProcessMultiProof(mp); err != nil || r != rootHash.Hex() {
+		return nil, ErrInvariant
+	}
+	return mp, nil
+}
+
+// combineNode is a node of the partial tree recovered from multiproofs. Nodes
+// without kids are either proven leaves or opaque proof siblings.
+type combineNode struct {
+	hash  Bytes32
+	leaf  bool
+	depth int
+	kids  []*combineNode
+
+	// rank orders siblings so that subtrees reaching the deepest leaf level
+	// come first and the one holding the boundary between the two leaf
+	// levels sits between them and shallower subtrees.
+	rank int
+}
+
+// maxCombineDepth keeps heap indices of the recovered tree within an int.
+const maxCombineDepth = 62
+
+func newCombineNode(h Bytes32, depth int, children map[Bytes32][2]Bytes32, leaves map[Bytes32]bool) (*combineNode, error) {
+	if depth > maxCombineDepth {
+		return nil, ErrInvariant
+	}
+	n := &combineNode{hash: h, depth: depth, leaf: leaves[h]}
+	if pair, ok := children[h]; ok && !n.leaf {
+		for _, c := range pair {
+			kid, err := newCombineNode(c, depth+1, children, leaves)
+			if err != nil {
+				return nil, err
+			}
+			n.kids = append(n.kids, kid)
+		}
+	}
+	return n, nil
+}
+
+func (n *combineNode) maxLeafDepth() int {
+	d := -1
+	if n.leaf {
+		d = n.depth
+	}
+	for _, k := range n.kids {
+		d = max(d, k.maxLeafDepth())
+	}
+	return d
+}
+
+// mark computes ranks given the depth of the deepest leaf and returns whether
+// the subtree reaches that depth and whether it holds a leaf one level above.
+func (n *combineNode) mark(deepest int) (reaches, upper bool) {
+	if n.leaf {
+		reaches, upper = n.depth == deepest, n.depth == deepest-1
+	}
+	for _, k := range n.kids {
+		r, u := k.mark(deepest)
+		reaches, upper = reaches || r, upper || u
+	}
+	switch {
+	case reaches && !upper:
+		n.rank = 0
+	case reaches:
+		n.rank = 1
+	default:
+		n.rank = 2
+	}
+	return reaches, upper
+}
+
+func (n *combineNode) place(i int, nodes map[int]string, leaves *[]int, maxInternal *int) {
+	nodes[i] = n.hash.Hex()
+	if n.leaf {
+		*leaves = append(*leaves, i)
+		return
+	}
+	if len(n.kids) == 0 {
+		return
+	}
+	*maxInternal = max(*maxInternal, i)
+	slices.SortFunc(n.kids, func(a, b *combineNode) int {
+		if a.rank != b.rank {
+			return a.rank - b.rank
+		}
+		return a.hash.Compare(b.hash)
+	})
+	n.kids[0].place(leftChild(i), nodes, leaves, maxInternal)
+	n.kids[1].place(rightChild(i), nodes, leaves, maxInternal)
+}
@@ -0,0 +1,83 @@
+package gomerk_test
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestCombineMultiProofs(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for n := 1; n <= 20; n++ {
+		tree, _ := gomerk.MakeTree(testLeaves(n))
+		for range 20 {
+			var parts [][]int
+			union := make(map[string]bool)
+			for range 1 + rng.IntN(3) {
+				var idx []int
+				for i := len(tree) - n; i < len(tree); i++ {
+					if rng.IntN(3) == 0 {
+						idx = append(idx, i)
+						union[tree[i]] = true
+					}
+				}
+				parts = append(parts, idx)
+			}
+
+			var mps []*gomerk.MultiProof
+			for _, idx := range parts {
+				mp, _ := gomerk.GetMultiProof(tree, idx)
+				mps = append(mps, mp)
+			}
+
+			combined, err := gomerk.CombineMultiProofs(tree[0], mps...)
+			if err != nil {
+				t.Fatalf("n=%d parts=%v: %v", n, parts, err)
+			}
+			root, err := gomerk.ProcessMultiProof(combined)
+			if err != nil || root != tree[0] {
+				t.Fatalf("n=%d parts=%v: combined proof does not verify", n, parts)
+			}
+			if len(combined.Leaves) != len(union) {
+				t.Errorf("n=%d parts=%v: got %d leaves, want %d", n, parts, len(combined.Leaves), len(union))
+			}
+			for _, leaf := range combined.Leaves {
+				if !union[leaf] {
+					t.Errorf("n=%d: unexpected leaf %s", n, leaf)
+				}
+			}
+		}
+	}
+}
+
+func TestCombineMultiProofsStandard(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(9), []string{"address", "uint256"}, true)
+	a, _ := tree.GetMultiProofByIndices([]int{0, 3, 4})
+	b, _ := tree.GetMultiProofByIndices([]int{4, 7, 8})
+
+	combined, err := gomerk.CombineMultiProofs(tree.Root(), a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(combined.Leaves) != 5 {
+		t.Errorf("got %d leaves, want 5", len(combined.Leaves))
+	}
+	ok, _ := tree.VerifyMultiProof(combined)
+	if !ok {
+		t.Error("combined proof should verify against the tree")
+	}
+}
+
+func TestCombineMultiProofsRootMismatch(t *testing.T) {
+	tree, _ := gomerk.MakeTree(testLeaves(4))
+	other, _ := gomerk.MakeTree(testLeaves(5))
+	a, _ := gomerk.GetMultiProof(tree, []int{3, 4})
+	b, _ := gomerk.GetMultiProof(other, []int{5})
+
+	_, err := gomerk.CombineMultiProofs(tree[0], a, b)
+	if !errors.Is(err, gomerk.ErrRootMismatch) {
+		t.Errorf("got %v, want ErrRootMismatch", err)
+	}
+}
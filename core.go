@@ -3,8 +3,10 @@ package gomerk
 import (
 	"fmt"
 	"iter"
+	"math/bits"
 	"slices"
 	"strings"
+	"sync"
 )
 
 func leftChild(i int) int  { return 2*i + 1 }
@@ -15,7 +17,6 @@ func sibling(i int) int    { return ((i + 1) ^ 1) - 1 }
 func isTreeNode(n, i int) bool     { return i >= 0 && i < n }
 func isInternalNode(n, i int) bool { return isTreeNode(n, leftChild(i)) }
 func isLeafNode(n, i int) bool     { return isTreeNode(n, i) && !isInternalNode(n, i) }
-func isValidNode(s string) bool    { _, err := HexToBytes32(s); return err == nil }
 
 func checkLeaf(n, i int) error {
 	if !isTreeNode(n, i) {
@@ -32,17 +33,270 @@ func MakeTree(leaves []Bytes32) ([]string, error) {
 	if len(leaves) == 0 {
 		return nil, ErrEmptyTree
 	}
+	return makeTree(Keccak256Hasher, leaves), nil
+}
+
+// OddPolicy selects how a level with an odd number of nodes is paired.
+type OddPolicy int
+
+const (
+	// OddUnbalanced is the OpenZeppelin layout used by MakeTree: 2n-1 nodes
+	// with the leaves spread over the last two levels.
+	OddUnbalanced OddPolicy = iota
+	// OddDuplicate pairs the last node of an odd level with itself, as in
+	// Bitcoin's transaction tree.
+	OddDuplicate
+)
+
+// MakeTreeWithPolicy builds a Merkle tree from leaves, pairing nodes of odd
+// levels according to policy. Under OddDuplicate the leaves are padded by
+// repeating the last subtree at each odd level, giving a perfect tree whose
+// root matches the duplicate-last construction. The padded tree uses the
+// same flat layout as MakeTree, so GetProof and GetMultiProof work on it
+// unchanged and leaf i is still at tree[len(tree)-1-i].
+func MakeTreeWithPolicy(leaves []Bytes32, policy OddPolicy) ([]string, error) {
+	switch policy {
+	case OddUnbalanced:
+		return MakeTree(leaves)
+	case OddDuplicate:
+		if len(leaves) == 0 {
+			return nil, ErrEmptyTree
+		}
+		return makeTree(Keccak256Hasher, padDuplicate(leaves)), nil
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+// padDuplicate extends leaves to a power of two by duplicating the last
+// subtree of each level with an odd number of nodes.
+func padDuplicate(leaves []Bytes32) []Bytes32 {
+	padded := slices.Clone(leaves)
+	for size := 1; len(padded) > size; size *= 2 {
+		if (len(padded)/size)%2 == 1 {
+			padded = append(padded, padded[len(padded)-size:]...)
+		}
+	}
+	return padded
+}
+
+// makeTree builds a tree from a non-empty set of leaves with h.
+func makeTree(h Hasher, leaves []Bytes32) []string {
+	return hexNodes(makeNodes(h, nil, leaves, 1))
+}
+
+// makeNodes builds a tree from a non-empty set of leaves with h on up to
+// workers goroutines, reusing dst's storage when it has enough capacity.
+func makeNodes(h Hasher, dst []Bytes32, leaves []Bytes32, workers int) []Bytes32 {
+	nodes, _ := makeNodesProgress(h, dst, leaves, workers, nil)
+	return nodes
+}
+
+// makeNodesProgress is makeNodes reporting each level built to bp, if not
+// nil, and stopping with its error once its context is done.
+func makeNodesProgress(h Hasher, dst []Bytes32, leaves []Bytes32, workers int, bp *buildProgress) ([]Bytes32, error) {
 	n := 2*len(leaves) - 1
-	tree := make([]string, n)
+	nodes := slices.Grow(dst[:0], n)[:n]
 	for i, leaf := range leaves {
-		tree[n-1-i] = leaf.Hex()
+		nodes[n-1-i] = leaf
+	}
+	// Internal nodes at the same depth are independent, so each depth is
+	// hashed in parallel, deepest first.
+	internal := len(leaves) - 1
+	levels := bits.Len(uint(internal))
+	for d := levels - 1; d >= 0; d-- {
+		lo, hi := 1<<d-1, min(1<<(d+1)-1, internal)
+		parallelFor(hi-lo, workers, func(a, b int) {
+			for i := lo + a; i < lo+b; i++ {
+				nodes[i] = h.NodeHash(nodes[leftChild(i)], nodes[rightChild(i)])
+			}
+		})
+		if bp != nil {
+			if err := bp.level(levels); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// mergeLeaves returns the leaves of a tree holding n values, whose leaves
+// are at tree index treeIndex(i), extended with added, together with the
+// value index owning each leaf. The added leaves belong to values n, n+1, ...
+// If sorted, they are merged into hash order; otherwise they follow the
+// existing leaves.
+func mergeLeaves(tree []Bytes32, n int, treeIndex func(int) int, added []Bytes32, sorted bool) (leaves []Bytes32, owners []int) {
+	owners = make([]int, n, n+len(added))
+	for i := range n {
+		owners[len(tree)-1-treeIndex(i)] = i
+	}
+	newOwners := make([]int, len(added))
+	for k := range added {
+		newOwners[k] = n + k
+	}
+	if sorted {
+		slices.SortStableFunc(newOwners, func(a, b int) int { return added[a-n].Compare(added[b-n]) })
+		merged := make([]int, 0, n+len(added))
+		i, k := 0, 0
+		for i < n || k < len(added) {
+			if k == len(added) || (i < n && !added[newOwners[k]-n].Less(tree[len(tree)-1-i])) {
+				merged = append(merged, owners[i])
+				i++
+			} else {
+				merged = append(merged, newOwners[k])
+				k++
+			}
+		}
+		owners = merged
+	} else {
+		owners = append(owners, newOwners...)
+	}
+	leaves = make([]Bytes32, len(owners))
+	for pos, i := range owners {
+		if i < n {
+			leaves[pos] = tree[treeIndex(i)]
+		} else {
+			leaves[pos] = added[i-n]
+		}
+	}
+	return leaves, owners
+}
+
+// hexNodes formats nodes as hex, keeping a nil slice nil.
+func hexNodes(nodes []Bytes32) []string {
+	if nodes == nil {
+		return nil
+	}
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.Hex()
+	}
+	return out
+}
+
+// parseNodes parses hex nodes, such as a serialized tree.
+func parseNodes(nodes []string) ([]Bytes32, error) {
+	out := make([]Bytes32, len(nodes))
+	for i, s := range nodes {
+		b, err := HexToBytes32(s)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+// minParallelWork is the smallest range parallelFor splits across workers.
+const minParallelWork = 1024
+
+// parallelFor calls fn over contiguous chunks covering [0, n), on up to
+// workers goroutines, and returns when all chunks are done. Small ranges run
+// on the calling goroutine.
+func parallelFor(n, workers int, fn func(lo, hi int)) {
+	if workers <= 1 || n < minParallelWork {
+		fn(0, n)
+		return
+	}
+	workers = min(workers, n/(minParallelWork/2))
+	var wg sync.WaitGroup
+	for w := range workers {
+		lo, hi := n*w/workers, n*(w+1)/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(lo, hi)
+		}()
+	}
+	wg.Wait()
+}
+
+// leavesSorted reports whether the leaves of tree appear in ascending hash
+// order, as they do in trees built with sortLeaves.
+func leavesSorted(tree []Bytes32) bool {
+	prev := Bytes32{}
+	for i := len(tree) - 1; isLeafNode(len(tree), i); i-- {
+		if tree[i].Less(prev) {
+			return false
+		}
+		prev = tree[i]
+	}
+	return true
+}
+
+// updateLeaf sets the leaf at tree index ti to leaf and recomputes the nodes
+// on its path to the root.
+func updateLeaf(h Hasher, tree []Bytes32, ti int, leaf Bytes32) {
+	tree[ti] = leaf
+	for ti > 0 {
+		ti = parent(ti)
+		tree[ti] = h.NodeHash(tree[leftChild(ti)], tree[rightChild(ti)])
+	}
+}
+
+// leafInOrder reports whether the leaf at tree index ti is ordered with
+// respect to its neighbouring leaves, as leavesSorted requires.
+func leafInOrder(tree []Bytes32, ti int) bool {
+	if ti+1 < len(tree) && tree[ti].Less(tree[ti+1]) {
+		return false
+	}
+	return !isLeafNode(len(tree), ti-1) || !tree[ti-1].Less(tree[ti])
+}
+
+// reindexLeaf updates an index built by indexLeaves after value i, at tree
+// index treeIndex(i), changed its leaf from old to the one now in tree. When
+// the index holds fewer entries than values some leaves are duplicated, and
+// another value with the old leaf takes over its entry.
+func reindexLeaf(index map[Bytes32]int, tree []Bytes32, n int, treeIndex func(int) int, i int, old Bytes32) {
+	if j, ok := index[old]; ok && j == i {
+		dup := len(index) < n
+		delete(index, old)
+		if dup {
+			for j := range n {
+				if tree[treeIndex(j)] == old {
+					index[old] = j
+					break
+				}
+			}
+		}
+	}
+	leaf := tree[treeIndex(i)]
+	if j, ok := index[leaf]; !ok || i < j {
+		index[leaf] = i
+	}
+}
+
+// leafIndices returns the indices of the n values whose leaf is h, in
+// ascending order, using index to find the first. It only scans the values
+// if index shows that some leaves are duplicated.
+func leafIndices(index map[Bytes32]int, tree []Bytes32, n int, treeIndex func(int) int, h Bytes32) []int {
+	first, ok := index[h]
+	if !ok {
+		return nil
+	}
+	out := []int{first}
+	if len(index) == n {
+		return out
 	}
-	for i := n - 1 - len(leaves); i >= 0; i-- {
-		l, _ := HexToBytes32(tree[leftChild(i)])
-		r, _ := HexToBytes32(tree[rightChild(i)])
-		tree[i] = HashNode(l, r).Hex()
+	for j := first + 1; j < n; j++ {
+		if tree[treeIndex(j)] == h {
+			out = append(out, j)
+		}
 	}
-	return tree, nil
+	return out
+}
+
+// indexLeaves maps each leaf hash to the first of n values whose leaf, at tree
+// index treeIndex(i), holds it.
+func indexLeaves(tree []Bytes32, n int, treeIndex func(int) int) map[Bytes32]int {
+	index := make(map[Bytes32]int, n)
+	for i := range n {
+		h := tree[treeIndex(i)]
+		if _, ok := index[h]; !ok {
+			index[h] = i
+		}
+	}
+	return index
 }
 
 // GetProof returns a single proof for a leaf at index.
@@ -58,19 +312,251 @@ func GetProof(tree []string, index int) ([]string, error) {
 	return proof, nil
 }
 
+// getProof is GetProof for a parsed tree.
+func getProof(tree []Bytes32, index int) ([]string, error) {
+	proof, err := getProofBytes(tree, index)
+	return hexNodes(proof), err
+}
+
+func getProofBytes(tree []Bytes32, index int) ([]Bytes32, error) {
+	if err := checkLeaf(len(tree), index); err != nil {
+		return nil, err
+	}
+	var proof []Bytes32
+	for index > 0 {
+		proof = append(proof, tree[sibling(index)])
+		index = parent(index)
+	}
+	return proof, nil
+}
+
+// proofsSeq yields the proof of each of n values, whose leaf is at tree index
+// treeIndex(i), formatting every node as hex only once.
+func proofsSeq(tree []Bytes32, n int, treeIndex func(int) int) iter.Seq2[int, []string] {
+	return func(yield func(int, []string) bool) {
+		nodes := hexNodes(tree)
+		for i := range n {
+			if !yield(i, proofFrom(nodes, treeIndex(i))) {
+				return
+			}
+		}
+	}
+}
+
+// allProofs returns the proofs of n values like proofsSeq, building them on up
+// to workers goroutines.
+func allProofs(tree []Bytes32, n int, treeIndex func(int) int, workers int) [][]string {
+	nodes := hexNodes(tree)
+	proofs := make([][]string, n)
+	parallelFor(n, workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			proofs[i] = proofFrom(nodes, treeIndex(i))
+		}
+	})
+	return proofs
+}
+
+// proofsByIndices returns the proofs of the given values, out of n whose
+// leaf is at tree index treeIndex(i), keyed by value index. The leaves are
+// visited in tree order, and once a leaf's path joins the previous leaf's,
+// the rest of its proof is copied from the previous proof.
+func proofsByIndices(tree []Bytes32, n int, treeIndex func(int) int, indices []int) (map[int][]string, error) {
+	leaves := make([]int, 0, len(indices))
+	for _, i := range indices {
+		if i < 0 || i >= n {
+			return nil, ErrIndexOutOfBounds
+		}
+		leaves = append(leaves, treeIndex(i))
+	}
+	slices.Sort(leaves)
+	leaves = slices.Compact(leaves)
+
+	depth := func(i int) int { return bits.Len(uint(i+1)) - 1 }
+	byLeaf := make(map[int][]string, len(leaves))
+	var (
+		prev   []string
+		prevAt []int // prevAt[d] is the previous leaf's ancestor at depth d
+	)
+	for _, leaf := range leaves {
+		at := make([]int, depth(leaf)+1)
+		proof := make([]string, 0, len(at)-1)
+		for index := leaf; index > 0; index = parent(index) {
+			d := depth(index)
+			if d < len(prevAt) && prevAt[d] == index {
+				copy(at, prevAt[:d+1])
+				proof = append(proof, prev[len(prevAt)-1-d:]...)
+				break
+			}
+			at[d] = index
+			proof = append(proof, tree[sibling(index)].Hex())
+		}
+		byLeaf[leaf] = proof
+		prev, prevAt = proof, at
+	}
+	proofs := make(map[int][]string, len(indices))
+	for _, i := range indices {
+		proofs[i] = byLeaf[treeIndex(i)]
+	}
+	return proofs, nil
+}
+
+func proofFrom(nodes []string, index int) []string {
+	var proof []string
+	for index > 0 {
+		proof = append(proof, nodes[sibling(index)])
+		index = parent(index)
+	}
+	return proof
+}
+
+// LeafDescendants returns the tree indices of all leaves under the node at
+// nodeIndex, in ascending order. A leaf is its own only descendant.
+func LeafDescendants(tree []string, nodeIndex int) ([]int, error) {
+	n := len(tree)
+	if !isTreeNode(n, nodeIndex) {
+		return nil, ErrIndexOutOfBounds
+	}
+	var leaves []int
+	for lo, hi := nodeIndex, nodeIndex; lo < n; lo, hi = leftChild(lo), rightChild(hi) {
+		for i := lo; i <= min(hi, n-1); i++ {
+			if isLeafNode(n, i) {
+				leaves = append(leaves, i)
+			}
+		}
+	}
+	return leaves, nil
+}
+
+// NodeChildren returns the left and right children of the node at index.
+// It reports false for leaves and out-of-bounds indices.
+func NodeChildren(tree []string, index int) (left, right string, ok bool) {
+	if !isTreeNode(len(tree), index) || !isInternalNode(len(tree), index) {
+		return "", "", false
+	}
+	return tree[leftChild(index)], tree[rightChild(index)], true
+}
+
 // ProcessProof computes the root from a leaf and proof.
 func ProcessProof(leaf Bytes32, proof []string) (string, error) {
+	return processProof(Keccak256Hasher, leaf, proof)
+}
+
+func processProof(h Hasher, leaf Bytes32, proof []string) (string, error) {
+	if !commutative(h) {
+		return "", ErrPositionalProof
+	}
 	current := leaf
 	for _, sib := range proof {
 		s, err := HexToBytes32(sib)
 		if err != nil {
 			return "", err
 		}
-		current = HashNode(current, s)
+		current = h.NodeHash(current, s)
+	}
+	return current.Hex(), nil
+}
+
+func processProofBytes(h Hasher, leaf Bytes32, proof []Bytes32) Bytes32 {
+	for _, sib := range proof {
+		leaf = h.NodeHash(leaf, sib)
+	}
+	return leaf
+}
+
+// Verify checks that proof links leafHash to root. It is the primitive behind
+// the tree-specific verifiers, which first derive leafHash from a value.
+func Verify(root string, leafHash Bytes32, proof []string) (bool, error) {
+	return verify(Keccak256Hasher, root, leafHash, proof)
+}
+
+func verify(h Hasher, root string, leafHash Bytes32, proof []string) (bool, error) {
+	r, err := processProof(h, leafHash, proof)
+	if err != nil {
+		return false, err
+	}
+	return r == root, nil
+}
+
+// verifyPositional checks a plain proof for leafHash against a positionally
+// hashed tree. The proof carries no direction bits, so they are taken from
+// positions, the tree indices holding leafHash.
+func verifyPositional(h Hasher, tree []Bytes32, positions []int, leafHash Bytes32, proof []Bytes32) bool {
+	for _, index := range positions {
+		i, k, cur := index, 0, leafHash
+		for ; i > 0 && k < len(proof); i, k = parent(i), k+1 {
+			if i%2 == 0 {
+				cur = h.NodeHash(proof[k], cur)
+			} else {
+				cur = h.NodeHash(cur, proof[k])
+			}
+		}
+		if i == 0 && k == len(proof) && cur == tree[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// leafPositions returns the tree indices of the values with leaf hash h.
+func leafPositions(index map[Bytes32]int, tree []Bytes32, n int, treeIndex func(int) int, h Bytes32) []int {
+	out := leafIndices(index, tree, n, treeIndex, h)
+	for k, i := range out {
+		out[k] = treeIndex(i)
+	}
+	return out
+}
+
+// getProofWithPath returns the proof for the leaf at index with its direction
+// bits: path[i] is true when the node proven at level i is a right child, so
+// proof[i] is hashed on its left.
+func getProofWithPath(tree []Bytes32, index int) ([]string, []bool, error) {
+	proof, err := getProof(tree, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	path := make([]bool, 0, len(proof))
+	for ; index > 0; index = parent(index) {
+		path = append(path, index%2 == 0)
+	}
+	return proof, path, nil
+}
+
+// ProcessProofWithPath computes the root from a leaf, proof and direction
+// bits, hashing positionally with PositionalKeccak256Hasher. It returns
+// ErrInvalidProof if proof and path differ in length.
+func ProcessProofWithPath(leaf Bytes32, proof []string, path []bool) (string, error) {
+	return processProofWithPath(PositionalKeccak256Hasher, leaf, proof, path)
+}
+
+func processProofWithPath(h Hasher, leaf Bytes32, proof []string, path []bool) (string, error) {
+	if len(proof) != len(path) {
+		return "", ErrInvalidProof
+	}
+	current := leaf
+	for i, sib := range proof {
+		s, err := HexToBytes32(sib)
+		if err != nil {
+			return "", err
+		}
+		if path[i] {
+			current = h.NodeHash(s, current)
+		} else {
+			current = h.NodeHash(current, s)
+		}
 	}
 	return current.Hex(), nil
 }
 
+// VerifyWithPath checks that proof and its direction bits link leafHash to
+// root under PositionalKeccak256Hasher.
+func VerifyWithPath(root string, leafHash Bytes32, proof []string, path []bool) (bool, error) {
+	r, err := ProcessProofWithPath(leafHash, proof, path)
+	if err != nil {
+		return false, err
+	}
+	return r == root, nil
+}
+
 // MultiProof represents a proof for multiple leaves.
 type MultiProof struct {
 	Leaves     []string `json:"leaves"`
@@ -78,6 +564,40 @@ type MultiProof struct {
 	ProofFlags []bool   `json:"proofFlags"`
 }
 
+// Validate checks the length invariant required by on-chain multiproof
+// verifiers: len(Leaves) + len(Proof) == len(ProofFlags) + 1.
+func (mp *MultiProof) Validate() error {
+	if len(mp.Leaves)+len(mp.Proof) != len(mp.ProofFlags)+1 {
+		return ErrInvariant
+	}
+	return nil
+}
+
+// SolidityCalldata returns the multiproof arrays as expected by OpenZeppelin's
+// MerkleProof.multiProofVerify(proof, proofFlags, root, leaves). Empty arrays
+// are returned as non-nil slices so they encode as [] rather than null.
+func (mp *MultiProof) SolidityCalldata() (leaves, proof []string, flags []bool) {
+	leaves = append(make([]string, 0, len(mp.Leaves)), mp.Leaves...)
+	proof = append(make([]string, 0, len(mp.Proof)), mp.Proof...)
+	flags = append(make([]bool, 0, len(mp.ProofFlags)), mp.ProofFlags...)
+	return leaves, proof, flags
+}
+
+// FromSolidityCalldata builds a MultiProof from on-chain multiproof arguments,
+// checking that every node is a 32-byte hex value and that the lengths agree.
+func FromSolidityCalldata(leaves, proof []string, flags []bool) (*MultiProof, error) {
+	for _, node := range slices.Concat(leaves, proof) {
+		if _, err := HexToBytes32(node); err != nil {
+			return nil, err
+		}
+	}
+	mp := &MultiProof{Leaves: leaves, Proof: proof, ProofFlags: flags}
+	if err := mp.Validate(); err != nil {
+		return nil, err
+	}
+	return mp, nil
+}
+
 // GetMultiProof generates a proof for multiple leaf indices.
 func GetMultiProof(tree []string, indices []int) (*MultiProof, error) {
 	for _, i := range indices {
@@ -89,6 +609,26 @@ func GetMultiProof(tree []string, indices []int) (*MultiProof, error) {
 	sorted := slices.Clone(indices)
 	slices.SortFunc(sorted, func(a, b int) int { return b - a })
 
+	return multiProof(sorted, func(i int) (string, bool) { return tree[i], true })
+}
+
+// getMultiProof is GetMultiProof for a parsed tree.
+func getMultiProof(tree []Bytes32, indices []int) (*MultiProof, error) {
+	for _, i := range indices {
+		if err := checkLeaf(len(tree), i); err != nil {
+			return nil, err
+		}
+	}
+
+	sorted := slices.Clone(indices)
+	slices.SortFunc(sorted, func(a, b int) int { return b - a })
+
+	return multiProof(sorted, func(i int) (string, bool) { return tree[i].Hex(), true })
+}
+
+// multiProof builds a multiproof for leaf indices sorted in descending order,
+// reading nodes through node, which reports false for unknown indices.
+func multiProof(sorted []int, node func(int) (string, bool)) (*MultiProof, error) {
 	seen := make(map[int]bool)
 	for _, i := range sorted {
 		if seen[i] {
@@ -97,6 +637,15 @@ func GetMultiProof(tree []string, indices []int) (*MultiProof, error) {
 		seen[i] = true
 	}
 
+	leaves := make([]string, len(sorted))
+	for i, idx := range sorted {
+		v, ok := node(idx)
+		if !ok {
+			return nil, ErrInvariant
+		}
+		leaves[i] = v
+	}
+
 	stack := slices.Clone(sorted)
 	var proof []string
 	var flags []bool
@@ -111,8 +660,12 @@ func GetMultiProof(tree []string, indices []int) (*MultiProof, error) {
 			flags = append(flags, true)
 			stack = stack[1:]
 		} else {
+			v, ok := node(s)
+			if !ok {
+				return nil, ErrInvariant
+			}
 			flags = append(flags, false)
-			proof = append(proof, tree[s])
+			proof = append(proof, v)
 		}
 
 		pos, _ := slices.BinarySearchFunc(stack, p, func(a, b int) int { return b - a })
@@ -120,12 +673,11 @@ func GetMultiProof(tree []string, indices []int) (*MultiProof, error) {
 	}
 
 	if len(stack) != 1 {
-		proof = append(proof, tree[0])
-	}
-
-	leaves := make([]string, len(sorted))
-	for i, idx := range sorted {
-		leaves[i] = tree[idx]
+		v, ok := node(0)
+		if !ok {
+			return nil, ErrInvariant
+		}
+		proof = append(proof, v)
 	}
 
 	return &MultiProof{Leaves: leaves, Proof: proof, ProofFlags: flags}, nil
@@ -133,8 +685,17 @@ func GetMultiProof(tree []string, indices []int) (*MultiProof, error) {
 
 // ProcessMultiProof computes the root from a MultiProof.
 func ProcessMultiProof(mp *MultiProof) (string, error) {
-	if len(mp.Leaves)+len(mp.Proof) != len(mp.ProofFlags)+1 {
-		return "", ErrInvariant
+	return processMultiProof(Keccak256Hasher, mp, nil)
+}
+
+// processMultiProof computes the root from a MultiProof with h, calling visit
+// (if non-nil) for every parent hashed from a pair of nodes.
+func processMultiProof(h Hasher, mp *MultiProof, visit func(parent, a, b Bytes32)) (string, error) {
+	if !commutative(h) {
+		return "", ErrPositionalProof
+	}
+	if err := mp.Validate(); err != nil {
+		return "", err
 	}
 
 	stack := make([]Bytes32, 0, len(mp.Leaves))
@@ -172,7 +733,11 @@ func ProcessMultiProof(mp *MultiProof) (string, error) {
 			}
 			proofIdx++
 		}
-		stack = append(stack, HashNode(a, b))
+		p := h.NodeHash(a, b)
+		if visit != nil {
+			visit(p, a, b)
+		}
+		stack = append(stack, p)
 	}
 
 	if len(stack) == 1 {
@@ -186,13 +751,15 @@ func ProcessMultiProof(mp *MultiProof) (string, error) {
 
 // IsValidTree checks if tree is a valid Merkle tree.
 func IsValidTree(tree []string) bool {
+	nodes, err := parseNodes(tree)
+	return err == nil && isValidNodes(Keccak256Hasher, nodes)
+}
+
+func isValidNodes(h Hasher, tree []Bytes32) bool {
 	if len(tree) == 0 {
 		return false
 	}
 	for i, node := range tree {
-		if !isValidNode(node) {
-			return false
-		}
 		l, r := leftChild(i), rightChild(i)
 		if r >= len(tree) {
 			if l < len(tree) {
@@ -200,10 +767,7 @@ func IsValidTree(tree []string) bool {
 			}
 			continue
 		}
-		left, _ := HexToBytes32(tree[l])
-		right, _ := HexToBytes32(tree[r])
-		nodeB, _ := HexToBytes32(node)
-		if nodeB != HashNode(left, right) {
+		if node != h.NodeHash(tree[l], tree[r]) {
 			return false
 		}
 	}
@@ -268,3 +832,42 @@ func TreeLeaves(tree []string) iter.Seq2[int, string] {
 		}
 	}
 }
+
+// findDuplicate returns a *DuplicateError for the first leaf hash, in input
+// order, shared by several items, or nil.
+func findDuplicate[T any](items []T, hash func(T) Bytes32) error {
+	seen := make(map[Bytes32]int, len(items))
+	for i, it := range items {
+		h := hash(it)
+		first, ok := seen[h]
+		if !ok {
+			seen[h] = i
+			continue
+		}
+		indices := []int{first, i}
+		for j := i + 1; j < len(items); j++ {
+			if hash(items[j]) == h {
+				indices = append(indices, j)
+			}
+		}
+		return &DuplicateError{Leaf: h, Indices: indices}
+	}
+	return nil
+}
+
+// dedupLeaves keeps the first item for each distinct leaf hash, renumbering
+// the kept items' value indices with setIndex.
+func dedupLeaves[T any](items []T, hash func(T) Bytes32, setIndex func(*T, int)) []T {
+	seen := make(map[Bytes32]bool, len(items))
+	kept := items[:0]
+	for _, it := range items {
+		h := hash(it)
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		setIndex(&it, len(kept))
+		kept = append(kept, it)
+	}
+	return kept
+}
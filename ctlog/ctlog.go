@@ -0,0 +1,211 @@
+// Package ctlog implements the Merkle tree of Certificate Transparency logs
+// (RFC 6962 §2.1): tree hashes, audit paths, consistency proofs and signed
+// tree heads.
+//
+// CT trees are not gomerk trees. Leaves keep their order, leaf and node
+// hashes are domain separated with a 0x00 or 0x01 prefix, and a tree of n
+// leaves splits at the largest power of two below n rather than being
+// balanced.
+package ctlog
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/bits"
+
+	"github.com/pyroth/gomerk"
+)
+
+// LeafHash returns SHA-256(0x00 || entry).
+func LeafHash(entry []byte) gomerk.Bytes32 {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(entry)
+	return gomerk.Bytes32(h.Sum(nil))
+}
+
+// NodeHash returns SHA-256(0x01 || left || right).
+func NodeHash(left, right gomerk.Bytes32) gomerk.Bytes32 {
+	var buf [65]byte
+	buf[0] = 1
+	copy(buf[1:], left[:])
+	copy(buf[33:], right[:])
+	return sha256.Sum256(buf[:])
+}
+
+// EmptyRoot is the root of the empty tree, SHA-256 of no data.
+var EmptyRoot = gomerk.Bytes32(sha256.Sum256(nil))
+
+// Tree is an append-only log of leaf hashes. Roots and proofs can be taken
+// at any size up to the current one, as a log serves them for earlier tree
+// heads.
+type Tree struct {
+	leaves []gomerk.Bytes32
+}
+
+// Append adds an entry and returns its leaf index.
+func (t *Tree) Append(entry []byte) uint64 {
+	return t.AppendHash(LeafHash(entry))
+}
+
+// AppendHash adds a leaf by its hash and returns its leaf index.
+func (t *Tree) AppendHash(leaf gomerk.Bytes32) uint64 {
+	t.leaves = append(t.leaves, leaf)
+	return uint64(len(t.leaves) - 1)
+}
+
+// Size returns the number of leaves.
+func (t *Tree) Size() uint64 { return uint64(len(t.leaves)) }
+
+// LeafHash returns the hash of the leaf at index.
+func (t *Tree) LeafHash(index uint64) (gomerk.Bytes32, error) {
+	if index >= t.Size() {
+		return gomerk.Bytes32{}, gomerk.ErrIndexOutOfBounds
+	}
+	return t.leaves[index], nil
+}
+
+// Root returns the root of the whole tree.
+func (t *Tree) Root() gomerk.Bytes32 {
+	r, _ := t.RootAt(t.Size())
+	return r
+}
+
+// RootAt returns the root of the tree over the first size leaves.
+func (t *Tree) RootAt(size uint64) (gomerk.Bytes32, error) {
+	if size > t.Size() {
+		return gomerk.Bytes32{}, fmt.Errorf("%w: size %d of %d", gomerk.ErrIndexOutOfBounds, size, t.Size())
+	}
+	if size == 0 {
+		return EmptyRoot, nil
+	}
+	return mth(t.leaves[:size]), nil
+}
+
+// AuditPath returns the proof that the leaf at index is in the tree over the
+// first size leaves, PATH(index, D[size]) in RFC 6962.
+func (t *Tree) AuditPath(index, size uint64) ([]gomerk.Bytes32, error) {
+	if size > t.Size() || index >= size {
+		return nil, fmt.Errorf("%w: leaf %d of size %d", gomerk.ErrIndexOutOfBounds, index, size)
+	}
+	return path(index, t.leaves[:size]), nil
+}
+
+// ConsistencyProof returns the proof that the tree over the first first
+// leaves is a prefix of the tree over the first second leaves,
+// PROOF(first, D[second]) in RFC 6962. It is empty if first is 0 or equals
+// second.
+func (t *Tree) ConsistencyProof(first, second uint64) ([]gomerk.Bytes32, error) {
+	if second > t.Size() || first > second {
+		return nil, fmt.Errorf("%w: sizes %d and %d of %d", gomerk.ErrIndexOutOfBounds, first, second, t.Size())
+	}
+	if first == 0 || first == second {
+		return []gomerk.Bytes32{}, nil
+	}
+	return subproof(first, t.leaves[:second], true), nil
+}
+
+// split returns the largest power of two less than n, for n > 1.
+func split(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// mth returns the tree hash of leaves, which must not be empty.
+func mth(leaves []gomerk.Bytes32) gomerk.Bytes32 {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return NodeHash(mth(leaves[:k]), mth(leaves[k:]))
+}
+
+func path(m uint64, leaves []gomerk.Bytes32) []gomerk.Bytes32 {
+	if len(leaves) == 1 {
+		return []gomerk.Bytes32{}
+	}
+	k := split(len(leaves))
+	if m < uint64(k) {
+		return append(path(m, leaves[:k]), mth(leaves[k:]))
+	}
+	return append(path(m-uint64(k), leaves[k:]), mth(leaves[:k]))
+}
+
+func subproof(m uint64, leaves []gomerk.Bytes32, complete bool) []gomerk.Bytes32 {
+	if m == uint64(len(leaves)) {
+		if complete {
+			return []gomerk.Bytes32{}
+		}
+		return []gomerk.Bytes32{mth(leaves)}
+	}
+	k := split(len(leaves))
+	if m <= uint64(k) {
+		return append(subproof(m, leaves[:k], complete), mth(leaves[k:]))
+	}
+	return append(subproof(m-uint64(k), leaves[k:], false), mth(leaves[:k]))
+}
+
+// VerifyAuditPath reports whether proof shows that leafHash is the leaf at
+// index of the tree of size leaves with the given root.
+func VerifyAuditPath(leafHash gomerk.Bytes32, index, size uint64, proof []gomerk.Bytes32, root gomerk.Bytes32) bool {
+	if index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	return sn == 0 && r == root
+}
+
+// VerifyConsistency reports whether proof shows that the tree of first
+// leaves with root firstRoot is a prefix of the tree of second leaves with
+// root secondRoot. Every tree extends the empty one.
+func VerifyConsistency(first, second uint64, firstRoot, secondRoot gomerk.Bytes32, proof []gomerk.Bytes32) bool {
+	switch {
+	case first > second:
+		return false
+	case first == second:
+		return len(proof) == 0 && firstRoot == secondRoot
+	case first == 0:
+		return len(proof) == 0
+	}
+	// A first tree that is a complete subtree is itself the starting node.
+	if first&(first-1) == 0 {
+		proof = append([]gomerk.Bytes32{firstRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return false
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn, sn = fn>>1, sn>>1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr, sr = NodeHash(c, fr), NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	return sn == 0 && fr == firstRoot && sr == secondRoot
+}
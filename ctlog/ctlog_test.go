@@ -0,0 +1,128 @@
+package ctlog_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/ctlog"
+)
+
+// The reference inputs and roots of the Certificate Transparency test suite.
+var (
+	inputs = []string{
+		"", "00", "10", "2021", "3031", "40414243",
+		"5051525354555657", "606162636465666768696a6b6c6d6e6f",
+	}
+	roots = []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+)
+
+func referenceTree(t *testing.T) *ctlog.Tree {
+	t.Helper()
+	var tree ctlog.Tree
+	for _, s := range inputs {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Append(b)
+	}
+	return &tree
+}
+
+func TestRoots(t *testing.T) {
+	tree := referenceTree(t)
+	if r, _ := tree.RootAt(0); r.Hex() != "0xe3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Fatalf("empty root = %s", r)
+	}
+	for i, want := range roots {
+		if r, _ := tree.RootAt(uint64(i + 1)); r.Hex() != "0x"+want {
+			t.Errorf("root of %d = %s, want %s", i+1, r, want)
+		}
+	}
+	if _, err := tree.RootAt(9); err == nil {
+		t.Fatal("root beyond size")
+	}
+}
+
+func TestAuditPath(t *testing.T) {
+	var tree ctlog.Tree
+	for i := range 40 {
+		tree.Append([]byte{byte(i)})
+	}
+	for size := uint64(1); size <= tree.Size(); size++ {
+		root, _ := tree.RootAt(size)
+		for i := range size {
+			leaf, _ := tree.LeafHash(i)
+			proof, err := tree.AuditPath(i, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ctlog.VerifyAuditPath(leaf, i, size, proof, root) {
+				t.Fatalf("leaf %d of %d does not verify", i, size)
+			}
+			if len(proof) > 0 {
+				proof[0][0] ^= 1
+				if ctlog.VerifyAuditPath(leaf, i, size, proof, root) {
+					t.Fatalf("leaf %d of %d verifies with a changed proof", i, size)
+				}
+			}
+			if size > 1 && ctlog.VerifyAuditPath(leaf, (i+1)%size, size, proof, root) {
+				t.Fatalf("leaf %d of %d verifies at another index", i, size)
+			}
+		}
+	}
+	if _, err := tree.AuditPath(3, 3); err == nil {
+		t.Fatal("audit path beyond size")
+	}
+}
+
+func TestConsistencyProof(t *testing.T) {
+	var tree ctlog.Tree
+	for i := range 40 {
+		tree.Append([]byte{byte(i)})
+	}
+	for second := uint64(0); second <= tree.Size(); second++ {
+		r2, _ := tree.RootAt(second)
+		for first := uint64(0); first <= second; first++ {
+			r1, _ := tree.RootAt(first)
+			proof, err := tree.ConsistencyProof(first, second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ctlog.VerifyConsistency(first, second, r1, r2, proof) {
+				t.Fatalf("%d to %d does not verify", first, second)
+			}
+			if first == 0 {
+				continue
+			}
+			other := r1
+			other[0] ^= 1
+			if ctlog.VerifyConsistency(first, second, other, r2, proof) {
+				t.Fatalf("%d to %d verifies with a changed root", first, second)
+			}
+			for j := range proof {
+				proof[j][0] ^= 1
+				if ctlog.VerifyConsistency(first, second, r1, r2, proof) {
+					t.Fatalf("%d to %d verifies with proof node %d changed", first, second, j)
+				}
+				proof[j][0] ^= 1
+			}
+		}
+	}
+	if ctlog.VerifyConsistency(2, 1, gomerk.Bytes32{}, gomerk.Bytes32{}, nil) {
+		t.Fatal("shrinking tree verifies")
+	}
+	if _, err := tree.ConsistencyProof(5, 41); err == nil {
+		t.Fatal("consistency proof beyond size")
+	}
+}
@@ -0,0 +1,140 @@
+package ctlog
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pyroth/gomerk"
+)
+
+// ErrInvalidSignature is returned when a tree head signature does not verify.
+var ErrInvalidSignature = errors.New("invalid tree head signature")
+
+// TLS algorithm identifiers used in DigitallySigned structs (RFC 5246).
+const (
+	hashSHA256 = 4
+	sigRSA     = 1
+	sigECDSA   = 3
+)
+
+// TreeHead is the state of a log that its tree head signature covers.
+type TreeHead struct {
+	TreeSize uint64
+	// Timestamp is in milliseconds since the Unix epoch.
+	Timestamp uint64
+	RootHash  gomerk.Bytes32
+}
+
+// SignedTreeHead is a TreeHead with the log's signature, a TLS
+// DigitallySigned struct. It marshals to JSON as a get-sth response.
+type SignedTreeHead struct {
+	TreeHead
+	Signature []byte
+}
+
+// TreeHead returns the head of the tree over the first size leaves at time
+// ts.
+func (t *Tree) TreeHead(size uint64, ts time.Time) (TreeHead, error) {
+	root, err := t.RootAt(size)
+	if err != nil {
+		return TreeHead{}, err
+	}
+	return TreeHead{TreeSize: size, Timestamp: uint64(ts.UnixMilli()), RootHash: root}, nil
+}
+
+// signedData returns the serialized TreeHeadSignature struct of RFC 6962
+// §3.5, version v1.
+func (h TreeHead) signedData() []byte {
+	b := []byte{0, 1} // v1, tree_hash
+	b = binary.BigEndian.AppendUint64(b, h.Timestamp)
+	b = binary.BigEndian.AppendUint64(b, h.TreeSize)
+	return append(b, h.RootHash[:]...)
+}
+
+// Sign signs the tree head with an ECDSA or RSA key, as logs do.
+func (h TreeHead) Sign(signer crypto.Signer) (*SignedTreeHead, error) {
+	alg, err := sigAlgorithm(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(h.signedData())
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) > 0xffff {
+		return nil, fmt.Errorf("%w: %d byte signature", gomerk.ErrUnsupportedType, len(sig))
+	}
+	ds := append([]byte{hashSHA256, alg}, byte(len(sig)>>8), byte(len(sig)))
+	return &SignedTreeHead{TreeHead: h, Signature: append(ds, sig...)}, nil
+}
+
+// Verify checks the signature against the log's public key.
+func (s *SignedTreeHead) Verify(pub crypto.PublicKey) error {
+	alg, err := sigAlgorithm(pub)
+	if err != nil {
+		return err
+	}
+	ds := s.Signature
+	if len(ds) < 4 || ds[0] != hashSHA256 || ds[1] != alg || int(ds[2])<<8|int(ds[3]) != len(ds)-4 {
+		return fmt.Errorf("%w: malformed DigitallySigned", ErrInvalidSignature)
+	}
+	sig := ds[4:]
+	digest := sha256.Sum256(s.signedData())
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+			return ErrInvalidSignature
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return ErrInvalidSignature
+		}
+	}
+	return nil
+}
+
+func sigAlgorithm(pub crypto.PublicKey) (byte, error) {
+	switch pub.(type) {
+	case *ecdsa.PublicKey:
+		return sigECDSA, nil
+	case *rsa.PublicKey:
+		return sigRSA, nil
+	}
+	return 0, fmt.Errorf("%w: %T log key", gomerk.ErrUnsupportedType, pub)
+}
+
+// sthJSON is the get-sth response of RFC 6962 §4.3.
+type sthJSON struct {
+	TreeSize          uint64 `json:"tree_size"`
+	Timestamp         uint64 `json:"timestamp"`
+	SHA256RootHash    []byte `json:"sha256_root_hash"`
+	TreeHeadSignature []byte `json:"tree_head_signature"`
+}
+
+func (s SignedTreeHead) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sthJSON{s.TreeSize, s.Timestamp, s.RootHash[:], s.Signature})
+}
+
+func (s *SignedTreeHead) UnmarshalJSON(data []byte) error {
+	var j sthJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if len(j.SHA256RootHash) != 32 {
+		return gomerk.ErrInvalidNodeLength
+	}
+	*s = SignedTreeHead{
+		TreeHead:  TreeHead{TreeSize: j.TreeSize, Timestamp: j.Timestamp, RootHash: gomerk.Bytes32(j.SHA256RootHash)},
+		Signature: j.TreeHeadSignature,
+	}
+	return nil
+}
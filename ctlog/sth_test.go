@@ -0,0 +1,76 @@
+package ctlog_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/ctlog"
+)
+
+func TestSignedTreeHead(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	tree := referenceTree(t)
+	head, err := tree.TreeHead(8, time.UnixMilli(1700000000123))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.RootHash.Hex() != "0x"+roots[7] || head.Timestamp != 1700000000123 {
+		t.Fatalf("head = %+v", head)
+	}
+
+	for _, key := range []crypto.Signer{ecKey, rsaKey} {
+		sth, err := head.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sth.Verify(key.Public()); err != nil {
+			t.Fatal(err)
+		}
+
+		b, _ := json.Marshal(sth)
+		var got ctlog.SignedTreeHead
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := got.Verify(key.Public()); err != nil || got.TreeHead != head {
+			t.Fatalf("%s: %v", b, err)
+		}
+
+		got.TreeSize++
+		if err := got.Verify(key.Public()); !errors.Is(err, ctlog.ErrInvalidSignature) {
+			t.Fatal(err)
+		}
+	}
+
+	sth, _ := head.Sign(ecKey)
+	if err := sth.Verify(rsaKey.Public()); !errors.Is(err, ctlog.ErrInvalidSignature) {
+		t.Fatal(err)
+	}
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := head.Sign(edKey); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Fatal(err)
+	}
+}
+
+func TestSignedTreeHeadJSON(t *testing.T) {
+	var sth ctlog.SignedTreeHead
+	err := json.Unmarshal([]byte(`{"tree_size":1,"timestamp":2,"sha256_root_hash":"AAAA","tree_head_signature":""}`), &sth)
+	if !errors.Is(err, gomerk.ErrInvalidNodeLength) {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(ctlog.SignedTreeHead{TreeHead: ctlog.TreeHead{TreeSize: 3, Timestamp: 4}, Signature: []byte{1}})
+	want := `{"tree_size":3,"timestamp":4,"sha256_root_hash":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=","tree_head_signature":"AQ=="}`
+	if string(b) != want {
+		t.Fatalf("json = %s", b)
+	}
+}
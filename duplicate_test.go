@@ -0,0 +1,116 @@
+package gomerk_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestRejectDuplicates(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := airdropData(6)
+	values = append(values, values[1], []any{values[4][0], values[4][1]}, values[1])
+
+	_, err := gomerk.BuildStandardMerkleTree(values, enc, gomerk.WithDuplicates(gomerk.DuplicatesReject))
+	var de *gomerk.DuplicateError
+	if !errors.As(err, &de) || !errors.Is(err, gomerk.ErrDuplicateLeaf) {
+		t.Fatalf("err = %v", err)
+	}
+	if !slices.Equal(de.Indices, []int{1, 6, 8}) {
+		t.Errorf("indices = %v, want [1 6 8]", de.Indices)
+	}
+	tree0, _ := gomerk.NewStandardMerkleTree(values[:2], enc, true)
+	if want, _ := tree0.LeafHash(values[1]); de.Leaf != want {
+		t.Errorf("leaf = %s, want %s", de.Leaf.Hex(), want.Hex())
+	}
+
+	tree, err := gomerk.BuildStandardMerkleTree(values[:6], enc, gomerk.WithDuplicates(gomerk.DuplicatesReject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := tree.Root()
+	if err := tree.Append(airdropData(8)[6], values[3]); !errors.As(err, &de) || !slices.Equal(de.Indices, []int{3, 7}) {
+		t.Errorf("Append: err = %v", err)
+	}
+	if _, err := tree.UpdateLeafByIndex(5, values[2]); !errors.As(err, &de) || !slices.Equal(de.Indices, []int{2, 5}) {
+		t.Errorf("UpdateLeafByIndex: err = %v", err)
+	}
+	if tree.Root() != root || tree.Len() != 6 {
+		t.Error("rejected changes modified the tree")
+	}
+	if _, err := tree.UpdateLeafByIndex(2, values[2]); err != nil {
+		t.Errorf("updating a value to itself: %v", err)
+	}
+
+	_, err = gomerk.NewSimpleMerkleTree(simpleLeaves(3)[:2], true, gomerk.WithDuplicates(gomerk.DuplicatesReject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := append(simpleLeaves(3), simpleLeaves(1)...)
+	if _, err := gomerk.NewSimpleMerkleTree(leaves, true, gomerk.WithDuplicates(gomerk.DuplicatesReject)); !errors.As(err, &de) || !slices.Equal(de.Indices, []int{0, 3}) {
+		t.Errorf("simple: err = %v", err)
+	}
+	simple, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(3), true, gomerk.WithDuplicates(gomerk.DuplicatesReject))
+	root = simple.Root()
+	if err := simple.Append(simpleLeaves(5)[4], simpleLeaves(2)[1]); !errors.As(err, &de) || !slices.Equal(de.Indices, []int{1, 4}) {
+		t.Errorf("simple Append: err = %v", err)
+	}
+	if err := simple.Append(simpleLeaves(5)[4], simpleLeaves(5)[4]); !errors.As(err, &de) || !slices.Equal(de.Indices, []int{3, 4}) {
+		t.Errorf("simple Append repeated value: err = %v", err)
+	}
+	if simple.Root() != root || simple.Len() != 3 {
+		t.Error("rejected Append modified the simple tree")
+	}
+}
+
+func TestDuplicatesDrop(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := append(airdropData(4), airdropData(2)...)
+	a, _ := gomerk.BuildStandardMerkleTree(values, enc, gomerk.WithDuplicates(gomerk.DuplicatesDrop))
+	b, _ := gomerk.BuildStandardMerkleTree(values, enc, gomerk.WithDeduplication())
+	if a.Len() != 4 || !a.Equal(b) {
+		t.Errorf("len %d, equal to WithDeduplication %v", a.Len(), a.Equal(b))
+	}
+}
+
+func TestIndicesOf(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := append(airdropData(5), airdropData(2)...)
+	for _, sorted := range []bool{true, false} {
+		tree, _ := gomerk.NewStandardMerkleTree(values, enc, sorted)
+		if got, err := tree.IndicesOf(values[1]); err != nil || !slices.Equal(got, []int{1, 6}) {
+			t.Errorf("sorted=%v: IndicesOf duplicate = %v, %v", sorted, got, err)
+		}
+		if got, err := tree.IndicesOf(values[3]); err != nil || !slices.Equal(got, []int{3}) {
+			t.Errorf("sorted=%v: IndicesOf single = %v, %v", sorted, got, err)
+		}
+		if _, err := tree.IndicesOf(airdropData(9)[8]); !errors.Is(err, gomerk.ErrLeafNotInTree) {
+			t.Errorf("sorted=%v: missing value: err = %v", sorted, err)
+		}
+	}
+
+	leaves := append(simpleLeaves(3), simpleLeaves(3)...)
+	tree, _ := gomerk.NewSimpleMerkleTree(leaves, true)
+	if got, err := tree.IndicesOf(leaves[2]); err != nil || !slices.Equal(got, []int{2, 5}) {
+		t.Errorf("simple: IndicesOf = %v, %v", got, err)
+	}
+}
+
+func TestBuildTreeFileRejectDuplicates(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := append(airdropData(10), airdropData(10)[7])
+	path := filepath.Join(t.TempDir(), "tree.bin")
+	_, err := gomerk.BuildTreeFile(path, valueSeq(values), enc,
+		gomerk.WithDuplicates(gomerk.DuplicatesReject), gomerk.WithSortBuffer(4))
+	if !errors.Is(err, gomerk.ErrDuplicateLeaf) {
+		t.Errorf("err = %v", err)
+	}
+	_, err = gomerk.BuildTreeFile(path, valueSeq(values), enc,
+		gomerk.WithDuplicates(gomerk.DuplicatesReject), gomerk.WithSortLeaves(false))
+	if !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("unsorted: err = %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package gomerk
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EIP712Field is a member of an EIP-712 struct type.
+type EIP712Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EIP712Type defines the EIP-712 struct type of a tree's leaves. Types holds
+// PrimaryType and every struct type it references. Values of struct members
+// are given as []any in field order, like tuples.
+type EIP712Type struct {
+	PrimaryType string                   `json:"primaryType"`
+	Types       map[string][]EIP712Field `json:"types"`
+
+	// DomainSeparator, if set, makes each leaf the digest
+	// keccak256("\x19\x01" || domainSeparator || hashStruct(value)) that
+	// OpenZeppelin's _hashTypedDataV4 returns, rather than hashStruct(value).
+	DomainSeparator string `json:"domainSeparator,omitempty"`
+}
+
+// LeafEncoding returns the member types of the primary type, the leaf
+// encoding of trees built with WithEIP712Leaves(t).
+func (t *EIP712Type) LeafEncoding() []string {
+	fields := t.Types[t.PrimaryType]
+	enc := make([]string, len(fields))
+	for i, f := range fields {
+		enc[i] = f.Type
+	}
+	return enc
+}
+
+// EncodeType returns the EIP-712 encodeType string of the primary type, such
+// as "Mail(Person from,Person to,string contents)Person(string name,address
+// wallet)".
+func (t *EIP712Type) EncodeType() (string, error) { return t.encodeType(t.PrimaryType) }
+
+func (t *EIP712Type) encodeType(name string) (string, error) {
+	if _, ok := t.Types[name]; !ok {
+		return "", fmt.Errorf("%w: EIP-712 type %s", ErrUnsupportedType, name)
+	}
+	deps := map[string]bool{}
+	t.collectDeps(name, deps)
+	delete(deps, name)
+	names := append([]string{name}, slices.Sorted(func(yield func(string) bool) {
+		for d := range deps {
+			if !yield(d) {
+				return
+			}
+		}
+	})...)
+	var b strings.Builder
+	for _, n := range names {
+		b.WriteString(n + "(")
+		for i, f := range t.Types[n] {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(f.Type + " " + f.Name)
+		}
+		b.WriteByte(')')
+	}
+	return b.String(), nil
+}
+
+func (t *EIP712Type) collectDeps(name string, deps map[string]bool) {
+	if deps[name] {
+		return
+	}
+	deps[name] = true
+	for _, f := range t.Types[name] {
+		if base := eip712BaseType(f.Type); t.Types[base] != nil {
+			t.collectDeps(base, deps)
+		}
+	}
+}
+
+// eip712BaseType strips array suffixes from typ.
+func eip712BaseType(typ string) string {
+	if i := strings.IndexByte(typ, '['); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// TypeHash returns keccak256(EncodeType()).
+func (t *EIP712Type) TypeHash() (Bytes32, error) {
+	enc, err := t.EncodeType()
+	if err != nil {
+		return Bytes32{}, err
+	}
+	return Keccak256([]byte(enc)), nil
+}
+
+// HashStruct returns the EIP-712 hashStruct of value, an instance of the
+// primary type.
+func (t *EIP712Type) HashStruct(value []any) (Bytes32, error) {
+	return t.hashStruct(t.PrimaryType, value)
+}
+
+func (t *EIP712Type) hashStruct(name string, value []any) (Bytes32, error) {
+	buf, err := t.encodeStruct(name, value)
+	if err != nil {
+		return Bytes32{}, err
+	}
+	return Keccak256(buf), nil
+}
+
+// encodeStruct returns the type hash of name followed by the encoded members
+// of value, the input of hashStruct.
+func (t *EIP712Type) encodeStruct(name string, value []any) ([]byte, error) {
+	enc, err := t.encodeType(name)
+	if err != nil {
+		return nil, err
+	}
+	fields := t.Types[name]
+	if len(value) != len(fields) {
+		return nil, &EncodeError{Index: -1, Field: -1, Err: ErrMismatchedCount}
+	}
+	typeHash := Keccak256([]byte(enc))
+	buf := append(make([]byte, 0, 32*(len(fields)+1)), typeHash[:]...)
+	for i, f := range fields {
+		w, err := t.encodeData(f.Type, value[i])
+		if err != nil {
+			return nil, &EncodeError{Index: -1, Field: i, Type: f.Type, Err: err}
+		}
+		buf = append(buf, w...)
+	}
+	return buf, nil
+}
+
+// encodeData encodes a member as one word: atomic types as in abi.encode,
+// strings and bytes by their hash, arrays by the hash of their encoded
+// elements and structs by their hashStruct.
+func (t *EIP712Type) encodeData(typ string, val any) ([]byte, error) {
+	if i := strings.LastIndexByte(typ, '['); i >= 0 && strings.HasSuffix(typ, "]") {
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, ErrAbiEncode
+		}
+		if n := typ[i+1 : len(typ)-1]; n != "" {
+			if size, err := strconv.Atoi(n); err != nil || size != rv.Len() {
+				return nil, ErrAbiEncode
+			}
+		}
+		buf := make([]byte, 0, 32*rv.Len())
+		for k := range rv.Len() {
+			w, err := t.encodeData(typ[:i], rv.Index(k).Interface())
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, w...)
+		}
+		h := Keccak256(buf)
+		return h[:], nil
+	}
+	if _, ok := t.Types[typ]; ok {
+		v, ok := val.([]any)
+		if !ok {
+			return nil, ErrAbiEncode
+		}
+		h, err := t.hashStruct(typ, v)
+		return h[:], err
+	}
+	if strings.ContainsAny(typ, "()") {
+		return nil, ErrUnsupportedType
+	}
+	return leafCodec{}.encodeValue(typ, val)
+}
+
+// leafHash returns the leaf of value: its hashStruct, or its typed data
+// digest if a domain separator is set.
+func (t *EIP712Type) leafHash(value []any) (Bytes32, error) {
+	buf, err := t.leafPreimage(value)
+	if err != nil {
+		return Bytes32{}, err
+	}
+	return Keccak256(buf), nil
+}
+
+// leafPreimage returns the bytes whose keccak256 is the leaf of value: the
+// input of its hashStruct, or 0x1901, the domain separator and its hashStruct
+// if a domain separator is set.
+func (t *EIP712Type) leafPreimage(value []any) ([]byte, error) {
+	buf, err := t.encodeStruct(t.PrimaryType, value)
+	if err != nil || t.DomainSeparator == "" {
+		return buf, err
+	}
+	sep, err := HexToBytes32(t.DomainSeparator)
+	if err != nil {
+		return nil, err
+	}
+	h := Keccak256(buf)
+	return slices.Concat([]byte{0x19, 0x01}, sep[:], h[:]), nil
+}
+
+// EIP712Domain is an EIP-712 signing domain. Empty fields are left out of the
+// domain type, as eth_signTypedData implementations do.
+type EIP712Domain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract string
+	Salt              string
+}
+
+// Separator returns the domain separator, the hashStruct of the domain.
+func (d EIP712Domain) Separator() (Bytes32, error) {
+	var (
+		fields []EIP712Field
+		values []any
+	)
+	add := func(name, typ string, val any) {
+		fields = append(fields, EIP712Field{name, typ})
+		values = append(values, val)
+	}
+	if d.Name != "" {
+		add("name", "string", d.Name)
+	}
+	if d.Version != "" {
+		add("version", "string", d.Version)
+	}
+	if d.ChainID != nil {
+		add("chainId", "uint256", d.ChainID)
+	}
+	if d.VerifyingContract != "" {
+		add("verifyingContract", "address", d.VerifyingContract)
+	}
+	if d.Salt != "" {
+		add("salt", "bytes32", d.Salt)
+	}
+	t := EIP712Type{PrimaryType: "EIP712Domain", Types: map[string][]EIP712Field{"EIP712Domain": fields}}
+	return t.HashStruct(values)
+}
+
+// VerifyEIP712 checks a proof for value in a tree built with
+// WithEIP712Leaves(typ).
+func VerifyEIP712(root string, typ *EIP712Type, value []any, proof []string) (bool, error) {
+	h, err := typ.leafHash(value)
+	if err != nil {
+		return false, err
+	}
+	return Verify(root, h, proof)
+}
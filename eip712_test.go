@@ -0,0 +1,180 @@
+package gomerk_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+// mailType and mailValue are the example from the EIP-712 specification.
+func mailType() *gomerk.EIP712Type {
+	return &gomerk.EIP712Type{
+		PrimaryType: "Mail",
+		Types: map[string][]gomerk.EIP712Field{
+			"Person": {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+			"Mail":   {{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "contents", Type: "string"}},
+		},
+	}
+}
+
+func mailValue(contents string) []any {
+	return []any{
+		[]any{"Cow", "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		[]any{"Bob", "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		contents,
+	}
+}
+
+func TestEIP712SpecVector(t *testing.T) {
+	typ := mailType()
+	enc, err := typ.EncodeType()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; enc != want {
+		t.Errorf("EncodeType() = %q, want %q", enc, want)
+	}
+	typeHash, _ := typ.TypeHash()
+	if want := "0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"; typeHash.Hex() != want {
+		t.Errorf("TypeHash() = %s, want %s", typeHash.Hex(), want)
+	}
+	h, err := typ.HashStruct(mailValue("Hello, Bob!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"; h.Hex() != want {
+		t.Errorf("HashStruct() = %s, want %s", h.Hex(), want)
+	}
+
+	sep, err := gomerk.EIP712Domain{
+		Name:              "Ether Mail",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+	}.Separator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; sep.Hex() != want {
+		t.Errorf("Separator() = %s, want %s", sep.Hex(), want)
+	}
+
+	// With a domain separator a one-leaf tree's root is the signing digest.
+	typ.DomainSeparator = sep.Hex()
+	tree, err := gomerk.BuildStandardMerkleTree([][]any{mailValue("Hello, Bob!")}, nil, gomerk.WithEIP712Leaves(typ))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; tree.Root() != want {
+		t.Errorf("root = %s, want %s", tree.Root(), want)
+	}
+}
+
+func TestEIP712Leaves(t *testing.T) {
+	typ := mailType()
+	var values [][]any
+	for i := range 6 {
+		values = append(values, mailValue(fmt.Sprintf("message %d", i)))
+	}
+	tree, err := gomerk.BuildStandardMerkleTree(values, nil, gomerk.WithEIP712Leaves(typ))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc := tree.LeafEncoding(); !slices.Equal(enc, []string{"Person", "Person", "string"}) {
+		t.Fatalf("leaf encoding = %v", enc)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range tree.All() {
+		proof, err := tree.GetProofByIndex(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := gomerk.VerifyEIP712(tree.Root(), typ, v, proof); err != nil || !ok {
+			t.Errorf("VerifyEIP712(%d) = %v, %v", i, ok, err)
+		}
+		h, _ := typ.HashStruct(v)
+		if leaf, _ := tree.LeafHash(v); leaf != h {
+			t.Errorf("leaf %d = %s, want hashStruct %s", i, leaf.Hex(), h.Hex())
+		}
+	}
+
+	data, err := json.Marshal(tree.Dump())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := tree.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("WriteTo() differs from json.Marshal(Dump())")
+	}
+	if streamed, err := gomerk.LoadStandardMerkleTreeFrom(&buf); err != nil || streamed.Root() != tree.Root() {
+		t.Errorf("LoadStandardMerkleTreeFrom() = %v", err)
+	}
+
+	var dump gomerk.StandardTreeData
+	if err := json.Unmarshal(data, &dump); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := gomerk.LoadStandardMerkleTree(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Root() != tree.Root() {
+		t.Errorf("loaded root = %s, want %s", loaded.Root(), tree.Root())
+	}
+	if _, err := loaded.GetProof(mailValue("message 3")); err != nil {
+		t.Errorf("GetProof on loaded tree: %v", err)
+	}
+}
+
+func TestEIP712Arrays(t *testing.T) {
+	typ := &gomerk.EIP712Type{
+		PrimaryType: "Batch",
+		Types: map[string][]gomerk.EIP712Field{
+			"Batch": {{Name: "ids", Type: "uint256[]"}, {Name: "tags", Type: "bytes32[2]"}},
+		},
+	}
+	tag := "0x" + fmt.Sprintf("%064x", 7)
+	got, err := typ.HashStruct([]any{[]any{1, 2}, []any{tag, tag}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	word := func(n int) []byte { return new(big.Int).SetInt64(int64(n)).FillBytes(make([]byte, 32)) }
+	typeHash := gomerk.Keccak256([]byte("Batch(uint256[] ids,bytes32[2] tags)"))
+	ids := gomerk.Keccak256(slices.Concat(word(1), word(2)))
+	tags := gomerk.Keccak256(slices.Concat(word(7), word(7)))
+	if want := gomerk.Keccak256(slices.Concat(typeHash[:], ids[:], tags[:])); got != want {
+		t.Errorf("HashStruct() = %s, want %s", got.Hex(), want.Hex())
+	}
+
+	if _, err := typ.HashStruct([]any{[]any{1}, []any{tag}}); !errors.Is(err, gomerk.ErrAbiEncode) {
+		t.Errorf("short fixed array: err = %v, want ErrAbiEncode", err)
+	}
+}
+
+func TestEIP712Errors(t *testing.T) {
+	typ := mailType()
+	if _, err := gomerk.BuildStandardMerkleTree([][]any{mailValue("x")}, []string{"string"}, gomerk.WithEIP712Leaves(typ)); !errors.Is(err, gomerk.ErrMismatchedCount) {
+		t.Errorf("mismatched encoding: err = %v", err)
+	}
+	if _, err := gomerk.BuildStandardMerkleTree([][]any{mailValue("x")}, nil, gomerk.WithEIP712Leaves(typ), gomerk.WithSaltedLeaves(nil)); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("salted: err = %v", err)
+	}
+	if _, err := typ.HashStruct([]any{"x"}); !errors.Is(err, gomerk.ErrMismatchedCount) {
+		t.Errorf("short value: err = %v", err)
+	}
+	missing := &gomerk.EIP712Type{PrimaryType: "Mail", Types: map[string][]gomerk.EIP712Field{}}
+	if _, err := missing.TypeHash(); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("missing type: err = %v", err)
+	}
+}
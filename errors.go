@@ -1,18 +1,81 @@
 package gomerk
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrEmptyTree         = errors.New("expected non-zero number of leaves")
-	ErrInvalidNodeLength = errors.New("expected 32 bytes")
-	ErrNotALeaf          = errors.New("index is not a leaf")
-	ErrLeafNotInTree     = errors.New("leaf is not in tree")
-	ErrDuplicatedIndex   = errors.New("cannot prove duplicated index")
-	ErrIndexOutOfBounds  = errors.New("index out of bounds")
-	ErrInvalidFormat     = errors.New("invalid tree format")
-	ErrInvariant         = errors.New("invariant violation")
-	ErrInvalidHex        = errors.New("invalid hex string")
-	ErrAbiEncode         = errors.New("abi encoding error")
-	ErrUnsupportedType   = errors.New("unsupported type")
-	ErrMismatchedCount   = errors.New("mismatched leaf encoding count")
+	ErrEmptyTree            = errors.New("expected non-zero number of leaves")
+	ErrInvalidNodeLength    = errors.New("expected 32 bytes")
+	ErrNotALeaf             = errors.New("index is not a leaf")
+	ErrLeafNotInTree        = errors.New("leaf is not in tree")
+	ErrDuplicatedIndex      = errors.New("cannot prove duplicated index")
+	ErrIndexOutOfBounds     = errors.New("index out of bounds")
+	ErrInvalidFormat        = errors.New("invalid tree format")
+	ErrInvariant            = errors.New("invariant violation")
+	ErrInvalidHex           = errors.New("invalid hex string")
+	ErrAbiEncode            = errors.New("abi encoding error")
+	ErrUnsupportedType      = errors.New("unsupported type")
+	ErrMismatchedCount      = errors.New("mismatched leaf encoding count")
+	ErrInvalidAddress       = errors.New("invalid address")
+	ErrDuplicatedID         = errors.New("duplicated id")
+	ErrProofTooLong         = errors.New("proof exceeds maximum length")
+	ErrRootMismatch         = errors.New("root mismatch")
+	ErrInvalidProofEncoding = errors.New("invalid proof encoding")
+	ErrValuesNotRetained    = errors.New("tree values were not retained")
+	ErrNonCanonicalProof    = errors.New("proof is not in canonical order")
+	ErrTreeFull             = errors.New("tree is full")
+	ErrInvalidDepth         = errors.New("invalid tree depth")
+	ErrTreeNotSorted        = errors.New("tree leaves are not sorted")
+	ErrInvalidProof         = errors.New("invalid proof")
+	ErrInvalidMMRSize       = errors.New("invalid mmr size")
+	ErrUnknownHasher        = errors.New("unknown hasher")
+	ErrZeroValue            = errors.New("value must be non-zero")
+	ErrSumOverflow          = errors.New("sum exceeds uint256")
+	ErrReadOnly             = errors.New("store is read-only")
+	ErrInvalidIdentifier    = errors.New("invalid solidity identifier")
+	ErrDuplicateLeaf        = errors.New("duplicate leaf")
+	ErrLeafInTree           = errors.New("leaf is in tree")
+	ErrPositionalProof      = errors.New("positional hashing needs proofs with path")
+	ErrNotPositional        = errors.New("hasher is not positional")
 )
+
+// EncodeError reports a failure to encode a leaf value. Index is the position
+// of the value in the input and Field the position within the value; either
+// is -1 when not applicable.
+type EncodeError struct {
+	Index, Field int
+	Type         string
+	Err          error
+}
+
+func (e *EncodeError) Error() string {
+	msg := e.Err.Error()
+	if e.Field >= 0 {
+		msg = fmt.Sprintf("field %d (%s): %s", e.Field, e.Type, msg)
+	}
+	if e.Index >= 0 {
+		msg = fmt.Sprintf("value %d: %s", e.Index, msg)
+	}
+	return msg
+}
+
+func (e *EncodeError) Unwrap() error { return e.Err }
+
+// DuplicateError reports values with the same leaf hash in a tree that
+// rejects duplicates. Indices are the positions of the values in the input,
+// or nil when unknown.
+type DuplicateError struct {
+	Leaf    Bytes32
+	Indices []int
+}
+
+func (e *DuplicateError) Error() string {
+	if e.Indices == nil {
+		return fmt.Sprintf("%v %s", ErrDuplicateLeaf, e.Leaf.Hex())
+	}
+	return fmt.Sprintf("%v %s: values %v", ErrDuplicateLeaf, e.Leaf.Hex(), e.Indices)
+}
+
+func (e *DuplicateError) Unwrap() error { return ErrDuplicateLeaf }
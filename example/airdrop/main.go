@@ -4,40 +4,43 @@
 package main
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"strings"
 
 	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/ingest"
+	"github.com/pyroth/gomerk/server"
 )
 
 var encoding = []string{"address", "uint256"}
 
 func main() {
-	cmd := flag.String("cmd", "generate", "Command: generate|serve")
+	cmd := flag.String("cmd", "generate", "Command: generate|serve|verify")
 	csvFile := flag.String("csv", "airdrop.csv", "Input CSV file")
 	treeFile := flag.String("tree", "airdrop-tree.json", "Tree output file")
 	proofsFile := flag.String("proofs", "airdrop-proofs.json", "Proofs output file")
+	contractFile := flag.String("contract", "AirdropClaim.sol", "Solidity contract output file")
 	addr := flag.String("addr", ":8080", "Server address")
 	flag.Parse()
 
 	switch *cmd {
 	case "generate":
-		generate(*csvFile, *treeFile, *proofsFile)
+		generate(*csvFile, *treeFile, *proofsFile, *contractFile)
 	case "serve":
 		serve(*treeFile, *addr)
+	case "verify":
+		verify(*treeFile, *proofsFile)
 	default:
 		log.Fatalf("Unknown command: %s", *cmd)
 	}
 }
 
-// generate builds merkle tree from CSV and exports proofs.
-func generate(csvPath, treePath, proofsPath string) {
+// generate builds merkle tree from CSV and exports proofs and a claim contract.
+func generate(csvPath, treePath, proofsPath, contractPath string) {
 	// Load recipients
 	recipients := must(loadCSV(csvPath))
 	fmt.Printf("Loaded %d recipients\n", len(recipients))
@@ -50,19 +53,32 @@ func generate(csvPath, treePath, proofsPath string) {
 	os.WriteFile(treePath, must(json.MarshalIndent(tree.Dump(), "", "  ")), 0644)
 	fmt.Printf("Tree saved to %s\n", treePath)
 
-	// Generate all proofs
-	proofs := make(map[string]ProofData)
-	for i, v := range tree.All() {
-		addr := v[0].(string)
-		proofs[strings.ToLower(addr)] = ProofData{
-			Address: addr,
-			Amount:  v[1].(string),
-			Proof:   must(tree.GetProofByIndex(i)),
-		}
-	}
-
-	os.WriteFile(proofsPath, must(json.MarshalIndent(proofs, "", "  ")), 0644)
+	// Export all proofs
+	bundle := must(tree.ExportProofs())
+	os.WriteFile(proofsPath, must(json.MarshalIndent(bundle, "", "  ")), 0644)
 	fmt.Printf("Proofs saved to %s\n", proofsPath)
+
+	// Generate claim contract
+	src := must(tree.GenerateSolidityVerifier(gomerk.SolidityOptions{
+		Name:       "AirdropClaim",
+		FieldNames: []string{"account", "amount"},
+	}))
+	os.WriteFile(contractPath, []byte(src), 0644)
+	fmt.Printf("Contract saved to %s\n", contractPath)
+}
+
+// verify checks that the proofs file matches the tree file.
+func verify(treePath, proofsPath string) {
+	var treeData gomerk.StandardTreeData
+	must0(json.Unmarshal(must(os.ReadFile(treePath)), &treeData))
+	tree := must(gomerk.LoadStandardMerkleTree(treeData))
+	var bundle gomerk.ProofBundle
+	must0(json.Unmarshal(must(os.ReadFile(proofsPath)), &bundle))
+
+	if !must(gomerk.VerifyBundleAgainstTrustedRoot(bundle, tree.Root())) {
+		log.Fatalf("Proofs in %s do not verify", proofsPath)
+	}
+	fmt.Printf("All %d proofs match %s\n", len(bundle.Entries), treePath)
 }
 
 // serve starts HTTP API for proof queries.
@@ -75,46 +91,12 @@ func serve(treePath, addr string) {
 	fmt.Printf("Loaded tree with %d leaves\n", tree.Len())
 	fmt.Printf("Root: %s\n", tree.Root())
 
-	// Build index for O(1) lookup
-	index := make(map[string]int)
-	for i, v := range tree.All() {
-		index[strings.ToLower(v[0].(string))] = i
-	}
-
-	http.HandleFunc("/proof/", func(w http.ResponseWriter, r *http.Request) {
-		addr := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/proof/"))
-		i, ok := index[addr]
-		if !ok {
-			http.Error(w, `{"error":"address not found"}`, http.StatusNotFound)
-			return
-		}
-
-		v, _ := tree.At(i)
-		proof, _ := tree.GetProofByIndex(i)
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(ProofData{
-			Address: v[0].(string),
-			Amount:  v[1].(string),
-			Proof:   proof,
-		})
-	})
-
-	http.HandleFunc("/root", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"root": tree.Root()})
-	})
+	http.Handle("/", must(server.New(tree)))
 
 	fmt.Printf("Server listening on %s\n", addr)
 	log.Fatal(http.ListenAndServe(addr, nil))
 }
 
-type ProofData struct {
-	Address string   `json:"address"`
-	Amount  string   `json:"amount"`
-	Proof   []string `json:"proof"`
-}
-
 func loadCSV(path string) ([][]any, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -122,16 +104,7 @@ func loadCSV(path string) ([][]any, error) {
 	}
 	defer f.Close()
 
-	records, err := csv.NewReader(f).ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	var result [][]any
-	for _, row := range records[1:] { // skip header
-		result = append(result, []any{row[0], row[1]})
-	}
-	return result, nil
+	return ingest.FromCSV(f, ingest.Schema{Types: encoding, Header: true}).ReadAll()
 }
 
 func must[T any](v T, err error) T {
@@ -0,0 +1,344 @@
+package gomerk
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+)
+
+// defaultSortBuffer is the number of leaf hashes sorted in memory per run by
+// BuildTreeFile, 32 MiB of hashes.
+const defaultSortBuffer = 1 << 20
+
+// BuildTreeFile builds a standard tree over values too many to hold in
+// memory and writes it to path in the tree file layout read by OpenMmapTree.
+// Leaf hashes are sorted in runs of WithSortBuffer leaves that are spilled to
+// temporary files in WithTempDir and merged, and the tree is then hashed
+// level by level in the output file, so memory use stays bounded by the sort
+// buffer. Values are not retained; see MmapTree.FindLeaf to locate a leaf.
+// It returns the root, which matches BuildStandardMerkleTree's for the same
+// values and options.
+func BuildTreeFile(path string, values iter.Seq2[[]any, error], leafEncoding []string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	if o.unsorted && (o.dedup || o.rejectDuplicates) {
+		return "", fmt.Errorf("%w: duplicate detection in unsorted leaves", ErrUnsupportedType)
+	}
+	bufSize := o.sortBuffer
+	if bufSize <= 0 {
+		bufSize = defaultSortBuffer
+	}
+	codec := o.codec()
+
+	var runs []*os.File
+	defer func() {
+		for _, f := range runs {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	buf := make([]Bytes32, 0, bufSize)
+	spill := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		if !o.unsorted {
+			slices.SortFunc(buf, Bytes32.Compare)
+		}
+		f, err := os.CreateTemp(o.tempDir, "gomerk-run-*")
+		if err != nil {
+			return err
+		}
+		runs = append(runs, f)
+		w := bufio.NewWriter(f)
+		for _, h := range buf {
+			w.Write(h[:])
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		buf = buf[:0]
+		_, err = f.Seek(0, io.SeekStart)
+		return err
+	}
+
+	i := 0
+	for v, err := range values {
+		if err != nil {
+			return "", err
+		}
+		h, err := codec.encodeAndHash(leafEncoding, v)
+		if err != nil {
+			return "", withValueIndex(err, i)
+		}
+		buf = append(buf, h)
+		if len(buf) == cap(buf) {
+			if err := spill(); err != nil {
+				return "", err
+			}
+		}
+		i++
+	}
+	if err := spill(); err != nil {
+		return "", err
+	}
+	buf = nil
+
+	leaves, err := mergeRuns(runs, !o.unsorted, o.dedup, o.rejectDuplicates)
+	if err != nil {
+		return "", err
+	}
+	return writeTreeFileFrom(path, leaves, o.hash(), bufSize)
+}
+
+// WithSortBuffer sets how many leaf hashes BuildTreeFile sorts in memory
+// at a time.
+func WithSortBuffer(leaves int) Option {
+	return func(o *options) { o.sortBuffer = leaves }
+}
+
+// WithTempDir sets the directory BuildTreeFile spills sorted runs to,
+// os.TempDir() by default.
+func WithTempDir(dir string) Option {
+	return func(o *options) { o.tempDir = dir }
+}
+
+// leafStream yields the merged leaves in tree order, and their count once
+// known.
+type leafStream struct {
+	n    int
+	next func() (Bytes32, bool, error)
+	// rewind restarts the stream for a second pass.
+	rewind func() error
+}
+
+// mergeRuns returns a stream over runs: merged in order if sorted, otherwise
+// concatenated. Duplicates of the previous leaf are dropped if dedup is set,
+// or fail with a *DuplicateError if reject is set.
+func mergeRuns(runs []*os.File, sorted, dedup, reject bool) (*leafStream, error) {
+	s := &leafStream{}
+	s.rewind = func() error {
+		readers := make([]*bufio.Reader, len(runs))
+		for i, f := range runs {
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			readers[i] = bufio.NewReader(f)
+		}
+		var h runHeap
+		next := 0
+		for i, r := range readers {
+			if !sorted {
+				break
+			}
+			if err := h.pushFrom(r, i); err != nil {
+				return err
+			}
+		}
+		heap.Init(&h)
+		var (
+			prev    Bytes32
+			started bool
+		)
+		s.next = func() (Bytes32, bool, error) {
+			for {
+				var leaf Bytes32
+				if sorted {
+					if h.Len() == 0 {
+						return Bytes32{}, false, nil
+					}
+					top := h[0]
+					leaf = top.head
+					if err := h.advance(readers[top.run]); err != nil {
+						return Bytes32{}, false, err
+					}
+				} else {
+					for ; next < len(readers); next++ {
+						if _, err := io.ReadFull(readers[next], leaf[:]); err == nil {
+							break
+						} else if err != io.EOF {
+							return Bytes32{}, false, err
+						}
+					}
+					if next == len(readers) {
+						return Bytes32{}, false, nil
+					}
+				}
+				if started && leaf == prev {
+					if reject {
+						return Bytes32{}, false, &DuplicateError{Leaf: leaf}
+					}
+					if dedup {
+						continue
+					}
+				}
+				prev, started = leaf, true
+				return leaf, true, nil
+			}
+		}
+		return nil
+	}
+	if err := s.rewind(); err != nil {
+		return nil, err
+	}
+	for {
+		_, ok, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		s.n++
+	}
+	return s, s.rewind()
+}
+
+// runHead is the smallest unread hash of a sorted run.
+type runHead struct {
+	head Bytes32
+	run  int
+}
+
+type runHeap []runHead
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return h[i].head.Less(h[j].head) }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)        { *h = append(*h, x.(runHead)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// pushFrom appends the first hash of run r, if any, without fixing the heap.
+func (h *runHeap) pushFrom(r *bufio.Reader, run int) error {
+	var b Bytes32
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	*h = append(*h, runHead{b, run})
+	return nil
+}
+
+// advance replaces the top of the heap with the next hash of its run, r.
+func (h *runHeap) advance(r *bufio.Reader) error {
+	if _, err := io.ReadFull(r, (*h)[0].head[:]); err != nil {
+		if err != io.EOF {
+			return err
+		}
+		heap.Pop(h)
+		return nil
+	}
+	heap.Fix(h, 0)
+	return nil
+}
+
+// writeTreeFileFrom writes the tree over leaves to path, hashing internal
+// nodes in chunks of up to chunk nodes read back from the file.
+func writeTreeFileFrom(path string, leaves *leafStream, hasher Hasher, chunk int) (string, error) {
+	n := leaves.n
+	if n == 0 {
+		return "", ErrEmptyTree
+	}
+	total := 2*n - 1
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	ok := false
+	defer func() {
+		if !ok {
+			f.Close()
+			os.Remove(path)
+		}
+	}()
+	var header [treeFileHeader]byte
+	copy(header[:], treeFileMagic)
+	binary.BigEndian.PutUint64(header[len(treeFileMagic):], uint64(total))
+	if _, err := f.WriteAt(header[:], 0); err != nil {
+		return "", err
+	}
+	offset := func(i int) int64 { return treeFileHeader + 32*int64(i) }
+
+	// Leaf k goes to tree index total-1-k, so leaves are written backwards
+	// a chunk at a time.
+	block := make([]byte, 0, 32*chunk)
+	flush := func(k int) error {
+		if len(block) == 0 {
+			return nil
+		}
+		reverseNodes(block)
+		_, err := f.WriteAt(block, offset(total-k))
+		block = block[:0]
+		return err
+	}
+	for k := 0; ; k++ {
+		leaf, more, err := leaves.next()
+		if err != nil {
+			return "", err
+		}
+		if !more {
+			if err := flush(k); err != nil {
+				return "", err
+			}
+			break
+		}
+		block = append(block, leaf[:]...)
+		if len(block) == cap(block) {
+			if err := flush(k + 1); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	// Internal nodes [lo, hi) have their children in [2lo+1, 2hi+1), so
+	// nodes are hashed from the end of the file towards the root, in
+	// chunks that stop at hi/2 so that no child is in the same chunk.
+	children := make([]byte, 64*chunk)
+	parents := make([]byte, 32*chunk)
+	for hi := n - 1; hi > 0; {
+		lo := max(hi-chunk, hi/2)
+		c := children[:64*(hi-lo)]
+		if _, err := f.ReadAt(c, offset(2*lo+1)); err != nil {
+			return "", err
+		}
+		p := parents[:32*(hi-lo)]
+		for j := range hi - lo {
+			h := hasher.NodeHash(Bytes32(c[64*j:64*j+32]), Bytes32(c[64*j+32:64*j+64]))
+			copy(p[32*j:], h[:])
+		}
+		if _, err := f.WriteAt(p, offset(lo)); err != nil {
+			return "", err
+		}
+		hi = lo
+	}
+	var root Bytes32
+	if _, err := f.ReadAt(root[:], offset(0)); err != nil {
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	ok = true
+	return root.Hex(), nil
+}
+
+// reverseNodes reverses the order of the 32-byte nodes in b.
+func reverseNodes(b []byte) {
+	var tmp Bytes32
+	for i, j := 0, len(b)-32; i < j; i, j = i+32, j-32 {
+		copy(tmp[:], b[i:i+32])
+		copy(b[i:i+32], b[j:j+32])
+		copy(b[j:j+32], tmp[:])
+	}
+}
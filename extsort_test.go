@@ -0,0 +1,111 @@
+package gomerk_test
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func valueSeq(values [][]any) iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		for _, v := range values {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestBuildTreeFile(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	dir := t.TempDir()
+	for _, n := range []int{1, 2, 3, 7, 8, 33, 100} {
+		for _, sorted := range []bool{true, false} {
+			values := airdropData(n)
+			want, _ := gomerk.NewStandardMerkleTree(values, enc, sorted)
+			wantPath := filepath.Join(dir, "want.bin")
+			want.WriteTreeFile(wantPath)
+
+			path := filepath.Join(dir, "tree.bin")
+			root, err := gomerk.BuildTreeFile(path, valueSeq(values), enc,
+				gomerk.WithSortLeaves(sorted), gomerk.WithSortBuffer(5), gomerk.WithTempDir(dir))
+			if err != nil {
+				t.Fatalf("n=%d sorted=%v: %v", n, sorted, err)
+			}
+			if root != want.Root() {
+				t.Errorf("n=%d sorted=%v: root %s, want %s", n, sorted, root, want.Root())
+			}
+			got, _ := os.ReadFile(path)
+			wantData, _ := os.ReadFile(wantPath)
+			if !bytes.Equal(got, wantData) {
+				t.Errorf("n=%d sorted=%v: tree file differs from WriteTreeFile", n, sorted)
+			}
+		}
+	}
+	if entries, _ := filepath.Glob(filepath.Join(dir, "gomerk-run-*")); len(entries) != 0 {
+		t.Errorf("temporary runs left behind: %v", entries)
+	}
+}
+
+func TestBuildTreeFileDedup(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := append(airdropData(20), airdropData(20)...)
+	want, _ := gomerk.BuildStandardMerkleTree(values, enc, gomerk.WithDeduplication())
+	path := filepath.Join(t.TempDir(), "tree.bin")
+	root, err := gomerk.BuildTreeFile(path, valueSeq(values), enc, gomerk.WithDeduplication(), gomerk.WithSortBuffer(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != want.Root() {
+		t.Errorf("root %s, want %s", root, want.Root())
+	}
+
+	m, err := gomerk.OpenMmapTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	for i, v := range want.All() {
+		h, _ := want.LeafHash(v)
+		k, ok := m.FindLeaf(h)
+		if !ok {
+			t.Fatalf("FindLeaf(value %d) not found", i)
+		}
+		proof, _ := m.GetProofByIndex(k)
+		if ok, _ := want.VerifyParsed(v, proof); !ok {
+			t.Errorf("proof of value %d at leaf %d does not verify", i, k)
+		}
+	}
+	if _, ok := m.FindLeaf(gomerk.Keccak256([]byte("absent"))); ok {
+		t.Error("FindLeaf found an absent leaf")
+	}
+}
+
+func TestBuildTreeFileErrors(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	path := filepath.Join(t.TempDir(), "tree.bin")
+	if _, err := gomerk.BuildTreeFile(path, valueSeq(nil), enc); !errors.Is(err, gomerk.ErrEmptyTree) {
+		t.Errorf("empty: err = %v", err)
+	}
+	if _, err := gomerk.BuildTreeFile(path, valueSeq(airdropData(2)), enc, gomerk.WithSortLeaves(false), gomerk.WithDeduplication()); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("unsorted dedup: err = %v", err)
+	}
+	var e *gomerk.EncodeError
+	bad := append(airdropData(3), []any{"0x12", "1"})
+	if _, err := gomerk.BuildTreeFile(path, valueSeq(bad), enc); !errors.As(err, &e) || e.Index != 3 {
+		t.Errorf("bad value: err = %v", err)
+	}
+	sentinel := errors.New("read failed")
+	failing := func(yield func([]any, error) bool) { yield(nil, sentinel) }
+	if _, err := gomerk.BuildTreeFile(path, failing, enc); !errors.Is(err, sentinel) {
+		t.Errorf("failing source: err = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("output file exists after errors: %v", err)
+	}
+}
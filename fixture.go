@@ -0,0 +1,171 @@
+package gomerk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// FixtureFormat selects the layout written by WriteFixture.
+type FixtureFormat int
+
+const (
+	// FixtureForge writes leaves as objects with one key per value field,
+	// in alphabetical key order, so forge can read them with stdJson or
+	// abi.decode(vm.parseJson(json, ".leaves"), (Leaf[])).
+	FixtureForge FixtureFormat = iota
+
+	// FixtureHardhat writes leaves with their values as arrays, and
+	// multiproof leaves as values like OpenZeppelin's JS library.
+	FixtureHardhat
+)
+
+// FixtureOptions configures WriteFixture.
+type FixtureOptions struct {
+	Format FixtureFormat
+
+	// Sample is the number of leaves to include, spread evenly over the
+	// tree. All leaves are included if it is zero.
+	Sample int
+
+	// FieldNames names the value fields of forge leaves, value0, value1
+	// and so on by default.
+	FieldNames []string
+}
+
+type forgeFixture struct {
+	LeafEncoding []string         `json:"leafEncoding"`
+	Leaves       []map[string]any `json:"leaves"`
+	MultiProof   *MultiProof      `json:"multiProof"`
+	Root         string           `json:"root"`
+}
+
+type hardhatFixture struct {
+	Root         string             `json:"root"`
+	LeafEncoding []string           `json:"leafEncoding"`
+	Leaves       []hardhatLeaf      `json:"leaves"`
+	MultiProof   *hardhatMultiProof `json:"multiProof"`
+}
+
+type hardhatLeaf struct {
+	Index int      `json:"index"`
+	Value []any    `json:"value"`
+	Leaf  string   `json:"leaf"`
+	Proof []string `json:"proof"`
+}
+
+type hardhatMultiProof struct {
+	StandardMultiProof
+	LeafHashes []string `json:"leafHashes"`
+}
+
+// WriteFixture writes the tree's root and a sample of its leaves, with their
+// leaf hashes and proofs and a multiproof of them, as JSON test fixtures for
+// Solidity test suites. Values are written as in Dump.
+func (t *StandardMerkleTree) WriteFixture(w io.Writer, opts FixtureOptions) error {
+	if !t.HasValues() {
+		return ErrValuesNotRetained
+	}
+	if opts.FieldNames != nil && len(opts.FieldNames) != len(t.leafEncoding) {
+		return ErrMismatchedCount
+	}
+	indices := sampleIndices(len(t.values), opts.Sample)
+	values := make([][]any, len(indices))
+	for k, i := range indices {
+		values[k], _ = canonicalFields(t.leafEncoding, t.values[i].Value)
+	}
+	smp, err := t.GetMultiProof(values)
+	if err != nil {
+		return err
+	}
+	if smp.Proof == nil {
+		smp.Proof = []string{}
+	}
+	if smp.ProofFlags == nil {
+		smp.ProofFlags = []bool{}
+	}
+	hashes := make([]string, len(smp.Leaves))
+	for k, v := range smp.Leaves {
+		h, _ := t.LeafHash(v)
+		hashes[k] = h.Hex()
+	}
+
+	var doc any
+	switch opts.Format {
+	case FixtureForge:
+		f := forgeFixture{
+			LeafEncoding: t.leafEncoding,
+			Leaves:       make([]map[string]any, len(indices)),
+			MultiProof:   &MultiProof{Leaves: hashes, Proof: smp.Proof, ProofFlags: smp.ProofFlags},
+			Root:         t.Root(),
+		}
+		for k, i := range indices {
+			leaf := map[string]any{
+				"index": i,
+				"leaf":  t.tree[t.treeIndex(i)].Hex(),
+				"proof": t.proofByIndex(i),
+			}
+			for j, v := range values[k] {
+				name := fmt.Sprintf("value%d", j)
+				if opts.FieldNames != nil {
+					name = opts.FieldNames[j]
+				}
+				if _, ok := leaf[name]; ok {
+					return fmt.Errorf("%w: field name %q", ErrInvalidIdentifier, name)
+				}
+				leaf[name] = v
+			}
+			f.Leaves[k] = leaf
+		}
+		doc = f
+	case FixtureHardhat:
+		f := hardhatFixture{
+			Root:         t.Root(),
+			LeafEncoding: t.leafEncoding,
+			Leaves:       make([]hardhatLeaf, len(indices)),
+			MultiProof:   &hardhatMultiProof{StandardMultiProof: *smp, LeafHashes: hashes},
+		}
+		for k, i := range indices {
+			f.Leaves[k] = hardhatLeaf{
+				Index: i,
+				Value: values[k],
+				Leaf:  t.tree[t.treeIndex(i)].Hex(),
+				Proof: t.proofByIndex(i),
+			}
+		}
+		doc = f
+	default:
+		return fmt.Errorf("%w: fixture format %d", ErrInvalidFormat, opts.Format)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// proofByIndex returns the proof of the value at index i, which must be in
+// range.
+func (t *StandardMerkleTree) proofByIndex(i int) []string {
+	proof, _ := t.GetProofByIndex(i)
+	if proof == nil {
+		proof = []string{}
+	}
+	return proof
+}
+
+// sampleIndices returns k indices spread evenly over [0, n), including the
+// first and last, or all of them if k is zero or at least n.
+func sampleIndices(n, k int) []int {
+	if k <= 0 || k >= n {
+		k = n
+	}
+	out := make([]int, 0, k)
+	for j := range k {
+		i := 0
+		if k > 1 {
+			i = j * (n - 1) / (k - 1)
+		}
+		out = append(out, i)
+	}
+	return slices.Compact(out)
+}
@@ -0,0 +1,112 @@
+package gomerk_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestWriteFixtureForge(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(10), []string{"address", "uint256"}, true)
+	var buf bytes.Buffer
+	err := tree.WriteFixture(&buf, gomerk.FixtureOptions{Sample: 4, FieldNames: []string{"account", "amount"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f struct {
+		Leaves []struct {
+			Account string      `json:"account"`
+			Amount  json.Number `json:"amount"`
+			Index   int         `json:"index"`
+			Leaf    string      `json:"leaf"`
+			Proof   []string    `json:"proof"`
+		} `json:"leaves"`
+		MultiProof gomerk.MultiProof `json:"multiProof"`
+		Root       string            `json:"root"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Root != tree.Root() {
+		t.Errorf("root = %s, want %s", f.Root, tree.Root())
+	}
+	indices := make([]int, len(f.Leaves))
+	for k, l := range f.Leaves {
+		indices[k] = l.Index
+		if ok, err := tree.Verify([]any{l.Account, l.Amount.String()}, l.Proof); err != nil || !ok {
+			t.Errorf("leaf %d does not verify: %v", l.Index, err)
+		}
+		if h, _ := tree.LeafHash([]any{l.Account, l.Amount.String()}); h.Hex() != l.Leaf {
+			t.Errorf("leaf %d hash = %s, want %s", l.Index, l.Leaf, h.Hex())
+		}
+	}
+	if want := []int{0, 3, 6, 9}; !slices.Equal(indices, want) {
+		t.Errorf("sampled indices = %v, want %v", indices, want)
+	}
+	if ok, err := tree.VerifyMultiProof(&f.MultiProof); err != nil || !ok {
+		t.Errorf("multiproof does not verify: %v", err)
+	}
+
+	// Leaf objects keep alphabetical keys for abi.decode into structs.
+	s := buf.String()
+	if i, j := strings.Index(s, `"account"`), strings.Index(s, `"amount"`); i < 0 || j < i {
+		t.Errorf("leaf keys are not in alphabetical order:\n%s", s)
+	}
+}
+
+func TestWriteFixtureHardhat(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(5), []string{"address", "uint256"}, true)
+	var buf bytes.Buffer
+	if err := tree.WriteFixture(&buf, gomerk.FixtureOptions{Format: gomerk.FixtureHardhat}); err != nil {
+		t.Fatal(err)
+	}
+	var f struct {
+		Root   string `json:"root"`
+		Leaves []struct {
+			Index int      `json:"index"`
+			Value []any    `json:"value"`
+			Proof []string `json:"proof"`
+		} `json:"leaves"`
+		MultiProof struct {
+			gomerk.StandardMultiProof
+			LeafHashes []string `json:"leafHashes"`
+		} `json:"multiProof"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &f); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Leaves) != tree.Len() {
+		t.Fatalf("got %d leaves, want %d", len(f.Leaves), tree.Len())
+	}
+	for _, l := range f.Leaves {
+		if ok, err := tree.Verify(l.Value, l.Proof); err != nil || !ok {
+			t.Errorf("leaf %d does not verify: %v", l.Index, err)
+		}
+	}
+	if ok, err := tree.VerifyMultiProofValues(&f.MultiProof.StandardMultiProof); err != nil || !ok {
+		t.Errorf("multiproof values do not verify: %v", err)
+	}
+	mp := gomerk.MultiProof{Leaves: f.MultiProof.LeafHashes, Proof: f.MultiProof.Proof, ProofFlags: f.MultiProof.ProofFlags}
+	if ok, err := tree.VerifyMultiProof(&mp); err != nil || !ok {
+		t.Errorf("multiproof hashes do not verify: %v", err)
+	}
+}
+
+func TestWriteFixtureErrors(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(3), []string{"address", "uint256"}, true)
+	var buf bytes.Buffer
+	if err := tree.WriteFixture(&buf, gomerk.FixtureOptions{FieldNames: []string{"proof", "amount"}}); err == nil {
+		t.Error("expected error for a field named proof")
+	}
+	if err := tree.WriteFixture(&buf, gomerk.FixtureOptions{FieldNames: []string{"account"}}); err == nil {
+		t.Error("expected error for missing field names")
+	}
+	noValues, _ := gomerk.NewStandardMerkleTree(airdropData(3), []string{"address", "uint256"}, true, gomerk.WithoutValues())
+	if err := noValues.WriteFixture(&buf, gomerk.FixtureOptions{}); err == nil {
+		t.Error("expected error for a tree without values")
+	}
+}
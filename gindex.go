@@ -0,0 +1,101 @@
+package gomerk
+
+import "math/bits"
+
+// GIndexOf returns the generalized index of the node at tree index i.
+// Generalized indices number the nodes of a binary tree from 1 at the root,
+// with the children of node g at 2g and 2g+1, as in SSZ light-client proofs.
+// Trees here are laid out in the same order, so it is i+1.
+func GIndexOf(i int) int { return i + 1 }
+
+// GetProofByGIndex returns the proof of the node of tree with generalized
+// index gindex, leaf or internal, ordered from the node up. The root, gindex
+// 1, has an empty proof.
+func GetProofByGIndex(tree []string, gindex int) ([]string, error) {
+	if !isTreeNode(len(tree), gindex-1) {
+		return nil, ErrIndexOutOfBounds
+	}
+	proof := []string{}
+	for g := gindex; g > 1; g >>= 1 {
+		proof = append(proof, tree[(g^1)-1])
+	}
+	return proof, nil
+}
+
+// GIndexPath returns the direction bits of the proof of the node with
+// generalized index gindex, as GetProofWithPath does: path[i] is true when
+// proof[i] is the left sibling.
+func GIndexPath(gindex int) []bool {
+	path := make([]bool, 0, bits.Len(uint(gindex))-1)
+	for g := gindex; g > 1; g >>= 1 {
+		path = append(path, g&1 == 1)
+	}
+	return path
+}
+
+// VerifyByGIndex checks that proof links node, at generalized index gindex,
+// to root under PositionalKeccak256Hasher. The proof must have one sibling
+// per level below the root.
+func VerifyByGIndex(root string, node Bytes32, proof []string, gindex int) (bool, error) {
+	return verifyByGIndex(PositionalKeccak256Hasher, root, node, proof, gindex)
+}
+
+// VerifyByGIndex is VerifyByGIndex with the verifier's hasher.
+func (v *Verifier) VerifyByGIndex(root string, node Bytes32, proof []string, gindex int) (bool, error) {
+	if v.opts.maxProofLength > 0 && len(proof) > v.opts.maxProofLength {
+		return false, ErrProofTooLong
+	}
+	return verifyByGIndex(v.opts.hash(), root, node, proof, gindex)
+}
+
+func verifyByGIndex(h Hasher, root string, node Bytes32, proof []string, gindex int) (bool, error) {
+	if gindex < 1 {
+		return false, ErrIndexOutOfBounds
+	}
+	r, err := processProofWithPath(h, node, proof, GIndexPath(gindex))
+	if err != nil {
+		return false, err
+	}
+	return r == root, nil
+}
+
+// GIndexOfLeaf returns the generalized index of the leaf of the value at
+// index i.
+func (t *StandardMerkleTree) GIndexOfLeaf(i int) (int, error) {
+	if i < 0 || i >= len(t.values) {
+		return 0, ErrIndexOutOfBounds
+	}
+	return GIndexOf(t.values[i].TreeIndex), nil
+}
+
+// GetProofByGIndex returns the proof of the node with generalized index
+// gindex, as GetProofByGIndex does for a core tree.
+func (t *StandardMerkleTree) GetProofByGIndex(gindex int) ([]string, error) {
+	return getProofByGIndex(t.tree, gindex)
+}
+
+// GIndexOfLeaf returns the generalized index of the leaf of the value at
+// index i.
+func (t *SimpleMerkleTree) GIndexOfLeaf(i int) (int, error) {
+	if i < 0 || i >= len(t.values) {
+		return 0, ErrIndexOutOfBounds
+	}
+	return GIndexOf(t.values[i].TreeIndex), nil
+}
+
+// GetProofByGIndex returns the proof of the node with generalized index
+// gindex, as GetProofByGIndex does for a core tree.
+func (t *SimpleMerkleTree) GetProofByGIndex(gindex int) ([]string, error) {
+	return getProofByGIndex(t.tree, gindex)
+}
+
+func getProofByGIndex(tree []Bytes32, gindex int) ([]string, error) {
+	if !isTreeNode(len(tree), gindex-1) {
+		return nil, ErrIndexOutOfBounds
+	}
+	proof := []string{}
+	for g := gindex; g > 1; g >>= 1 {
+		proof = append(proof, tree[(g^1)-1].Hex())
+	}
+	return proof, nil
+}
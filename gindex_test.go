@@ -0,0 +1,75 @@
+package gomerk_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestGIndexProofs(t *testing.T) {
+	tree, _ := gomerk.BuildSimpleMerkleTree(simpleLeaves(11), gomerk.WithPositionalHashing())
+	for i := range tree.Len() {
+		g, err := tree.GIndexOfLeaf(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := tree.GetProofByGIndex(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, path, _ := tree.GetProofWithPath(i)
+		if !slices.Equal(proof, want) || !slices.Equal(gomerk.GIndexPath(g), path) {
+			t.Errorf("value %d: gindex %d proof differs from GetProofWithPath", i, g)
+		}
+		v, _ := tree.At(i)
+		b, _ := gomerk.HexToBytes32(v)
+		if ok, err := gomerk.VerifyByGIndex(tree.Root(), gomerk.HashLeaf(b[:]), proof, g); !ok || err != nil {
+			t.Errorf("value %d: verify = %v, %v", i, ok, err)
+		}
+		if ok, _ := gomerk.VerifyByGIndex(tree.Root(), gomerk.HashLeaf(b[:]), proof, g^1); ok && g > 1 {
+			t.Errorf("value %d: verified at its sibling's gindex", i)
+		}
+	}
+
+	// Internal nodes have proofs too: node 2 is the root's left child.
+	dump := tree.Dump().Tree
+	left, _ := gomerk.HexToBytes32(dump[1])
+	proof, _ := tree.GetProofByGIndex(2)
+	if !slices.Equal(proof, []string{dump[2]}) {
+		t.Errorf("gindex 2 proof = %v", proof)
+	}
+	if ok, _ := gomerk.VerifyByGIndex(tree.Root(), left, proof, 2); !ok {
+		t.Error("internal node proof did not verify")
+	}
+	if core, _ := gomerk.GetProofByGIndex(dump, 2); !slices.Equal(core, proof) {
+		t.Errorf("core proof = %v, want %v", core, proof)
+	}
+	if root, _ := tree.GetProofByGIndex(1); len(root) != 0 {
+		t.Errorf("root proof = %v", root)
+	}
+
+	for _, g := range []int{0, len(dump) + 1} {
+		if _, err := tree.GetProofByGIndex(g); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+			t.Errorf("gindex %d: err = %v", g, err)
+		}
+	}
+	if _, err := tree.GIndexOfLeaf(11); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("GIndexOfLeaf: err = %v", err)
+	}
+}
+
+func TestStandardGIndex(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(6), enc, true)
+	v := gomerk.NewVerifier()
+	for i, value := range tree.All() {
+		g, _ := tree.GIndexOfLeaf(i)
+		proof, _ := tree.GetProofByGIndex(g)
+		h, _ := tree.LeafHash(value)
+		if ok, err := v.VerifyByGIndex(tree.Root(), h, proof, g); !ok || err != nil {
+			t.Errorf("value %d: verify = %v, %v", i, ok, err)
+		}
+	}
+}
@@ -0,0 +1,569 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: proof.proto
+
+package grpcserver
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetRootRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRootRequest) Reset() {
+	*x = GetRootRequest{}
+	mi := &file_proof_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRootRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRootRequest) ProtoMessage() {}
+
+func (x *GetRootRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proof_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRootRequest.ProtoReflect.Descriptor instead.
+func (*GetRootRequest) Descriptor() ([]byte, []int) {
+	return file_proof_proto_rawDescGZIP(), []int{0}
+}
+
+type GetRootResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          string                 `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	LeafEncoding  []string               `protobuf:"bytes,2,rep,name=leaf_encoding,json=leafEncoding,proto3" json:"leaf_encoding,omitempty"`
+	LeafCount     uint64                 `protobuf:"varint,3,opt,name=leaf_count,json=leafCount,proto3" json:"leaf_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRootResponse) Reset() {
+	*x = GetRootResponse{}
+	mi := &file_proof_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRootResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRootResponse) ProtoMessage() {}
+
+func (x *GetRootResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proof_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRootResponse.ProtoReflect.Descriptor instead.
+func (*GetRootResponse) Descriptor() ([]byte, []int) {
+	return file_proof_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetRootResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *GetRootResponse) GetLeafEncoding() []string {
+	if x != nil {
+		return x.LeafEncoding
+	}
+	return nil
+}
+
+func (x *GetRootResponse) GetLeafCount() uint64 {
+	if x != nil {
+		return x.LeafCount
+	}
+	return 0
+}
+
+type GetProofRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Leaf:
+	//
+	//	*GetProofRequest_Index
+	//	*GetProofRequest_Value
+	Leaf          isGetProofRequest_Leaf `protobuf_oneof:"leaf"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProofRequest) Reset() {
+	*x = GetProofRequest{}
+	mi := &file_proof_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofRequest) ProtoMessage() {}
+
+func (x *GetProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proof_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofRequest.ProtoReflect.Descriptor instead.
+func (*GetProofRequest) Descriptor() ([]byte, []int) {
+	return file_proof_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetProofRequest) GetLeaf() isGetProofRequest_Leaf {
+	if x != nil {
+		return x.Leaf
+	}
+	return nil
+}
+
+func (x *GetProofRequest) GetIndex() uint64 {
+	if x != nil {
+		if x, ok := x.Leaf.(*GetProofRequest_Index); ok {
+			return x.Index
+		}
+	}
+	return 0
+}
+
+func (x *GetProofRequest) GetValue() string {
+	if x != nil {
+		if x, ok := x.Leaf.(*GetProofRequest_Value); ok {
+			return x.Value
+		}
+	}
+	return ""
+}
+
+type isGetProofRequest_Leaf interface {
+	isGetProofRequest_Leaf()
+}
+
+type GetProofRequest_Index struct {
+	// Index of the value in the tree.
+	Index uint64 `protobuf:"varint,1,opt,name=index,proto3,oneof"`
+}
+
+type GetProofRequest_Value struct {
+	// The value as a JSON array.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3,oneof"`
+}
+
+func (*GetProofRequest_Index) isGetProofRequest_Leaf() {}
+
+func (*GetProofRequest_Value) isGetProofRequest_Leaf() {}
+
+type GetProofResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Proof         []string               `protobuf:"bytes,3,rep,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProofResponse) Reset() {
+	*x = GetProofResponse{}
+	mi := &file_proof_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofResponse) ProtoMessage() {}
+
+func (x *GetProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proof_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofResponse.ProtoReflect.Descriptor instead.
+func (*GetProofResponse) Descriptor() ([]byte, []int) {
+	return file_proof_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetProofResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GetProofResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *GetProofResponse) GetProof() []string {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type GetMultiProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Indices       []uint64               `protobuf:"varint,1,rep,packed,name=indices,proto3" json:"indices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMultiProofRequest) Reset() {
+	*x = GetMultiProofRequest{}
+	mi := &file_proof_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMultiProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMultiProofRequest) ProtoMessage() {}
+
+func (x *GetMultiProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proof_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMultiProofRequest.ProtoReflect.Descriptor instead.
+func (*GetMultiProofRequest) Descriptor() ([]byte, []int) {
+	return file_proof_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetMultiProofRequest) GetIndices() []uint64 {
+	if x != nil {
+		return x.Indices
+	}
+	return nil
+}
+
+// MultiProof is a multiproof over leaf values, ordered as the proof
+// consumes them.
+type MultiProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Leaves        []string               `protobuf:"bytes,1,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	Proof         []string               `protobuf:"bytes,2,rep,name=proof,proto3" json:"proof,omitempty"`
+	ProofFlags    []bool                 `protobuf:"varint,3,rep,packed,name=proof_flags,json=proofFlags,proto3" json:"proof_flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiProof) Reset() {
+	*x = MultiProof{}
+	mi := &file_proof_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiProof) ProtoMessage() {}
+
+func (x *MultiProof) ProtoReflect() protoreflect.Message {
+	mi := &file_proof_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiProof.ProtoReflect.Descriptor instead.
+func (*MultiProof) Descriptor() ([]byte, []int) {
+	return file_proof_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MultiProof) GetLeaves() []string {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+func (x *MultiProof) GetProof() []string {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *MultiProof) GetProofFlags() []bool {
+	if x != nil {
+		return x.ProofFlags
+	}
+	return nil
+}
+
+type VerifyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Proof         []string               `protobuf:"bytes,2,rep,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	mi := &file_proof_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proof_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_proof_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerifyRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *VerifyRequest) GetProof() []string {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type VerifyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	mi := &file_proof_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proof_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_proof_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VerifyResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+var File_proof_proto protoreflect.FileDescriptor
+
+const file_proof_proto_rawDesc = "" +
+	"\n" +
+	"\vproof.proto\x12\tgomerk.v1\"\x10\n" +
+	"\x0eGetRootRequest\"i\n" +
+	"\x0fGetRootResponse\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12#\n" +
+	"\rleaf_encoding\x18\x02 \x03(\tR\fleafEncoding\x12\x1d\n" +
+	"\n" +
+	"leaf_count\x18\x03 \x01(\x04R\tleafCount\"I\n" +
+	"\x0fGetProofRequest\x12\x16\n" +
+	"\x05index\x18\x01 \x01(\x04H\x00R\x05index\x12\x16\n" +
+	"\x05value\x18\x02 \x01(\tH\x00R\x05valueB\x06\n" +
+	"\x04leaf\"T\n" +
+	"\x10GetProofResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x14\n" +
+	"\x05proof\x18\x03 \x03(\tR\x05proof\"0\n" +
+	"\x14GetMultiProofRequest\x12\x18\n" +
+	"\aindices\x18\x01 \x03(\x04R\aindices\"[\n" +
+	"\n" +
+	"MultiProof\x12\x16\n" +
+	"\x06leaves\x18\x01 \x03(\tR\x06leaves\x12\x14\n" +
+	"\x05proof\x18\x02 \x03(\tR\x05proof\x12\x1f\n" +
+	"\vproof_flags\x18\x03 \x03(\bR\n" +
+	"proofFlags\";\n" +
+	"\rVerifyRequest\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
+	"\x05proof\x18\x02 \x03(\tR\x05proof\"&\n" +
+	"\x0eVerifyResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid2\x9d\x02\n" +
+	"\fProofService\x12@\n" +
+	"\aGetRoot\x12\x19.gomerk.v1.GetRootRequest\x1a\x1a.gomerk.v1.GetRootResponse\x12C\n" +
+	"\bGetProof\x12\x1a.gomerk.v1.GetProofRequest\x1a\x1b.gomerk.v1.GetProofResponse\x12G\n" +
+	"\rGetMultiProof\x12\x1f.gomerk.v1.GetMultiProofRequest\x1a\x15.gomerk.v1.MultiProof\x12=\n" +
+	"\x06Verify\x12\x18.gomerk.v1.VerifyRequest\x1a\x19.gomerk.v1.VerifyResponseB%Z#github.com/pyroth/gomerk/grpcserverb\x06proto3"
+
+var (
+	file_proof_proto_rawDescOnce sync.Once
+	file_proof_proto_rawDescData []byte
+)
+
+func file_proof_proto_rawDescGZIP() []byte {
+	file_proof_proto_rawDescOnce.Do(func() {
+		file_proof_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proof_proto_rawDesc), len(file_proof_proto_rawDesc)))
+	})
+	return file_proof_proto_rawDescData
+}
+
+var file_proof_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_proof_proto_goTypes = []any{
+	(*GetRootRequest)(nil),       // 0: gomerk.v1.GetRootRequest
+	(*GetRootResponse)(nil),      // 1: gomerk.v1.GetRootResponse
+	(*GetProofRequest)(nil),      // 2: gomerk.v1.GetProofRequest
+	(*GetProofResponse)(nil),     // 3: gomerk.v1.GetProofResponse
+	(*GetMultiProofRequest)(nil), // 4: gomerk.v1.GetMultiProofRequest
+	(*MultiProof)(nil),           // 5: gomerk.v1.MultiProof
+	(*VerifyRequest)(nil),        // 6: gomerk.v1.VerifyRequest
+	(*VerifyResponse)(nil),       // 7: gomerk.v1.VerifyResponse
+}
+var file_proof_proto_depIdxs = []int32{
+	0, // 0: gomerk.v1.ProofService.GetRoot:input_type -> gomerk.v1.GetRootRequest
+	2, // 1: gomerk.v1.ProofService.GetProof:input_type -> gomerk.v1.GetProofRequest
+	4, // 2: gomerk.v1.ProofService.GetMultiProof:input_type -> gomerk.v1.GetMultiProofRequest
+	6, // 3: gomerk.v1.ProofService.Verify:input_type -> gomerk.v1.VerifyRequest
+	1, // 4: gomerk.v1.ProofService.GetRoot:output_type -> gomerk.v1.GetRootResponse
+	3, // 5: gomerk.v1.ProofService.GetProof:output_type -> gomerk.v1.GetProofResponse
+	5, // 6: gomerk.v1.ProofService.GetMultiProof:output_type -> gomerk.v1.MultiProof
+	7, // 7: gomerk.v1.ProofService.Verify:output_type -> gomerk.v1.VerifyResponse
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_proof_proto_init() }
+func file_proof_proto_init() {
+	if File_proof_proto != nil {
+		return
+	}
+	file_proof_proto_msgTypes[2].OneofWrappers = []any{
+		(*GetProofRequest_Index)(nil),
+		(*GetProofRequest_Value)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proof_proto_rawDesc), len(file_proof_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proof_proto_goTypes,
+		DependencyIndexes: file_proof_proto_depIdxs,
+		MessageInfos:      file_proof_proto_msgTypes,
+	}.Build()
+	File_proof_proto = out.File
+	file_proof_proto_goTypes = nil
+	file_proof_proto_depIdxs = nil
+}
@@ -0,0 +1,145 @@
+// Package grpcserver serves proofs from a StandardMerkleTree over gRPC. It is
+// a separate module so that only its users depend on gRPC and protobuf.
+//
+// The generated code is built from proof.proto with protoc-gen-go and
+// protoc-gen-go-grpc (see go:generate below).
+package grpcserver
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proof.proto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pyroth/gomerk"
+)
+
+// Server implements ProofServiceServer for a tree.
+type Server struct {
+	UnimplementedProofServiceServer
+	tree *gomerk.StandardMerkleTree
+}
+
+var _ ProofServiceServer = (*Server)(nil)
+
+// NewServer returns a Server for tree, which must retain its values.
+func NewServer(tree *gomerk.StandardMerkleTree) (*Server, error) {
+	if !tree.HasValues() {
+		return nil, gomerk.ErrValuesNotRetained
+	}
+	return &Server{tree: tree}, nil
+}
+
+func (s *Server) GetRoot(context.Context, *GetRootRequest) (*GetRootResponse, error) {
+	return &GetRootResponse{
+		Root:         s.tree.Root(),
+		LeafEncoding: s.tree.LeafEncoding(),
+		LeafCount:    uint64(s.tree.Len()),
+	}, nil
+}
+
+func (s *Server) GetProof(_ context.Context, req *GetProofRequest) (*GetProofResponse, error) {
+	var i int
+	switch leaf := req.Leaf.(type) {
+	case *GetProofRequest_Index:
+		i = s.index(leaf.Index)
+	case *GetProofRequest_Value:
+		v, err := decodeValue(leaf.Value)
+		if err != nil {
+			return nil, err
+		}
+		if i, err = s.tree.IndexOf(v); err != nil {
+			return nil, statusError(err)
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "missing leaf")
+	}
+	proof, err := s.tree.GetProofByIndex(i)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	v, _ := s.tree.At(i)
+	value, err := encodeValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return &GetProofResponse{Index: uint64(i), Value: value, Proof: proof}, nil
+}
+
+func (s *Server) GetMultiProof(_ context.Context, req *GetMultiProofRequest) (*MultiProof, error) {
+	values := make([][]any, len(req.Indices))
+	for k, idx := range req.Indices {
+		v, ok := s.tree.At(s.index(idx))
+		if !ok {
+			return nil, statusError(gomerk.ErrIndexOutOfBounds)
+		}
+		values[k] = v
+	}
+	mp, err := s.tree.GetMultiProof(values)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	leaves := make([]string, len(mp.Leaves))
+	for k, v := range mp.Leaves {
+		if leaves[k], err = encodeValue(v); err != nil {
+			return nil, err
+		}
+	}
+	return &MultiProof{Leaves: leaves, Proof: mp.Proof, ProofFlags: mp.ProofFlags}, nil
+}
+
+func (s *Server) Verify(_ context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+	v, err := decodeValue(req.Value)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.tree.Verify(v, req.Proof)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &VerifyResponse{Valid: ok}, nil
+}
+
+// index converts a requested index, mapping values beyond int to -1 so they
+// fail the tree's bounds check.
+func (s *Server) index(i uint64) int {
+	if i >= uint64(s.tree.Len()) {
+		return -1
+	}
+	return int(i)
+}
+
+func decodeValue(s string) ([]any, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	var v []any
+	if err := dec.Decode(&v); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "value: %v", err)
+	}
+	return v, nil
+}
+
+func encodeValue(v []any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	return string(b), nil
+}
+
+// statusError maps gomerk errors to gRPC status codes.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, gomerk.ErrLeafNotInTree):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, gomerk.ErrIndexOutOfBounds):
+		return status.Error(codes.OutOfRange, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
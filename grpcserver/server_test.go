@@ -0,0 +1,111 @@
+package grpcserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/grpcserver"
+)
+
+var values = [][]any{
+	{"0x1111111111111111111111111111111111111111", "5000000000000000000"},
+	{"0x2222222222222222222222222222222222222222", "2500000000000000000"},
+	{"0x3333333333333333333333333333333333333333", "1000000000000000000"},
+}
+
+func newClient(t *testing.T) (*gomerk.StandardMerkleTree, grpcserver.ProofServiceClient) {
+	t.Helper()
+	tree, err := gomerk.BuildStandardMerkleTree(values, []string{"address", "uint256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := grpcserver.NewServer(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	grpcserver.RegisterProofServiceServer(gs, srv)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return tree, grpcserver.NewProofServiceClient(conn)
+}
+
+func TestGetRootAndProof(t *testing.T) {
+	tree, c := newClient(t)
+	ctx := context.Background()
+
+	root, err := c.GetRoot(ctx, &grpcserver.GetRootRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Root != tree.Root() || root.LeafCount != 3 {
+		t.Errorf("GetRoot = %v", root)
+	}
+
+	byIndex, err := c.GetProof(ctx, &grpcserver.GetProofRequest{Leaf: &grpcserver.GetProofRequest_Index{Index: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byValue, err := c.GetProof(ctx, &grpcserver.GetProofRequest{Leaf: &grpcserver.GetProofRequest_Value{Value: byIndex.Value}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byValue.Index != 1 {
+		t.Errorf("index = %d, want 1", byValue.Index)
+	}
+
+	res, err := c.Verify(ctx, &grpcserver.VerifyRequest{Value: byValue.Value, Proof: byValue.Proof})
+	if err != nil || !res.Valid {
+		t.Errorf("Verify: got (%v, %v), want valid", res, err)
+	}
+
+	_, err = c.GetProof(ctx, &grpcserver.GetProofRequest{Leaf: &grpcserver.GetProofRequest_Index{Index: 3}})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("got %v, want OutOfRange", err)
+	}
+	_, err = c.GetProof(ctx, &grpcserver.GetProofRequest{Leaf: &grpcserver.GetProofRequest_Value{Value: `["0x4444444444444444444444444444444444444444", "1"]`}})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("got %v, want NotFound", err)
+	}
+	_, err = c.Verify(ctx, &grpcserver.VerifyRequest{Value: "{"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", err)
+	}
+}
+
+func TestGetMultiProof(t *testing.T) {
+	tree, c := newClient(t)
+	mp, err := c.GetMultiProof(context.Background(), &grpcserver.GetMultiProofRequest{Indices: []uint64{2, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := make([][]any, len(mp.Leaves))
+	for i, l := range mp.Leaves {
+		if err := json.Unmarshal([]byte(l), &leaves[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ok, err := tree.VerifyMultiProofValues(&gomerk.StandardMultiProof{Leaves: leaves, Proof: mp.Proof, ProofFlags: mp.ProofFlags})
+	if err != nil || !ok {
+		t.Errorf("multiproof: got (%v, %v), want (true, nil)", ok, err)
+	}
+}
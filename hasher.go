@@ -0,0 +1,167 @@
+package gomerk
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// Hasher computes the leaf and internal node hashes of a tree, calling
+// NodeHash with the left child first. Plain proofs and multiproofs carry no
+// left/right information, so they need a commutative NodeHash, typically
+// hashing ConcatSorted(a, b); with other hashers they fail with
+// ErrPositionalProof and trees are proven with GetProofWithPath. ID names the
+// scheme in serialized trees.
+type Hasher interface {
+	ID() string
+	LeafHash(data []byte) Bytes32
+	NodeHash(a, b Bytes32) Bytes32
+}
+
+// Identifiers of the built-in hashers.
+const (
+	HasherKeccak256 = "keccak256"
+	HasherSHA256    = "sha256"
+
+	HasherKeccak256Positional = "keccak256-positional"
+	HasherSHA256Positional    = "sha256-positional"
+	HasherSHA256RFC6962       = "sha256-rfc6962"
+)
+
+// Keccak256Hasher is the default OpenZeppelin-compatible hasher, using
+// HashLeaf and HashNode.
+var Keccak256Hasher Hasher = keccakHasher{}
+
+// SHA256Hasher mirrors Keccak256Hasher with SHA-256: leaves are hashed twice
+// and nodes hash their sorted concatenation.
+var SHA256Hasher Hasher = sha256Hasher{}
+
+// PositionalKeccak256Hasher hashes leaves like Keccak256Hasher but nodes as
+// keccak256(left || right), without sorting, as zk circuits and most
+// non-OpenZeppelin verifiers expect. Its proofs need the direction bits of
+// GetProofWithPath.
+var PositionalKeccak256Hasher Hasher = positionalKeccakHasher{}
+
+// SHA256PositionalHasher hashes leaves like SHA256Hasher but nodes as
+// sha256(left || right), as the Ethereum deposit contract and SSZ do.
+var SHA256PositionalHasher Hasher = positionalSHA256Hasher{}
+
+// SHA256RFC6962Hasher hashes leaves as sha256(0x00 || data) and nodes as
+// sha256(0x01 || left || right), the domain separation of RFC 6962 and
+// ICS-23. It is positional, so its proofs need the direction bits of
+// GetProofWithPath.
+var SHA256RFC6962Hasher Hasher = rfc6962Hasher{}
+
+type keccakHasher struct{}
+
+func (keccakHasher) ID() string                    { return HasherKeccak256 }
+func (keccakHasher) LeafHash(data []byte) Bytes32  { return HashLeaf(data) }
+func (keccakHasher) NodeHash(a, b Bytes32) Bytes32 { return HashNode(a, b) }
+
+type positionalKeccakHasher struct{}
+
+func (positionalKeccakHasher) ID() string                    { return HasherKeccak256Positional }
+func (positionalKeccakHasher) LeafHash(data []byte) Bytes32  { return HashLeaf(data) }
+func (positionalKeccakHasher) NodeHash(a, b Bytes32) Bytes32 { return keccakPair(a, b) }
+
+type sha256Hasher struct{}
+
+func (sha256Hasher) ID() string { return HasherSHA256 }
+func (sha256Hasher) LeafHash(data []byte) Bytes32 {
+	h := sha256.Sum256(data)
+	return sha256.Sum256(h[:])
+}
+func (sha256Hasher) NodeHash(a, b Bytes32) Bytes32 {
+	if b.Less(a) {
+		a, b = b, a
+	}
+	return positionalSHA256Hasher{}.NodeHash(a, b)
+}
+
+type positionalSHA256Hasher struct{}
+
+func (positionalSHA256Hasher) ID() string                   { return HasherSHA256Positional }
+func (positionalSHA256Hasher) LeafHash(data []byte) Bytes32 { return sha256Hasher{}.LeafHash(data) }
+func (positionalSHA256Hasher) NodeHash(a, b Bytes32) Bytes32 {
+	var buf [64]byte
+	copy(buf[:32], a[:])
+	copy(buf[32:], b[:])
+	return sha256.Sum256(buf[:])
+}
+
+type rfc6962Hasher struct{}
+
+func (rfc6962Hasher) ID() string { return HasherSHA256RFC6962 }
+func (rfc6962Hasher) LeafHash(data []byte) Bytes32 {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(data)
+	return Bytes32(h.Sum(nil))
+}
+func (rfc6962Hasher) NodeHash(a, b Bytes32) Bytes32 {
+	var buf [65]byte
+	buf[0] = 0x01
+	copy(buf[1:33], a[:])
+	copy(buf[33:], b[:])
+	return sha256.Sum256(buf[:])
+}
+
+// commutative reports whether h hashes node pairs regardless of order, as
+// plain proofs require.
+func commutative(h Hasher) bool {
+	switch h.(type) {
+	case keccakHasher, sha256Hasher:
+		return true
+	case positionalKeccakHasher, positionalSHA256Hasher, rfc6962Hasher:
+		return false
+	}
+	a, b := Bytes32{1}, Bytes32{2}
+	return h.NodeHash(a, b) == h.NodeHash(b, a)
+}
+
+var (
+	hashersMu sync.RWMutex
+	hashers   = map[string]Hasher{
+		HasherKeccak256: Keccak256Hasher,
+		HasherSHA256:    SHA256Hasher,
+
+		HasherKeccak256Positional: PositionalKeccak256Hasher,
+		HasherSHA256Positional:    SHA256PositionalHasher,
+		HasherSHA256RFC6962:       SHA256RFC6962Hasher,
+	}
+)
+
+// RegisterHasher makes h available to LoadStandardMerkleTree and
+// LoadSimpleMerkleTree under h.ID(). It returns ErrDuplicatedID if the ID is
+// already taken.
+func RegisterHasher(h Hasher) error {
+	hashersMu.Lock()
+	defer hashersMu.Unlock()
+	if _, ok := hashers[h.ID()]; ok {
+		return ErrDuplicatedID
+	}
+	hashers[h.ID()] = h
+	return nil
+}
+
+// LookupHasher returns the registered hasher with the given ID. The empty ID
+// denotes Keccak256Hasher, as in dumps written before hashers were recorded.
+func LookupHasher(id string) (Hasher, error) {
+	if id == "" {
+		return Keccak256Hasher, nil
+	}
+	hashersMu.RLock()
+	defer hashersMu.RUnlock()
+	h, ok := hashers[id]
+	if !ok {
+		return nil, ErrUnknownHasher
+	}
+	return h, nil
+}
+
+// dumpHasherID returns the ID recorded in dumps, omitting the default.
+func dumpHasherID(h Hasher) string {
+	if h.ID() == HasherKeccak256 {
+		return ""
+	}
+	return h.ID()
+}
@@ -0,0 +1,274 @@
+package gomerk_test
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/ctlog"
+)
+
+type sha512Hasher struct{}
+
+func (sha512Hasher) ID() string { return "test-sha512-256" }
+func (sha512Hasher) LeafHash(data []byte) gomerk.Bytes32 {
+	return sha512.Sum512_256(data)
+}
+func (sha512Hasher) NodeHash(a, b gomerk.Bytes32) gomerk.Bytes32 {
+	return sha512.Sum512_256(gomerk.ConcatSorted(a, b))
+}
+
+func TestHasherSHA256StandardTree(t *testing.T) {
+	vals := airdropData(5)
+	enc := []string{"address", "uint256"}
+	keccak, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithHasher(gomerk.SHA256Hasher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Root() == keccak.Root() {
+		t.Error("SHA-256 root should differ from Keccak root")
+	}
+	if err := tree.SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	proof, _ := tree.GetProof(vals[1])
+	if ok, _ := gomerk.VerifyStandard(tree.Root(), enc, vals[1], proof); ok {
+		t.Error("Keccak static verifier should reject a SHA-256 proof")
+	}
+	v := gomerk.NewVerifier(gomerk.WithHasher(gomerk.SHA256Hasher))
+	if ok, err := v.VerifyStandard(tree.Root(), enc, vals[1], proof); err != nil || !ok {
+		t.Errorf("SHA-256 verifier: got (%v, %v), want (true, nil)", ok, err)
+	}
+	if ok, err := gomerk.NewVerifier().VerifyStandardTree(tree, vals[1], proof); err != nil || !ok {
+		t.Errorf("tree verifier: got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	mp, _ := tree.GetMultiProofByIndices([]int{0, 3})
+	if ok, _ := tree.VerifyMultiProof(mp); !ok {
+		t.Error("multiproof verify failed")
+	}
+
+	raw, _ := json.Marshal(tree.Dump())
+	var data gomerk.StandardTreeData
+	json.Unmarshal(raw, &data)
+	if data.Hasher != gomerk.HasherSHA256 {
+		t.Errorf("dump hasher = %q, want %q", data.Hasher, gomerk.HasherSHA256)
+	}
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := loaded.Verify(vals[1], proof); !ok {
+		t.Error("loaded tree lost its hasher")
+	}
+	if keccak.Dump().Hasher != "" {
+		t.Error("Keccak dumps should omit the hasher")
+	}
+
+	data.Hasher = "unknown"
+	if _, err := gomerk.LoadStandardMerkleTree(data); err != gomerk.ErrUnknownHasher {
+		t.Errorf("got %v, want ErrUnknownHasher", err)
+	}
+}
+
+func TestHasherRegisteredSimpleTree(t *testing.T) {
+	h := sha512Hasher{}
+	if err := gomerk.RegisterHasher(h); err != nil && !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Fatal(err)
+	}
+	if err := gomerk.RegisterHasher(h); err != gomerk.ErrDuplicatedID {
+		t.Errorf("got %v, want ErrDuplicatedID", err)
+	}
+	if got, err := gomerk.LookupHasher(h.ID()); err != nil || got != h {
+		t.Errorf("LookupHasher = (%v, %v)", got, err)
+	}
+
+	leaves := simpleLeaves(6)
+	tree, err := gomerk.NewSimpleMerkleTree(leaves, true, gomerk.WithHasher(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := gomerk.LoadSimpleMerkleTree(tree.Dump())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Root() != tree.Root() {
+		t.Error("root changed after load")
+	}
+	for i, leaf := range leaves {
+		proof, _ := loaded.GetProofByIndex(i)
+		if ok, _ := loaded.Verify(leaf, proof); !ok {
+			t.Errorf("leaf %d: verify failed", i)
+		}
+		if ok, _ := gomerk.VerifySimple(loaded.Root(), leaf, proof); ok {
+			t.Errorf("leaf %d: Keccak verifier accepted proof", i)
+		}
+	}
+	mp, _ := loaded.GetMultiProofByIndices([]int{1, 4})
+	if ok, _ := loaded.VerifyMultiProof(mp); !ok {
+		t.Error("multiproof verify failed")
+	}
+}
+
+func TestKeccak256HasherMatchesDefault(t *testing.T) {
+	h := gomerk.Keccak256Hasher
+	a, b := simpleLeaves(2)[0], simpleLeaves(2)[1]
+	if h.LeafHash(a[:]) != gomerk.HashLeaf(a[:]) || h.NodeHash(a, b) != gomerk.HashNode(a, b) {
+		t.Error("Keccak256Hasher should match HashLeaf and HashNode")
+	}
+	if got, _ := gomerk.LookupHasher(""); got != h {
+		t.Error("empty ID should resolve to Keccak256Hasher")
+	}
+}
+
+func TestPositionalHashing(t *testing.T) {
+	vals := airdropData(5)
+	enc := []string{"address", "uint256"}
+	tree, err := gomerk.BuildStandardMerkleTree(vals, enc, gomerk.WithPositionalHashing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, _ := gomerk.BuildStandardMerkleTree(vals, enc)
+	if tree.Root() == sorted.Root() {
+		t.Error("positional root should differ from sorted-pair root")
+	}
+	if err := tree.SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	rejected := false
+	for i := range tree.Len() {
+		proof, path, err := tree.GetProofWithPath(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, _ := tree.LeafHash(vals[i])
+		if ok, err := gomerk.VerifyWithPath(tree.Root(), leaf, proof, path); err != nil || !ok {
+			t.Errorf("value %d: got (%v, %v), want (true, nil)", i, ok, err)
+		}
+		flipped := slices.Clone(path)
+		flipped[0] = !flipped[0]
+		if ok, _ := gomerk.VerifyWithPath(tree.Root(), leaf, proof, flipped); ok {
+			t.Errorf("value %d: proof with a flipped direction bit verified", i)
+		}
+		if ok, _ := gomerk.Verify(tree.Root(), leaf, proof); !ok {
+			rejected = true
+		}
+	}
+	if !rejected {
+		t.Error("plain verification should fail for some positional proofs")
+	}
+
+	// The tree knows each value's position, so its own plain proofs verify.
+	for i, val := range vals {
+		proof, _ := tree.GetProof(val)
+		if ok, err := tree.Verify(val, proof); err != nil || !ok {
+			t.Errorf("value %d: Verify = (%v, %v), want (true, nil)", i, ok, err)
+		}
+		nodes, _ := tree.GetProofBytes(i)
+		if ok, err := tree.VerifyParsed(val, nodes); err != nil || !ok {
+			t.Errorf("value %d: VerifyParsed = (%v, %v), want (true, nil)", i, ok, err)
+		}
+		other, _ := tree.GetProof(vals[(i+1)%len(vals)])
+		if ok, _ := tree.Verify(val, other); ok {
+			t.Errorf("value %d: another value's proof verified", i)
+		}
+	}
+	if _, err := tree.GetMultiProof(vals[:2]); !errors.Is(err, gomerk.ErrPositionalProof) {
+		t.Errorf("GetMultiProof: got %v, want ErrPositionalProof", err)
+	}
+	if _, err := tree.GetMultiProofByIndices([]int{0, 1}); !errors.Is(err, gomerk.ErrPositionalProof) {
+		t.Errorf("GetMultiProofByIndices: got %v, want ErrPositionalProof", err)
+	}
+	if _, err := tree.ExportProofs(); !errors.Is(err, gomerk.ErrPositionalProof) {
+		t.Errorf("ExportProofs: got %v, want ErrPositionalProof", err)
+	}
+	proof0, _ := tree.GetProof(vals[0])
+	if _, err := gomerk.NewVerifier(gomerk.WithPositionalHashing()).VerifyStandard(tree.Root(), enc, vals[0], proof0); !errors.Is(err, gomerk.ErrPositionalProof) {
+		t.Errorf("Verifier.VerifyStandard: got %v, want ErrPositionalProof", err)
+	}
+
+	proof, path, _ := tree.GetProofWithPath(0)
+	if _, err := gomerk.ProcessProofWithPath(gomerk.Bytes32{}, proof, path[1:]); !errors.Is(err, gomerk.ErrInvalidProof) {
+		t.Errorf("short path: got %v, want ErrInvalidProof", err)
+	}
+
+	raw, _ := json.Marshal(tree.Dump())
+	var data gomerk.StandardTreeData
+	json.Unmarshal(raw, &data)
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Root() != tree.Root() {
+		t.Errorf("loaded root = %s, want %s", loaded.Root(), tree.Root())
+	}
+}
+
+func TestSHA256RFC6962Hasher(t *testing.T) {
+	h := gomerk.SHA256RFC6962Hasher
+	a, b := simpleLeaves(2)[0], simpleLeaves(2)[1]
+	if h.LeafHash(a[:]) != ctlog.LeafHash(a[:]) || h.NodeHash(a, b) != ctlog.NodeHash(a, b) {
+		t.Error("SHA256RFC6962Hasher should match the RFC 6962 hashes of ctlog")
+	}
+
+	vals := airdropData(5)
+	tree, err := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true, gomerk.WithHasher(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.GetMultiProofByIndices([]int{0, 1}); !errors.Is(err, gomerk.ErrPositionalProof) {
+		t.Errorf("GetMultiProofByIndices: got %v, want ErrPositionalProof", err)
+	}
+	raw, _ := json.Marshal(tree.Dump())
+	var data gomerk.StandardTreeData
+	json.Unmarshal(raw, &data)
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Hasher() != h || loaded.Root() != tree.Root() {
+		t.Errorf("loaded %s tree with root %s, want %s", loaded.Hasher().ID(), loaded.Root(), tree.Root())
+	}
+}
+
+func TestVerifierWithPath(t *testing.T) {
+	leaves := []gomerk.Bytes32{{1}, {2}, {3}}
+	tree, err := gomerk.BuildSimpleMerkleTree(leaves, gomerk.WithPositionalHashing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, path, err := tree.GetProofWithPath(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range leaves {
+		p, _ := tree.GetProof(l)
+		if ok, err := tree.Verify(l, p); err != nil || !ok {
+			t.Errorf("Verify(%s) = (%v, %v), want (true, nil)", l, ok, err)
+		}
+	}
+	if _, err := tree.GetMultiProof(leaves[:2]); !errors.Is(err, gomerk.ErrPositionalProof) {
+		t.Errorf("GetMultiProof: got %v, want ErrPositionalProof", err)
+	}
+	leaf := gomerk.HashLeaf(leaves[2][:])
+	v := gomerk.NewVerifier(gomerk.WithPositionalHashing())
+	if ok, err := v.VerifyWithPath(tree.Root(), leaf, proof, path); err != nil || !ok {
+		t.Errorf("got (%v, %v), want (true, nil)", ok, err)
+	}
+	v = gomerk.NewVerifier(gomerk.WithPositionalHashing(), gomerk.WithMaxProofLength(len(proof)-1))
+	if _, err := v.VerifyWithPath(tree.Root(), leaf, proof, path); !errors.Is(err, gomerk.ErrProofTooLong) {
+		t.Errorf("got %v, want ErrProofTooLong", err)
+	}
+	if _, _, err := tree.GetProofWithPath(3); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+}
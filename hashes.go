@@ -1,13 +1,80 @@
 package gomerk
 
-import "golang.org/x/crypto/sha3"
+import (
+	"hash"
+	"sync"
 
-func Keccak256(data []byte) (h Bytes32) {
-	d := sha3.NewLegacyKeccak256()
-	d.Write(data)
-	d.Sum(h[:0])
-	return
+	"golang.org/x/crypto/sha3"
+)
+
+// keccakScratch is a reusable Keccak-256 state with buffers for node inputs
+// and digests, kept in keccakPool so that hashing does not allocate.
+type keccakScratch struct {
+	state hash.Hash
+	// read writes the digest into out.
+	read func(out []byte)
+	buf  [64]byte
+	out  Bytes32
+}
+
+var keccakPool = sync.Pool{New: func() any {
+	s := &keccakScratch{state: sha3.NewLegacyKeccak256()}
+	// sha3's state also has a Read method that returns the digest without
+	// copying the state, as Sum does. It is not part of the hash.Hash
+	// contract, so Sum is used if it goes away.
+	if r, ok := s.state.(interface{ Read([]byte) (int, error) }); ok {
+		s.read = func(out []byte) { r.Read(out) }
+	} else {
+		s.read = func(out []byte) { s.state.Sum(out[:0]) }
+	}
+	return s
+}}
+
+// sum hashes data, which may be s.buf or s.out, into s.out.
+func (s *keccakScratch) sum(data []byte) {
+	s.state.Reset()
+	s.state.Write(data)
+	s.read(s.out[:])
 }
 
-func HashLeaf(data []byte) Bytes32  { h := Keccak256(data); return Keccak256(h[:]) }
-func HashNode(a, b Bytes32) Bytes32 { return Keccak256(ConcatSorted(a, b)) }
+func Keccak256(data []byte) Bytes32 {
+	s := keccakPool.Get().(*keccakScratch)
+	s.sum(data)
+	h := s.out
+	keccakPool.Put(s)
+	return h
+}
+
+func HashLeaf(data []byte) Bytes32 {
+	s := keccakPool.Get().(*keccakScratch)
+	s.sum(data)
+	s.sum(s.out[:])
+	h := s.out
+	keccakPool.Put(s)
+	return h
+}
+
+func HashNode(a, b Bytes32) Bytes32 {
+	if b.Less(a) {
+		a, b = b, a
+	}
+	return keccakPair(a, b)
+}
+
+// keccakPair returns keccak256(a || b).
+func keccakPair(a, b Bytes32) Bytes32 {
+	s := keccakPool.Get().(*keccakScratch)
+	copy(s.buf[:32], a[:])
+	copy(s.buf[32:], b[:])
+	s.sum(s.buf[:])
+	h := s.out
+	keccakPool.Put(s)
+	return h
+}
+
+// Selector returns the 4-byte function selector of a Solidity signature such
+// as "transfer(address,uint256)": the first bytes of its Keccak256 hash.
+func Selector(signature string) [4]byte {
+	h := Keccak256([]byte(signature))
+	return [4]byte(h[:4])
+}
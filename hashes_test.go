@@ -63,3 +63,34 @@ func TestHashNodeDifferentInputs(t *testing.T) {
 		t.Error("different inputs should produce different hashes")
 	}
 }
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want [4]byte
+	}{
+		{"transfer(address,uint256)", [4]byte{0xa9, 0x05, 0x9c, 0xbb}},
+		{"balanceOf(address)", [4]byte{0x70, 0xa0, 0x82, 0x31}},
+	}
+	for _, tc := range tests {
+		if got := gomerk.Selector(tc.sig); got != tc.want {
+			t.Errorf("Selector(%q) = %x, want %x", tc.sig, got, tc.want)
+		}
+	}
+}
+
+func BenchmarkKeccak256(b *testing.B) {
+	data := make([]byte, 64)
+	b.ReportAllocs()
+	for b.Loop() {
+		gomerk.Keccak256(data)
+	}
+}
+
+func BenchmarkHashNode(b *testing.B) {
+	x, y := gomerk.Keccak256([]byte{1}), gomerk.Keccak256([]byte{2})
+	b.ReportAllocs()
+	for b.Loop() {
+		gomerk.HashNode(x, y)
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/pyroth/gomerk/ics23proof
+
+go 1.25.5
+
+require (
+	github.com/cosmos/ics23/go v0.11.0
+	github.com/pyroth/gomerk v0.0.0
+)
+
+require (
+	github.com/cosmos/gogoproto v1.7.0 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	golang.org/x/crypto v0.46.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+replace github.com/pyroth/gomerk => ../
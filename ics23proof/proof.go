@@ -0,0 +1,280 @@
+// Package ics23proof exports gomerk proofs in the ICS-23 ExistenceProof
+// format, the proof encoding of IBC. It is a separate module so that only its
+// users depend on the ics23 module.
+//
+// An ExistenceProof hashes a key and value into a leaf and then applies one
+// InnerOp per level. The key and value are any split of the leaf's data, such
+// as the ABI encoding of a standard tree value.
+//
+// Trees hashed with gomerk.SHA256RFC6962Hasher give ICS-23 compliant proofs:
+// the LeafOp prefixes key||value with 0x00, and each InnerOp prefixes the
+// children with 0x01 and places the sibling as the prefix or suffix by its
+// position. Spec returns a ProofSpec that ics23.VerifyMembership and IBC light
+// clients check these proofs against.
+//
+// Trees hashed with gomerk.Keccak256Hasher or gomerk.SHA256Hasher can be
+// exported too, but only as a format. Their leaves hash their data twice and
+// nodes hash their sorted children, so a converted proof hashes key||value
+// with its LeafOp, hashes the result again with a bare InnerOp, and places
+// each sibling by its sort order. ics23's ProofSpec check requires inner op
+// prefixes to differ from the leaf prefix, which these unprefixed leaves
+// cannot satisfy, so ics23.VerifyMembership rejects the proofs. Use Verify
+// here, which checks the ops against this layout instead. ics23's Go library
+// does not implement KECCAK256, so ExistenceProof.Calculate only works for
+// SHA-256 trees; verifiers in other languages can apply the ops directly.
+package ics23proof
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+
+	ics23 "github.com/cosmos/ics23/go"
+
+	"github.com/pyroth/gomerk"
+)
+
+var (
+	ErrUnsupportedHasher = errors.New("ics23proof: unsupported hasher")
+	ErrInvalidProof      = errors.New("ics23proof: invalid proof")
+)
+
+// scheme describes how the proofs of a hasher map onto ics23 ops.
+type scheme struct {
+	op ics23.HashOp
+	h  gomerk.Hasher
+	// prefixed schemes separate leaves and nodes with the 0x00 and 0x01
+	// prefixes and order children by position, as ICS-23 requires.
+	prefixed bool
+}
+
+// lookup returns the scheme of hasher ID id.
+func lookup(id string) (scheme, error) {
+	switch id {
+	case gomerk.HasherKeccak256:
+		return scheme{op: ics23.HashOp_KECCAK256, h: gomerk.Keccak256Hasher}, nil
+	case gomerk.HasherSHA256:
+		return scheme{op: ics23.HashOp_SHA256, h: gomerk.SHA256Hasher}, nil
+	case gomerk.HasherSHA256RFC6962:
+		return scheme{op: ics23.HashOp_SHA256, h: gomerk.SHA256RFC6962Hasher, prefixed: true}, nil
+	}
+	return scheme{}, fmt.Errorf("%w: %s", ErrUnsupportedHasher, id)
+}
+
+// Spec returns the ProofSpec for the hasher with ID id. For
+// gomerk.HasherSHA256RFC6962 it is the spec that ics23 verifies proofs
+// against; for gomerk.HasherKeccak256 and gomerk.HasherSHA256 it documents
+// the export layout, which ics23 cannot check (see the package comment).
+func Spec(id string) (*ics23.ProofSpec, error) {
+	s, err := lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	spec := &ics23.ProofSpec{
+		LeafSpec: s.leafOp(),
+		InnerSpec: &ics23.InnerSpec{
+			ChildOrder: []int32{0, 1},
+			ChildSize:  32,
+			Hash:       s.op,
+		},
+	}
+	if s.prefixed {
+		spec.InnerSpec.MinPrefixLength = 1
+		spec.InnerSpec.MaxPrefixLength = 1
+	}
+	return spec, nil
+}
+
+func (s scheme) leafOp() *ics23.LeafOp {
+	op := &ics23.LeafOp{
+		Hash:         s.op,
+		PrehashKey:   ics23.HashOp_NO_HASH,
+		PrehashValue: ics23.HashOp_NO_HASH,
+		Length:       ics23.LengthOp_NO_PREFIX,
+	}
+	if s.prefixed {
+		op.Prefix = []byte{0x00}
+	}
+	return op
+}
+
+// FromProof converts proof, a gomerk proof of the leaf whose data is key
+// followed by value, for a tree hashed with the hasher with ID id. Both key
+// and value must be non-empty. Proofs of gomerk.HasherSHA256RFC6962 trees
+// carry no directions and fail with gomerk.ErrPositionalProof; convert them
+// with FromProofWithPath.
+func FromProof(id string, key, value []byte, proof []string) (*ics23.ExistenceProof, error) {
+	s, err := lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.prefixed {
+		return nil, gomerk.ErrPositionalProof
+	}
+	return s.convert(key, value, proof, nil)
+}
+
+// FromProofWithPath is like FromProof for a proof and direction bits from
+// GetProofWithPath. Only gomerk.HasherSHA256RFC6962 trees use the bits; for
+// the sorted hashers they are ignored.
+func FromProofWithPath(id string, key, value []byte, proof []string, path []bool) (*ics23.ExistenceProof, error) {
+	s, err := lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.prefixed && len(path) != len(proof) {
+		return nil, fmt.Errorf("%w: %d directions for %d siblings", ErrInvalidProof, len(path), len(proof))
+	}
+	return s.convert(key, value, proof, path)
+}
+
+func (s scheme) convert(key, value []byte, proof []string, path []bool) (*ics23.ExistenceProof, error) {
+	if len(key) == 0 || len(value) == 0 {
+		return nil, fmt.Errorf("%w: empty key or value", ErrInvalidProof)
+	}
+	p := &ics23.ExistenceProof{
+		Key:   bytes.Clone(key),
+		Value: bytes.Clone(value),
+		Leaf:  s.leafOp(),
+	}
+	if !s.prefixed {
+		p.Path = []*ics23.InnerOp{{Hash: s.op}}
+	}
+	node := s.h.LeafHash(append(bytes.Clone(key), value...))
+	for i, str := range proof {
+		sib, err := gomerk.HexToBytes32(str)
+		if err != nil {
+			return nil, err
+		}
+		inner := &ics23.InnerOp{Hash: s.op}
+		switch {
+		case s.prefixed && path[i]:
+			inner.Prefix = append([]byte{0x01}, sib[:]...)
+			node = s.h.NodeHash(sib, node)
+		case s.prefixed:
+			inner.Prefix = []byte{0x01}
+			inner.Suffix = sib[:]
+			node = s.h.NodeHash(node, sib)
+		case sib.Less(node):
+			inner.Prefix = sib[:]
+			node = s.h.NodeHash(node, sib)
+		default:
+			inner.Suffix = sib[:]
+			node = s.h.NodeHash(node, sib)
+		}
+		p.Path = append(p.Path, inner)
+	}
+	return p, nil
+}
+
+// FromStandard converts the proof of the value at index i of t, with the
+// first keyLen bytes of the value's ABI encoding as the key and the rest as
+// the value. The tree's leaves must be hashed from that encoding, so EIP-712
+// trees are not supported.
+func FromStandard(t *gomerk.StandardMerkleTree, i, keyLen int) (*ics23.ExistenceProof, error) {
+	data, err := t.LeafPreimage(i)
+	if err != nil {
+		return nil, err
+	}
+	proof, path, err := t.GetProofWithPath(i)
+	if err != nil {
+		return nil, err
+	}
+	return fromTree(t.Hasher().ID(), t.Root(), data, keyLen, proof, path)
+}
+
+// FromSimple converts the proof of the value at index i of t, with the
+// first keyLen bytes of the value as the key and the rest as the value.
+func FromSimple(t *gomerk.SimpleMerkleTree, i, keyLen int) (*ics23.ExistenceProof, error) {
+	v, ok := t.At(i)
+	if !ok {
+		return nil, gomerk.ErrIndexOutOfBounds
+	}
+	data, err := gomerk.HexToBytes32(v)
+	if err != nil {
+		return nil, err
+	}
+	proof, path, err := t.GetProofWithPath(i)
+	if err != nil {
+		return nil, err
+	}
+	return fromTree(t.Hasher().ID(), t.Root(), data[:], keyLen, proof, path)
+}
+
+// fromTree converts a tree proof and checks that it reaches root.
+func fromTree(id, root string, data []byte, keyLen int, proof []string, path []bool) (*ics23.ExistenceProof, error) {
+	if keyLen <= 0 || keyLen >= len(data) {
+		return nil, fmt.Errorf("%w: key length %d of %d bytes", ErrInvalidProof, keyLen, len(data))
+	}
+	p, err := FromProofWithPath(id, data[:keyLen], data[keyLen:], proof, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(id, root, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Verify checks that p has the layout of a converted proof for the hasher
+// with ID id and that it reaches root. Proofs of gomerk.HasherSHA256RFC6962
+// trees are checked by ics23 against Spec(id).
+func Verify(id, root string, p *ics23.ExistenceProof) error {
+	s, err := lookup(id)
+	if err != nil {
+		return err
+	}
+	want, err := gomerk.HexToBytes32(root)
+	if err != nil {
+		return err
+	}
+	if len(p.Key) == 0 || len(p.Value) == 0 {
+		return fmt.Errorf("%w: empty key or value", ErrInvalidProof)
+	}
+	if s.prefixed {
+		spec, _ := Spec(id)
+		if err := p.CheckAgainstSpec(spec); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+		got, err := p.Calculate()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+		if !bytes.Equal(got, want[:]) {
+			return gomerk.ErrRootMismatch
+		}
+		return nil
+	}
+	leaf := s.leafOp()
+	if p.Leaf == nil || p.Leaf.Hash != leaf.Hash || p.Leaf.PrehashKey != leaf.PrehashKey ||
+		p.Leaf.PrehashValue != leaf.PrehashValue || p.Leaf.Length != leaf.Length || len(p.Leaf.Prefix) != 0 {
+		return fmt.Errorf("%w: leaf op", ErrInvalidProof)
+	}
+	if len(p.Path) == 0 {
+		return fmt.Errorf("%w: missing leaf rehash", ErrInvalidProof)
+	}
+	for i, inner := range p.Path {
+		pre, suf := len(inner.Prefix), len(inner.Suffix)
+		ok := pre == 0 && suf == 0
+		if i > 0 {
+			ok = pre+suf == 32 && (pre == 0 || suf == 0)
+		}
+		if inner.Hash != s.op || !ok {
+			return fmt.Errorf("%w: inner op %d", ErrInvalidProof, i)
+		}
+	}
+	hash := gomerk.Keccak256
+	if s.op == ics23.HashOp_SHA256 {
+		hash = func(b []byte) gomerk.Bytes32 { return sha256.Sum256(b) }
+	}
+	got := hash(append(bytes.Clone(p.Key), p.Value...))
+	for _, inner := range p.Path {
+		got = hash(slices.Concat(inner.Prefix, got[:], inner.Suffix))
+	}
+	if got != want {
+		return gomerk.ErrRootMismatch
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package ics23proof_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	ics23 "github.com/cosmos/ics23/go"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/ics23proof"
+)
+
+func airdrop(n int) [][]any {
+	values := make([][]any, n)
+	for i := range values {
+		values[i] = []any{fmt.Sprintf("0x%040x", i+1), fmt.Sprint((i + 1) * 1000)}
+	}
+	return values
+}
+
+func TestFromStandard(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	for _, h := range []gomerk.Hasher{gomerk.Keccak256Hasher, gomerk.SHA256Hasher} {
+		tree, _ := gomerk.NewStandardMerkleTree(airdrop(9), enc, true, gomerk.WithHasher(h))
+		root, _ := gomerk.HexToBytes32(tree.Root())
+		for i := range tree.Len() {
+			p, err := ics23proof.FromStandard(tree, i, 32)
+			if err != nil {
+				t.Fatalf("%s: value %d: %v", h.ID(), i, err)
+			}
+			data, _ := tree.LeafPreimage(i)
+			if !bytes.Equal(p.Key, data[:32]) || !bytes.Equal(p.Value, data[32:]) {
+				t.Errorf("%s: value %d: key and value do not split the encoding", h.ID(), i)
+			}
+			if h == gomerk.SHA256Hasher {
+				got, err := p.Calculate()
+				if err != nil || !bytes.Equal(got, root[:]) {
+					t.Errorf("%s: value %d: Calculate = %x, %v", h.ID(), i, got, err)
+				}
+			}
+			if err := ics23proof.Verify(h.ID(), tree.Root(), p); err != nil {
+				t.Errorf("%s: value %d: %v", h.ID(), i, err)
+			}
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdrop(5), []string{"address", "uint256"}, true, gomerk.WithHasher(gomerk.SHA256Hasher))
+	p, err := ics23proof.FromStandard(tree, 2, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := *p
+	tampered.Value = bytes.Clone(p.Value)
+	tampered.Value[31]++
+	if err := ics23proof.Verify(gomerk.HasherSHA256, tree.Root(), &tampered); !errors.Is(err, gomerk.ErrRootMismatch) {
+		t.Errorf("tampered value: err = %v", err)
+	}
+	// Dropping the leaf rehash lets an internal node pose as a leaf.
+	short := *p
+	short.Path = p.Path[1:]
+	if err := ics23proof.Verify(gomerk.HasherSHA256, tree.Root(), &short); !errors.Is(err, ics23proof.ErrInvalidProof) {
+		t.Errorf("missing rehash: err = %v", err)
+	}
+	if err := ics23proof.Verify(gomerk.HasherKeccak256, tree.Root(), p); !errors.Is(err, ics23proof.ErrInvalidProof) {
+		t.Errorf("wrong hasher: err = %v", err)
+	}
+	if _, err := ics23proof.Spec(gomerk.HasherKeccak256Positional); !errors.Is(err, ics23proof.ErrUnsupportedHasher) {
+		t.Errorf("positional spec: err = %v", err)
+	}
+	if _, err := ics23proof.FromStandard(tree, 0, 64); !errors.Is(err, ics23proof.ErrInvalidProof) {
+		t.Errorf("key covering the whole encoding: err = %v", err)
+	}
+
+	// As documented, ics23's own spec check refuses the unprefixed leaves.
+	spec, _ := ics23proof.Spec(gomerk.HasherSHA256)
+	root, _ := gomerk.HexToBytes32(tree.Root())
+	cp := &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: p}}
+	if ics23.VerifyMembership(spec, root[:], cp, p.Key, p.Value) {
+		t.Error("ics23 accepted a proof against an unprefixed leaf spec")
+	}
+}
+
+func TestRFC6962Compliant(t *testing.T) {
+	h := gomerk.SHA256RFC6962Hasher
+	spec, err := ics23proof.Spec(h.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(name, rootHex string, p *ics23.ExistenceProof) {
+		t.Helper()
+		root, _ := gomerk.HexToBytes32(rootHex)
+		cp := &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: p}}
+		if !ics23.VerifyMembership(spec, root[:], cp, p.Key, p.Value) {
+			t.Errorf("%s: ics23 rejected the proof", name)
+		}
+		if err := ics23proof.Verify(h.ID(), rootHex, p); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	standard, _ := gomerk.NewStandardMerkleTree(airdrop(7), []string{"address", "uint256"}, false, gomerk.WithHasher(h))
+	for i := range standard.Len() {
+		p, err := ics23proof.FromStandard(standard, i, 32)
+		if err != nil {
+			t.Fatalf("standard value %d: %v", i, err)
+		}
+		check(fmt.Sprintf("standard value %d", i), standard.Root(), p)
+	}
+	leaves := make([]gomerk.Bytes32, 5)
+	for i := range leaves {
+		leaves[i] = gomerk.Keccak256([]byte{byte(i)})
+	}
+	simple, _ := gomerk.NewSimpleMerkleTree(leaves, false, gomerk.WithHasher(h))
+	for i := range simple.Len() {
+		p, err := ics23proof.FromSimple(simple, i, 8)
+		if err != nil {
+			t.Fatalf("simple value %d: %v", i, err)
+		}
+		check(fmt.Sprintf("simple value %d", i), simple.Root(), p)
+	}
+
+	p, _ := ics23proof.FromStandard(standard, 3, 32)
+	tampered := *p
+	tampered.Value = bytes.Clone(p.Value)
+	tampered.Value[31]++
+	if err := ics23proof.Verify(h.ID(), standard.Root(), &tampered); !errors.Is(err, gomerk.ErrRootMismatch) {
+		t.Errorf("tampered value: err = %v", err)
+	}
+	// Without the leaf prefix, an internal node could pose as a leaf.
+	unprefixed := *p
+	unprefixed.Leaf = &ics23.LeafOp{Hash: p.Leaf.Hash, Length: p.Leaf.Length}
+	if err := ics23proof.Verify(h.ID(), standard.Root(), &unprefixed); !errors.Is(err, ics23proof.ErrInvalidProof) {
+		t.Errorf("unprefixed leaf: err = %v", err)
+	}
+	data, _ := standard.LeafPreimage(3)
+	proof, _ := standard.GetProofByIndex(3)
+	if _, err := ics23proof.FromProof(h.ID(), data[:32], data[32:], proof); !errors.Is(err, gomerk.ErrPositionalProof) {
+		t.Errorf("proof without directions: err = %v", err)
+	}
+}
+
+func TestFromSimple(t *testing.T) {
+	leaves := make([]gomerk.Bytes32, 6)
+	for i := range leaves {
+		leaves[i] = gomerk.Keccak256([]byte{byte(i)})
+	}
+	tree, _ := gomerk.NewSimpleMerkleTree(leaves, true)
+	for i := range tree.Len() {
+		p, err := ics23proof.FromSimple(tree, i, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ics23proof.Verify(gomerk.HasherKeccak256, tree.Root(), p); err != nil {
+			t.Errorf("value %d: %v", i, err)
+		}
+	}
+	if _, err := ics23proof.FromSimple(tree, 6, 1); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("out of range: err = %v", err)
+	}
+}
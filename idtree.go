@@ -0,0 +1,147 @@
+package gomerk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IDMerkleTree is a StandardMerkleTree whose leaves carry a uint id field,
+// allowing proofs to be looked up by id for sparse id sets. Rebuild, Append
+// and UpdateLeafByIndex keep the id index in step with the tree; changes made
+// through the underlying tree's own methods are not seen by ProofForID.
+type IDMerkleTree struct {
+	*StandardMerkleTree
+	idField int
+	ids     map[uint64]int
+}
+
+// NewIDMerkleTree builds a StandardMerkleTree and indexes it by the uint
+// field at idField.
+func NewIDMerkleTree(values [][]any, leafEncoding []string, idField int, sortLeaves bool) (*IDMerkleTree, error) {
+	t, err := NewStandardMerkleTree(values, leafEncoding, sortLeaves)
+	if err != nil {
+		return nil, err
+	}
+	return IDMerkleTreeFrom(t, idField)
+}
+
+// IDMerkleTreeFrom indexes an existing tree by the uint field at idField.
+func IDMerkleTreeFrom(t *StandardMerkleTree, idField int) (*IDMerkleTree, error) {
+	if !t.HasValues() {
+		return nil, ErrValuesNotRetained
+	}
+	if idField < 0 || idField >= len(t.leafEncoding) {
+		return nil, ErrIndexOutOfBounds
+	}
+	if typ := t.leafEncoding[idField]; !strings.HasPrefix(typ, "uint") {
+		return nil, fmt.Errorf("%w: id field must be uint, got %s", ErrUnsupportedType, typ)
+	}
+	ids, err := indexIDs(t, idField)
+	if err != nil {
+		return nil, err
+	}
+	return &IDMerkleTree{StandardMerkleTree: t, idField: idField, ids: ids}, nil
+}
+
+// indexIDs maps the id of each value of t to its index.
+func indexIDs(t *StandardMerkleTree, idField int) (map[uint64]int, error) {
+	ids := make(map[uint64]int, len(t.values))
+	for i, v := range t.values {
+		id, err := valueID(t, v.Value, idField)
+		if err != nil {
+			return nil, withValueIndex(err, i)
+		}
+		if _, ok := ids[id]; ok {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicatedID, id)
+		}
+		ids[id] = i
+	}
+	return ids, nil
+}
+
+// valueID returns the id field of value.
+func valueID(t *StandardMerkleTree, value []any, idField int) (uint64, error) {
+	if idField >= len(value) {
+		return 0, ErrMismatchedCount
+	}
+	n, err := toBigInt(value[idField])
+	if err == nil && !n.IsUint64() {
+		err = ErrAbiEncode
+	}
+	if err != nil {
+		return 0, &EncodeError{Index: -1, Field: idField, Type: t.leafEncoding[idField], Err: err}
+	}
+	return n.Uint64(), nil
+}
+
+// IDField returns the index of the id field within each value.
+func (t *IDMerkleTree) IDField() int { return t.idField }
+
+// ProofForID returns the proof and value of the leaf with the given id.
+func (t *IDMerkleTree) ProofForID(id uint64) ([]string, []any, error) {
+	i, ok := t.ids[id]
+	if !ok {
+		return nil, nil, ErrLeafNotInTree
+	}
+	proof, err := t.GetProofByIndex(i)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, t.values[i].Value, nil
+}
+
+// Rebuild is StandardMerkleTree.Rebuild, reindexing the ids. It fails with
+// ErrDuplicatedID if two values share an id, leaving the tree unchanged.
+func (t *IDMerkleTree) Rebuild(values [][]any) error {
+	return t.change(func(c *StandardMerkleTree) error { return c.Rebuild(values) })
+}
+
+// Append is StandardMerkleTree.Append, indexing the new ids. It fails with
+// ErrDuplicatedID if a new value's id is already taken, leaving the tree
+// unchanged.
+func (t *IDMerkleTree) Append(values ...[]any) error {
+	return t.change(func(c *StandardMerkleTree) error { return c.Append(values...) })
+}
+
+// change applies f to a copy of the tree and keeps the result only if its
+// ids are still unique.
+func (t *IDMerkleTree) change(f func(*StandardMerkleTree) error) error {
+	c := t.StandardMerkleTree.Clone()
+	if err := f(c); err != nil {
+		return err
+	}
+	ids, err := indexIDs(c, t.idField)
+	if err != nil {
+		return err
+	}
+	*t.StandardMerkleTree = *c
+	t.ids = ids
+	return nil
+}
+
+// UpdateLeafByIndex is StandardMerkleTree.UpdateLeafByIndex, moving the id
+// index to the new value's id. It fails with ErrDuplicatedID if another
+// value has that id.
+func (t *IDMerkleTree) UpdateLeafByIndex(i int, value []any) (string, error) {
+	if i < 0 || i >= len(t.values) {
+		return "", ErrIndexOutOfBounds
+	}
+	id, err := valueID(t.StandardMerkleTree, value, t.idField)
+	if err != nil {
+		return "", err
+	}
+	if j, ok := t.ids[id]; ok && j != i {
+		return "", fmt.Errorf("%w: %d", ErrDuplicatedID, id)
+	}
+	old, err := valueID(t.StandardMerkleTree, t.values[i].Value, t.idField)
+	if err != nil {
+		return "", err
+	}
+	root, err := t.StandardMerkleTree.UpdateLeafByIndex(i, value)
+	if err != nil {
+		return "", err
+	}
+	delete(t.ids, old)
+	t.ids[id] = i
+	return root, nil
+}
@@ -0,0 +1,113 @@
+package gomerk_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestIDMerkleTree(t *testing.T) {
+	vals := [][]any{
+		{1, "0x1111111111111111111111111111111111111111"},
+		{5, "0x2222222222222222222222222222222222222222"},
+		{1000, "0x3333333333333333333333333333333333333333"},
+	}
+	tree, err := gomerk.NewIDMerkleTree(vals, []string{"uint256", "address"}, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range vals {
+		proof, value, err := tree.ProofForID(uint64(v[0].(int)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value[1] != v[1] {
+			t.Errorf("got value %v, want %v", value, v)
+		}
+		ok, _ := tree.Verify(value, proof)
+		if !ok {
+			t.Errorf("id %v: verify failed", v[0])
+		}
+	}
+
+	if _, _, err := tree.ProofForID(2); err != gomerk.ErrLeafNotInTree {
+		t.Errorf("got %v, want ErrLeafNotInTree", err)
+	}
+}
+
+func TestIDMerkleTreeInvalid(t *testing.T) {
+	enc := []string{"uint256", "address"}
+	dup := [][]any{
+		{7, "0x1111111111111111111111111111111111111111"},
+		{7, "0x2222222222222222222222222222222222222222"},
+	}
+	if _, err := gomerk.NewIDMerkleTree(dup, enc, 0, true); !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Errorf("got %v, want ErrDuplicatedID", err)
+	}
+	if _, err := gomerk.NewIDMerkleTree(dup, enc, 1, true); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("got %v, want ErrUnsupportedType", err)
+	}
+	if _, err := gomerk.NewIDMerkleTree(dup, enc, 2, true); err != gomerk.ErrIndexOutOfBounds {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+}
+
+func TestIDMerkleTreeChanges(t *testing.T) {
+	enc := []string{"uint256", "address"}
+	vals := [][]any{
+		{1, "0x1111111111111111111111111111111111111111"},
+		{5, "0x2222222222222222222222222222222222222222"},
+		{9, "0x3333333333333333333333333333333333333333"},
+	}
+	tree, _ := gomerk.NewIDMerkleTree(vals, enc, 0, true)
+	check := func(step string, id uint64, want any) {
+		t.Helper()
+		proof, value, err := tree.ProofForID(id)
+		if err != nil {
+			t.Fatalf("%s: id %d: %v", step, id, err)
+		}
+		if value[1] != want {
+			t.Errorf("%s: id %d has value %v, want %v", step, id, value[1], want)
+		}
+		if ok, _ := tree.Verify(value, proof); !ok {
+			t.Errorf("%s: id %d: proof does not verify", step, id)
+		}
+	}
+
+	if err := tree.Rebuild([][]any{vals[0], vals[2], {5, "0x4444444444444444444444444444444444444444"}}); err != nil {
+		t.Fatal(err)
+	}
+	check("Rebuild", 5, "0x4444444444444444444444444444444444444444")
+	check("Rebuild", 1, vals[0][1])
+
+	if err := tree.Append([]any{7, "0x5555555555555555555555555555555555555555"}); err != nil {
+		t.Fatal(err)
+	}
+	check("Append", 7, "0x5555555555555555555555555555555555555555")
+	check("Append", 9, vals[2][1])
+
+	if _, err := tree.UpdateLeafByIndex(0, []any{3, vals[2][1]}); err != nil {
+		t.Fatal(err)
+	}
+	check("UpdateLeafByIndex", 3, vals[2][1])
+	if _, _, err := tree.ProofForID(1); err != gomerk.ErrLeafNotInTree {
+		t.Errorf("replaced id: got %v, want ErrLeafNotInTree", err)
+	}
+
+	root := tree.Root()
+	if err := tree.Append([]any{5, "0x6666666666666666666666666666666666666666"}); !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Errorf("Append taken id: got %v", err)
+	}
+	if _, err := tree.UpdateLeafByIndex(0, []any{7, vals[2][1]}); !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Errorf("UpdateLeafByIndex taken id: got %v", err)
+	}
+	if err := tree.Rebuild([][]any{vals[0], {1, vals[1][1]}}); !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Errorf("Rebuild duplicate ids: got %v", err)
+	}
+	if tree.Root() != root || tree.Len() != 4 {
+		t.Error("rejected change modified the tree")
+	}
+	check("rejected", 7, "0x5555555555555555555555555555555555555555")
+}
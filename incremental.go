@@ -0,0 +1,103 @@
+package gomerk
+
+import "encoding/binary"
+
+// MaxIncrementalDepth is the largest depth supported by IncrementalTree.
+const MaxIncrementalDepth = 32
+
+// DepositContractDepth is the depth of the Ethereum deposit contract's tree.
+const DepositContractDepth = 32
+
+// IncrementalTree is an append-only Merkle tree of fixed depth that keeps
+// only its right-edge frontier, as in the Tornado Cash and Semaphore
+// contracts. Unfilled positions hold the zero leaf, and inserts cost
+// O(depth) time and the tree O(depth) memory.
+//
+// Nodes are hashed with the hasher set by WithHasher, with the left child
+// first. With the default commutative hasher the root equals that of MakeTree
+// over the inserted leaves padded with zero leaves to 2^depth entries.
+type IncrementalTree struct {
+	hasher Hasher
+	depth  int
+	next   int
+	zeros  []Bytes32
+	filled []Bytes32
+	root   Bytes32
+}
+
+// NewIncrementalTree creates an empty IncrementalTree with room for 2^depth
+// leaves.
+func NewIncrementalTree(depth int, opts ...Option) (*IncrementalTree, error) {
+	if depth < 1 || depth > MaxIncrementalDepth {
+		return nil, ErrInvalidDepth
+	}
+	h := newOptions(opts).hash()
+	zeros := make([]Bytes32, depth+1)
+	for i := 1; i <= depth; i++ {
+		zeros[i] = h.NodeHash(zeros[i-1], zeros[i-1])
+	}
+	return &IncrementalTree{
+		hasher: h,
+		depth:  depth,
+		zeros:  zeros,
+		filled: append([]Bytes32(nil), zeros[:depth]...),
+		root:   zeros[depth],
+	}, nil
+}
+
+// NewDepositTree creates an empty IncrementalTree laid out and hashed as the
+// Ethereum deposit contract's, whose DepositRoot matches get_deposit_root.
+// Leaves are deposit data roots.
+func NewDepositTree() *IncrementalTree {
+	t, _ := NewIncrementalTree(DepositContractDepth, WithHasher(SHA256PositionalHasher))
+	return t
+}
+
+// Insert appends leaf and returns its index and the new root.
+func (t *IncrementalTree) Insert(leaf Bytes32) (int, Bytes32, error) {
+	if t.next == 1<<t.depth {
+		return 0, Bytes32{}, ErrTreeFull
+	}
+	index := t.next
+	cur := leaf
+	for i, idx := 0, index; i < t.depth; i, idx = i+1, idx/2 {
+		if idx%2 == 0 {
+			t.filled[i] = cur
+			cur = t.hasher.NodeHash(cur, t.zeros[i])
+		} else {
+			cur = t.hasher.NodeHash(t.filled[i], cur)
+		}
+	}
+	t.next++
+	t.root = cur
+	return index, cur, nil
+}
+
+// Append adds leaf, as the deposit contract's deposit does, and returns its
+// index.
+func (t *IncrementalTree) Append(leaf Bytes32) (int, error) {
+	index, _, err := t.Insert(leaf)
+	return index, err
+}
+
+// DepositRoot returns the root with the leaf count mixed in, as the node hash
+// of the root and the count as a little-endian 32-byte word. This is SSZ's
+// mix_in_length and, for trees from NewDepositTree, the deposit contract's
+// get_deposit_root.
+func (t *IncrementalTree) DepositRoot() Bytes32 {
+	var count Bytes32
+	binary.LittleEndian.PutUint64(count[:8], uint64(t.next))
+	return t.hasher.NodeHash(t.root, count)
+}
+
+func (t *IncrementalTree) Root() Bytes32 { return t.root }
+func (t *IncrementalTree) Len() int      { return t.next }
+func (t *IncrementalTree) Depth() int    { return t.depth }
+
+// ZeroHash returns the root of an empty subtree of the given height.
+func (t *IncrementalTree) ZeroHash(height int) (Bytes32, error) {
+	if height < 0 || height > t.depth {
+		return Bytes32{}, ErrInvalidDepth
+	}
+	return t.zeros[height], nil
+}
@@ -0,0 +1,115 @@
+package gomerk_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestIncrementalTreeMatchesFullTree(t *testing.T) {
+	const depth = 4
+	it, err := gomerk.NewIncrementalTree(depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := make([]gomerk.Bytes32, 1<<depth)
+
+	full, _ := gomerk.MakeTree(leaves)
+	if it.Root().Hex() != full[0] {
+		t.Errorf("empty root = %s, want %s", it.Root().Hex(), full[0])
+	}
+
+	for i := range 1 << depth {
+		leaf := gomerk.Keccak256([]byte{byte(i)})
+		idx, root, err := it.Insert(leaf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if idx != i {
+			t.Errorf("index = %d, want %d", idx, i)
+		}
+		leaves[i] = leaf
+		full, _ := gomerk.MakeTree(leaves)
+		if root.Hex() != full[0] || it.Root() != root {
+			t.Fatalf("after %d inserts: root = %s, want %s", i+1, root.Hex(), full[0])
+		}
+	}
+	if it.Len() != 1<<depth {
+		t.Errorf("len = %d, want %d", it.Len(), 1<<depth)
+	}
+	if _, _, err := it.Insert(gomerk.Bytes32{}); err != gomerk.ErrTreeFull {
+		t.Errorf("got %v, want ErrTreeFull", err)
+	}
+}
+
+func TestIncrementalTreeZeroHash(t *testing.T) {
+	it, _ := gomerk.NewIncrementalTree(3)
+	z1, _ := it.ZeroHash(1)
+	if z1 != gomerk.HashNode(gomerk.Bytes32{}, gomerk.Bytes32{}) {
+		t.Error("unexpected zero hash at height 1")
+	}
+	if z3, _ := it.ZeroHash(3); z3 != it.Root() {
+		t.Error("empty root should be the zero hash at full depth")
+	}
+	if _, err := it.ZeroHash(4); err != gomerk.ErrInvalidDepth {
+		t.Errorf("got %v, want ErrInvalidDepth", err)
+	}
+}
+
+func TestIncrementalTreeInvalidDepth(t *testing.T) {
+	for _, d := range []int{0, -1, gomerk.MaxIncrementalDepth + 1} {
+		if _, err := gomerk.NewIncrementalTree(d); err != gomerk.ErrInvalidDepth {
+			t.Errorf("depth %d: got %v, want ErrInvalidDepth", d, err)
+		}
+	}
+}
+
+func TestIncrementalTreeAppendWithHasher(t *testing.T) {
+	it, _ := gomerk.NewIncrementalTree(2, gomerk.WithHasher(gomerk.SHA256Hasher))
+	h := gomerk.SHA256Hasher
+	leaves := simpleLeaves(3)
+	for i, leaf := range leaves {
+		if idx, err := it.Append(leaf); err != nil || idx != i {
+			t.Fatalf("append %d: got (%d, %v)", i, idx, err)
+		}
+	}
+	want := h.NodeHash(h.NodeHash(leaves[0], leaves[1]), h.NodeHash(leaves[2], gomerk.Bytes32{}))
+	if it.Root() != want {
+		t.Errorf("root = %s, want %s", it.Root(), want)
+	}
+}
+
+func TestDepositTree(t *testing.T) {
+	dt := gomerk.NewDepositTree()
+	// get_deposit_root of a deposit contract without deposits.
+	want := gomerk.MustHexToBytes32("0xd70a234731285c6804c2a4f56711ddb8c82c99740f207854891028af34e27e5e")
+	if dt.DepositRoot() != want {
+		t.Fatalf("empty deposit root = %s, want %s", dt.DepositRoot(), want)
+	}
+
+	// Rebuild the root as hash_tree_root of an SSZ list of deposit data roots.
+	pair := func(a, b gomerk.Bytes32) gomerk.Bytes32 { return sha256.Sum256(append(a[:], b[:]...)) }
+	leaves := simpleLeaves(5)
+	for _, leaf := range leaves {
+		dt.Append(leaf)
+	}
+	level := append([]gomerk.Bytes32(nil), leaves...)
+	var zero gomerk.Bytes32
+	for range gomerk.DepositContractDepth {
+		if len(level)%2 == 1 {
+			level = append(level, zero)
+		}
+		next := make([]gomerk.Bytes32, len(level)/2)
+		for i := range next {
+			next[i] = pair(level[2*i], level[2*i+1])
+		}
+		level, zero = next, pair(zero, zero)
+	}
+	var count gomerk.Bytes32
+	binary.LittleEndian.PutUint64(count[:], uint64(len(leaves)))
+	if dt.Root() != level[0] || dt.DepositRoot() != pair(level[0], count) {
+		t.Fatalf("deposit root = %s, want %s", dt.DepositRoot(), pair(level[0], count))
+	}
+}
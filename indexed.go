@@ -0,0 +1,407 @@
+package gomerk
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// IndexedLeaf is a leaf of an IndexedMerkleTree: a value and the position of
+// the next larger value in the tree, which make the leaves a sorted linked
+// list. The leaf with the largest value has zero NextValue and NextIndex.
+type IndexedLeaf struct {
+	Value     Bytes32
+	NextValue Bytes32
+	NextIndex int
+}
+
+// Hash returns h.LeafHash(value || nextIndex || nextValue), nextIndex as a
+// 32-byte big-endian word.
+func (l IndexedLeaf) Hash(h Hasher) Bytes32 {
+	var buf [96]byte
+	copy(buf[:32], l.Value[:])
+	binary.BigEndian.PutUint64(buf[56:64], uint64(l.NextIndex))
+	copy(buf[64:], l.NextValue[:])
+	return h.LeafHash(buf[:])
+}
+
+// covers reports whether v falls strictly between the leaf's value and the
+// next one.
+func (l IndexedLeaf) covers(v Bytes32) bool {
+	return l.Value.Less(v) && (l.NextValue.IsZero() || v.Less(l.NextValue))
+}
+
+type indexedLeafJSON struct {
+	Value     string `json:"value"`
+	NextValue string `json:"nextValue"`
+	NextIndex int    `json:"nextIndex"`
+}
+
+func (l IndexedLeaf) MarshalJSON() ([]byte, error) {
+	return json.Marshal(indexedLeafJSON{l.Value.Hex(), l.NextValue.Hex(), l.NextIndex})
+}
+
+func (l *IndexedLeaf) UnmarshalJSON(data []byte) error {
+	var j indexedLeafJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	v, err := HexToBytes32(j.Value)
+	if err != nil {
+		return err
+	}
+	next, err := HexToBytes32(j.NextValue)
+	if err != nil {
+		return err
+	}
+	*l = IndexedLeaf{Value: v, NextValue: next, NextIndex: j.NextIndex}
+	return nil
+}
+
+// IndexedProof shows that Leaf is at Index of an IndexedMerkleTree. Siblings
+// run from the leaf up, one per level of the tree.
+type IndexedProof struct {
+	Leaf     IndexedLeaf `json:"leaf"`
+	Index    int         `json:"index"`
+	Siblings []string    `json:"siblings"`
+}
+
+// IndexedInsertion is the witness of an insertion that a rollup circuit
+// checks: the low leaf, whose value precedes the inserted one, against the
+// root before the insertion, and the new leaf against the root after it.
+type IndexedInsertion struct {
+	Low IndexedProof `json:"low"`
+	New IndexedProof `json:"new"`
+}
+
+// IndexedMerkleTree is a fixed-depth Merkle tree of IndexedLeaf values, as
+// used for nullifier trees in Aztec-style rollups. Leaves are appended in
+// insertion order, but each links to the next larger value, so a value's
+// absence is proven by the single leaf whose range covers it, the low leaf.
+// Leaf 0 is the zero value, which is never inserted.
+//
+// Unfilled positions hold the zero hash, as in IncrementalTree, and nodes are
+// hashed with PositionalKeccak256Hasher or the hasher set by WithHasher.
+// Proofs only bind leaf positions, which insertions depend on, when nodes are
+// hashed in position order, so commutative hashers are rejected with
+// ErrNotPositional.
+type IndexedMerkleTree struct {
+	hasher Hasher
+	depth  int
+	leaves []IndexedLeaf
+	// sorted holds leaf indices in ascending order of value.
+	sorted []int
+	// levels[h] holds the nodes at height h up to the last filled one.
+	levels [][]Bytes32
+	zeros  []Bytes32
+}
+
+// NewIndexedMerkleTree creates an IndexedMerkleTree with room for 2^depth
+// leaves, holding only the zero leaf.
+func NewIndexedMerkleTree(depth int, opts ...Option) (*IndexedMerkleTree, error) {
+	if depth < 1 || depth > MaxIncrementalDepth {
+		return nil, ErrInvalidDepth
+	}
+	h, err := indexedHasher(newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	zeros := make([]Bytes32, depth+1)
+	for i := 1; i <= depth; i++ {
+		zeros[i] = h.NodeHash(zeros[i-1], zeros[i-1])
+	}
+	t := &IndexedMerkleTree{
+		hasher: h,
+		depth:  depth,
+		leaves: []IndexedLeaf{{}},
+		sorted: []int{0},
+		levels: make([][]Bytes32, depth+1),
+		zeros:  zeros,
+	}
+	t.rehash([]int{0})
+	return t, nil
+}
+
+func (t *IndexedMerkleTree) Root() Bytes32 { return t.levels[t.depth][0] }
+func (t *IndexedMerkleTree) Len() int      { return len(t.leaves) }
+func (t *IndexedMerkleTree) Depth() int    { return t.depth }
+
+// Leaf returns the leaf at index.
+func (t *IndexedMerkleTree) Leaf(index int) (IndexedLeaf, error) {
+	if index < 0 || index >= len(t.leaves) {
+		return IndexedLeaf{}, ErrIndexOutOfBounds
+	}
+	return t.leaves[index], nil
+}
+
+// find returns the position in sorted of the first leaf whose value is not
+// less than v, and whether that leaf holds v.
+func (t *IndexedMerkleTree) find(v Bytes32) (int, bool) {
+	k := sort.Search(len(t.sorted), func(k int) bool { return !t.leaves[t.sorted[k]].Value.Less(v) })
+	return k, k < len(t.sorted) && t.leaves[t.sorted[k]].Value == v
+}
+
+// Insert adds a non-zero value that is not yet present and returns the
+// witness of the insertion.
+func (t *IndexedMerkleTree) Insert(value Bytes32) (*IndexedInsertion, error) {
+	if len(t.leaves) == 1<<t.depth {
+		return nil, ErrTreeFull
+	}
+	if err := t.checkValue(value); err != nil {
+		return nil, err
+	}
+	k, _ := t.find(value)
+	low := t.sorted[k-1]
+	ins := &IndexedInsertion{Low: t.prove(low)}
+	index := t.link(k, value)
+	t.rehash([]int{low, index})
+	ins.New = t.prove(index)
+	return ins, nil
+}
+
+// BatchInsert adds non-zero values that are neither present nor repeated,
+// hashing the changed paths once for the whole batch. It returns the index
+// of the first value; the rest follow in order.
+func (t *IndexedMerkleTree) BatchInsert(values []Bytes32) (int, error) {
+	if len(t.leaves)+len(values) > 1<<t.depth {
+		return 0, ErrTreeFull
+	}
+	seen := make(map[Bytes32]bool, len(values))
+	for i, v := range values {
+		if err := t.checkValue(v); err != nil {
+			return 0, fmt.Errorf("value %d: %w", i, err)
+		}
+		if seen[v] {
+			return 0, fmt.Errorf("value %d: %w", i, ErrDuplicatedID)
+		}
+		seen[v] = true
+	}
+	first := len(t.leaves)
+	if len(values) == 0 {
+		return first, nil
+	}
+	dirty := make([]int, 0, 2*len(values))
+	for _, v := range values {
+		k, _ := t.find(v)
+		dirty = append(dirty, t.sorted[k-1], t.link(k, v))
+	}
+	t.rehash(dirty)
+	return first, nil
+}
+
+// checkValue checks that value can be inserted.
+func (t *IndexedMerkleTree) checkValue(value Bytes32) error {
+	if value.IsZero() {
+		return ErrZeroValue
+	}
+	if _, ok := t.find(value); ok {
+		return ErrDuplicatedID
+	}
+	return nil
+}
+
+// link appends a leaf for value after the low leaf at position k-1 of
+// sorted, without hashing, and returns its index.
+func (t *IndexedMerkleTree) link(k int, value Bytes32) int {
+	index, low := len(t.leaves), t.sorted[k-1]
+	t.leaves = append(t.leaves, IndexedLeaf{Value: value, NextValue: t.leaves[low].NextValue, NextIndex: t.leaves[low].NextIndex})
+	t.leaves[low].NextValue, t.leaves[low].NextIndex = value, index
+	t.sorted = slices.Insert(t.sorted, k, index)
+	return index
+}
+
+// rehash recomputes the leaves at indices and their paths to the root.
+func (t *IndexedMerkleTree) rehash(indices []int) {
+	for _, i := range indices {
+		if i >= len(t.levels[0]) {
+			t.levels[0] = append(t.levels[0], make([]Bytes32, i+1-len(t.levels[0]))...)
+		}
+		t.levels[0][i] = t.leaves[i].Hash(t.hasher)
+	}
+	dirty := slices.Clone(indices)
+	for h := 1; h <= t.depth; h++ {
+		for j := range dirty {
+			dirty[j] /= 2
+		}
+		slices.Sort(dirty)
+		dirty = slices.Compact(dirty)
+		if n := dirty[len(dirty)-1] + 1; n > len(t.levels[h]) {
+			t.levels[h] = append(t.levels[h], make([]Bytes32, n-len(t.levels[h]))...)
+		}
+		for _, i := range dirty {
+			t.levels[h][i] = t.hasher.NodeHash(t.node(h-1, 2*i), t.node(h-1, 2*i+1))
+		}
+	}
+}
+
+func (t *IndexedMerkleTree) node(h, i int) Bytes32 {
+	if i < len(t.levels[h]) {
+		return t.levels[h][i]
+	}
+	return t.zeros[h]
+}
+
+func (t *IndexedMerkleTree) prove(index int) IndexedProof {
+	p := IndexedProof{Leaf: t.leaves[index], Index: index, Siblings: make([]string, t.depth)}
+	for h := range t.depth {
+		p.Siblings[h] = t.node(h, index^1).Hex()
+		index /= 2
+	}
+	return p
+}
+
+// ProveMembership returns the proof of the leaf holding value.
+func (t *IndexedMerkleTree) ProveMembership(value Bytes32) (*IndexedProof, error) {
+	k, ok := t.find(value)
+	if !ok || value.IsZero() {
+		return nil, ErrLeafNotInTree
+	}
+	p := t.prove(t.sorted[k])
+	return &p, nil
+}
+
+// ProveNonMembership returns the proof of the low leaf of an absent value,
+// the leaf whose value is the largest one below it.
+func (t *IndexedMerkleTree) ProveNonMembership(value Bytes32) (*IndexedProof, error) {
+	if value.IsZero() {
+		return nil, ErrZeroValue
+	}
+	k, ok := t.find(value)
+	if ok {
+		return nil, ErrLeafInTree
+	}
+	p := t.prove(t.sorted[k-1])
+	return &p, nil
+}
+
+// indexedHasher returns the hasher set in o, defaulting to
+// PositionalKeccak256Hasher, or ErrNotPositional if it is commutative.
+func indexedHasher(o options) (Hasher, error) {
+	h := o.hasher
+	if h == nil {
+		return PositionalKeccak256Hasher, nil
+	}
+	if commutative(h) {
+		return nil, ErrNotPositional
+	}
+	return h, nil
+}
+
+// VerifyIndexedMembership checks that proof shows value in the tree with the
+// given root, hashed with PositionalKeccak256Hasher.
+func VerifyIndexedMembership(root, value Bytes32, proof *IndexedProof) (bool, error) {
+	return verifyIndexedMembership(PositionalKeccak256Hasher, root, value, proof)
+}
+
+// VerifyIndexedNonMembership checks that proof holds the low leaf of value
+// in the tree with the given root, hashed with PositionalKeccak256Hasher.
+func VerifyIndexedNonMembership(root, value Bytes32, proof *IndexedProof) (bool, error) {
+	return verifyIndexedNonMembership(PositionalKeccak256Hasher, root, value, proof)
+}
+
+// VerifyIndexedInsertion checks that ins inserts value into the tree with
+// root oldRoot, giving newRoot, hashed with PositionalKeccak256Hasher.
+func VerifyIndexedInsertion(oldRoot, newRoot, value Bytes32, ins *IndexedInsertion) (bool, error) {
+	return verifyIndexedInsertion(PositionalKeccak256Hasher, oldRoot, newRoot, value, ins)
+}
+
+// VerifyIndexedMembership is VerifyIndexedMembership with the verifier's
+// hasher, if set. Commutative hashers are rejected as in
+// NewIndexedMerkleTree.
+func (v *Verifier) VerifyIndexedMembership(root, value Bytes32, proof *IndexedProof) (bool, error) {
+	h, err := indexedHasher(v.opts)
+	if err != nil {
+		return false, err
+	}
+	return verifyIndexedMembership(h, root, value, proof)
+}
+
+// VerifyIndexedNonMembership is VerifyIndexedNonMembership with the
+// verifier's hasher, if set.
+func (v *Verifier) VerifyIndexedNonMembership(root, value Bytes32, proof *IndexedProof) (bool, error) {
+	h, err := indexedHasher(v.opts)
+	if err != nil {
+		return false, err
+	}
+	return verifyIndexedNonMembership(h, root, value, proof)
+}
+
+// VerifyIndexedInsertion is VerifyIndexedInsertion with the verifier's
+// hasher, if set.
+func (v *Verifier) VerifyIndexedInsertion(oldRoot, newRoot, value Bytes32, ins *IndexedInsertion) (bool, error) {
+	h, err := indexedHasher(v.opts)
+	if err != nil {
+		return false, err
+	}
+	return verifyIndexedInsertion(h, oldRoot, newRoot, value, ins)
+}
+
+func verifyIndexedMembership(h Hasher, root, value Bytes32, p *IndexedProof) (bool, error) {
+	if value.IsZero() {
+		return false, ErrZeroValue
+	}
+	r, err := indexedRoot(h, p.Leaf.Hash(h), p)
+	if err != nil {
+		return false, err
+	}
+	return p.Leaf.Value == value && r == root, nil
+}
+
+func verifyIndexedNonMembership(h Hasher, root, value Bytes32, p *IndexedProof) (bool, error) {
+	if value.IsZero() {
+		return false, ErrZeroValue
+	}
+	r, err := indexedRoot(h, p.Leaf.Hash(h), p)
+	if err != nil {
+		return false, err
+	}
+	return p.Leaf.covers(value) && r == root, nil
+}
+
+func verifyIndexedInsertion(h Hasher, oldRoot, newRoot, value Bytes32, ins *IndexedInsertion) (bool, error) {
+	low := ins.Low.Leaf
+	if ok, err := verifyIndexedNonMembership(h, oldRoot, value, &ins.Low); !ok || err != nil {
+		return false, err
+	}
+	if ins.New.Leaf != (IndexedLeaf{Value: value, NextValue: low.NextValue, NextIndex: low.NextIndex}) {
+		return false, nil
+	}
+	// Linking the low leaf to the new one gives an intermediate root in
+	// which the new leaf's slot is still empty.
+	mid, err := indexedRoot(h, IndexedLeaf{low.Value, value, ins.New.Index}.Hash(h), &ins.Low)
+	if err != nil {
+		return false, err
+	}
+	empty, err := indexedRoot(h, Bytes32{}, &ins.New)
+	if err != nil {
+		return false, err
+	}
+	r, err := indexedRoot(h, ins.New.Leaf.Hash(h), &ins.New)
+	if err != nil {
+		return false, err
+	}
+	return empty == mid && r == newRoot, nil
+}
+
+// indexedRoot hashes leaf up p's path.
+func indexedRoot(h Hasher, leaf Bytes32, p *IndexedProof) (Bytes32, error) {
+	if len(p.Siblings) > MaxIncrementalDepth || p.Index < 0 || p.Index >= 1<<len(p.Siblings) {
+		return Bytes32{}, ErrInvalidProof
+	}
+	cur, index := leaf, p.Index
+	for _, s := range p.Siblings {
+		sib, err := HexToBytes32(s)
+		if err != nil {
+			return Bytes32{}, err
+		}
+		if index%2 == 0 {
+			cur = h.NodeHash(cur, sib)
+		} else {
+			cur = h.NodeHash(sib, cur)
+		}
+		index /= 2
+	}
+	return cur, nil
+}
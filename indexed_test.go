@@ -0,0 +1,190 @@
+package gomerk_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+// indexedRoot rebuilds an indexed tree's root from its leaves.
+func indexedRoot(t *testing.T, tree *gomerk.IndexedMerkleTree, h gomerk.Hasher) gomerk.Bytes32 {
+	t.Helper()
+	level := make([]gomerk.Bytes32, 1<<tree.Depth())
+	for i := range tree.Len() {
+		l, err := tree.Leaf(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		level[i] = l.Hash(h)
+	}
+	for len(level) > 1 {
+		for i := range len(level) / 2 {
+			level[i] = h.NodeHash(level[2*i], level[2*i+1])
+		}
+		level = level[:len(level)/2]
+	}
+	return level[0]
+}
+
+func randomValues(r *rand.Rand, n int) []gomerk.Bytes32 {
+	values := make([]gomerk.Bytes32, n)
+	for i := range values {
+		for j := range values[i] {
+			values[i][j] = byte(r.Uint32())
+		}
+	}
+	return values
+}
+
+func TestIndexedMerkleTree(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for _, h := range []gomerk.Hasher{gomerk.PositionalKeccak256Hasher, gomerk.SHA256PositionalHasher} {
+		tree, err := gomerk.NewIndexedMerkleTree(5, gomerk.WithHasher(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := gomerk.NewVerifier(gomerk.WithHasher(h))
+		if tree.Root() != indexedRoot(t, tree, h) {
+			t.Fatal("initial root mismatch")
+		}
+		values := randomValues(r, 20)
+		for i, value := range values {
+			old := tree.Root()
+			ins, err := tree.Insert(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ins.New.Index != i+1 || tree.Root() != indexedRoot(t, tree, h) {
+				t.Fatalf("%s: insert %d: index %d, root mismatch", h.ID(), i, ins.New.Index)
+			}
+			if ok, err := v.VerifyIndexedInsertion(old, tree.Root(), value, ins); !ok || err != nil {
+				t.Fatalf("%s: insert %d does not verify: %v", h.ID(), i, err)
+			}
+			if ok, _ := v.VerifyIndexedInsertion(old, tree.Root(), values[(i+1)%len(values)], ins); ok {
+				t.Fatalf("%s: insert %d verifies for another value", h.ID(), i)
+			}
+		}
+
+		// The leaves form a sorted list starting at the zero leaf.
+		var got []gomerk.Bytes32
+		for l, _ := tree.Leaf(0); l.NextIndex != 0; l, _ = tree.Leaf(l.NextIndex) {
+			got = append(got, l.NextValue)
+		}
+		want := slices.SortedFunc(slices.Values(values), gomerk.Bytes32.Compare)
+		if !slices.Equal(got, want) {
+			t.Fatalf("%s: linked values out of order", h.ID())
+		}
+
+		root := tree.Root()
+		for _, value := range values {
+			p, err := tree.ProveMembership(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := v.VerifyIndexedMembership(root, value, p); !ok || err != nil {
+				t.Fatalf("%s: membership does not verify: %v", h.ID(), err)
+			}
+			if ok, _ := v.VerifyIndexedNonMembership(root, value, p); ok {
+				t.Fatalf("%s: member proven absent", h.ID())
+			}
+			if _, err := tree.ProveNonMembership(value); !errors.Is(err, gomerk.ErrLeafInTree) {
+				t.Fatal(err)
+			}
+		}
+		for _, value := range append(randomValues(r, 20), gomerk.Bytes32{0xff, 0xff}, gomerk.Bytes32{31: 1}) {
+			p, err := tree.ProveNonMembership(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := v.VerifyIndexedNonMembership(root, value, p); !ok || err != nil {
+				t.Fatalf("%s: non-membership does not verify: %v", h.ID(), err)
+			}
+			p.Siblings[0] = gomerk.Bytes32{1}.Hex()
+			if ok, _ := v.VerifyIndexedNonMembership(root, value, p); ok {
+				t.Fatalf("%s: changed proof verifies", h.ID())
+			}
+		}
+	}
+}
+
+func TestIndexedMerkleTreeBatchInsert(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	values := randomValues(r, 40)
+	seq, _ := gomerk.NewIndexedMerkleTree(6, gomerk.WithPositionalHashing())
+	batch, _ := gomerk.NewIndexedMerkleTree(6, gomerk.WithPositionalHashing())
+	for _, v := range values {
+		if _, err := seq.Insert(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, err := batch.BatchInsert(values[:25])
+	if err != nil || first != 1 {
+		t.Fatal(first, err)
+	}
+	if first, err = batch.BatchInsert(values[25:]); err != nil || first != 26 {
+		t.Fatal(first, err)
+	}
+	if batch.Root() != seq.Root() || batch.Len() != 41 {
+		t.Fatal("batch root differs from sequential inserts")
+	}
+
+	if _, err := batch.BatchInsert([]gomerk.Bytes32{{9}, {8}, {9}}); !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Fatal(err)
+	}
+	if _, err := batch.BatchInsert([]gomerk.Bytes32{{9}, values[3]}); !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Fatal(err)
+	}
+	if _, err := batch.BatchInsert(randomValues(r, 24)); !errors.Is(err, gomerk.ErrTreeFull) {
+		t.Fatal(err)
+	}
+	if batch.Root() != seq.Root() {
+		t.Fatal("failed batch changed the tree")
+	}
+}
+
+func TestIndexedMerkleTreeErrors(t *testing.T) {
+	if _, err := gomerk.NewIndexedMerkleTree(0); !errors.Is(err, gomerk.ErrInvalidDepth) {
+		t.Fatal(err)
+	}
+	if _, err := gomerk.NewIndexedMerkleTree(4, gomerk.WithHasher(gomerk.Keccak256Hasher)); !errors.Is(err, gomerk.ErrNotPositional) {
+		t.Fatalf("sorted-pair hasher: err = %v", err)
+	}
+	tree, _ := gomerk.NewIndexedMerkleTree(1)
+	v := gomerk.NewVerifier(gomerk.WithHasher(gomerk.Keccak256Hasher))
+	if _, err := v.VerifyIndexedInsertion(tree.Root(), tree.Root(), gomerk.Bytes32{1}, &gomerk.IndexedInsertion{}); !errors.Is(err, gomerk.ErrNotPositional) {
+		t.Fatalf("sorted-pair verifier: err = %v", err)
+	}
+	if _, err := tree.Insert(gomerk.Bytes32{}); !errors.Is(err, gomerk.ErrZeroValue) {
+		t.Fatal(err)
+	}
+	if _, err := tree.Insert(gomerk.Bytes32{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.Insert(gomerk.Bytes32{2}); !errors.Is(err, gomerk.ErrTreeFull) {
+		t.Fatal(err)
+	}
+	if _, err := tree.ProveMembership(gomerk.Bytes32{2}); !errors.Is(err, gomerk.ErrLeafNotInTree) {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexedInsertionJSON(t *testing.T) {
+	tree, _ := gomerk.NewIndexedMerkleTree(3)
+	old := tree.Root()
+	ins, _ := tree.Insert(gomerk.Bytes32{7})
+	b, err := json.Marshal(ins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got gomerk.IndexedInsertion
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := gomerk.VerifyIndexedInsertion(old, tree.Root(), gomerk.Bytes32{7}, &got); !ok || err != nil {
+		t.Fatalf("%s: %v", b, err)
+	}
+}
@@ -0,0 +1,258 @@
+// Package ingest streams tree values from CSV and JSON Lines input,
+// converting each column to its ABI type as it is read.
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pyroth/gomerk"
+)
+
+// Schema describes the values in an input.
+type Schema struct {
+	// Types is the leaf encoding, one type per column.
+	Types []string
+
+	// Columns names the columns in leaf encoding order. CSV input with a
+	// header row and JSON Lines objects are read by these names. Without
+	// them, CSV columns are taken in order and JSON lines must be arrays.
+	Columns []string
+
+	// Header is set if the first CSV row is a header.
+	Header bool
+}
+
+// LineError reports an invalid input line. Line is 1-based.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string { return fmt.Sprintf("line %d: %v", e.Line, e.Err) }
+func (e *LineError) Unwrap() error { return e.Err }
+
+// Reader reads values one line at a time.
+type Reader struct {
+	schema Schema
+	next   func() (line int, fields []any, err error)
+	err    error
+}
+
+// FromCSV returns a Reader of CSV rows. Integers may be decimal or 0x hex,
+// bools are parsed with strconv.ParseBool and array and tuple cells are read
+// as JSON.
+func FromCSV(r io.Reader, schema Schema) *Reader {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+	var order []int
+	rd := &Reader{schema: schema}
+	rd.next = func() (int, []any, error) {
+		for {
+			row, err := cr.Read()
+			if err != nil {
+				var pe *csv.ParseError
+				if errors.As(err, &pe) {
+					return pe.Line, nil, pe.Err
+				}
+				return 0, nil, err
+			}
+			line, _ := cr.FieldPos(0)
+			if order == nil {
+				if order, err = csvOrder(schema, row); err != nil {
+					return line, nil, err
+				}
+				if schema.Header {
+					continue
+				}
+			}
+			fields := make([]any, len(order))
+			for i, j := range order {
+				if j >= len(row) {
+					return line, nil, fmt.Errorf("%w: %d columns, want %d", gomerk.ErrMismatchedCount, len(row), len(order))
+				}
+				if fields[i], err = parseCell(schema.Types[i], row[j]); err != nil {
+					return line, nil, &gomerk.EncodeError{Index: -1, Field: i, Type: schema.Types[i], Err: err}
+				}
+			}
+			return line, fields, nil
+		}
+	}
+	return rd
+}
+
+// csvOrder maps leaf encoding positions to CSV columns, by header name when
+// the schema names its columns.
+func csvOrder(schema Schema, first []string) ([]int, error) {
+	order := make([]int, len(schema.Types))
+	for i := range order {
+		order[i] = i
+	}
+	if !schema.Header || schema.Columns == nil {
+		return order, nil
+	}
+	for i, name := range schema.Columns {
+		j := slices.IndexFunc(first, func(h string) bool { return strings.EqualFold(strings.TrimSpace(h), name) })
+		if j < 0 {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+		order[i] = j
+	}
+	return order, nil
+}
+
+func parseCell(typ, cell string) (any, error) {
+	switch {
+	case typ == "bool":
+		b, err := strconv.ParseBool(strings.TrimSpace(cell))
+		if err != nil {
+			return nil, gomerk.ErrAbiEncode
+		}
+		return b, nil
+	case strings.HasSuffix(typ, "]"), strings.HasSuffix(typ, ")"):
+		dec := json.NewDecoder(strings.NewReader(cell))
+		dec.UseNumber()
+		var v []any
+		if err := dec.Decode(&v); err != nil {
+			return nil, gomerk.ErrAbiEncode
+		}
+		return v, nil
+	default:
+		return strings.TrimSpace(cell), nil
+	}
+}
+
+// FromJSONL returns a Reader of JSON Lines input, one value per line as an
+// array or, if the schema names its columns, an object. Blank lines are
+// skipped.
+func FromJSONL(r io.Reader, schema Schema) *Reader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	line := 0
+	rd := &Reader{schema: schema}
+	rd.next = func() (int, []any, error) {
+		for sc.Scan() {
+			line++
+			data := bytes.TrimSpace(sc.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			fields, err := decodeLine(schema, data)
+			return line, fields, err
+		}
+		if err := sc.Err(); err != nil {
+			return line + 1, nil, err
+		}
+		return line, nil, io.EOF
+	}
+	return rd
+}
+
+func decodeLine(schema Schema, data []byte) ([]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields []any
+	if schema.Columns == nil {
+		if err := dec.Decode(&fields); err != nil {
+			return nil, err
+		}
+	} else {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return nil, err
+		}
+		fields = make([]any, len(schema.Columns))
+		for i, name := range schema.Columns {
+			v, ok := obj[name]
+			if !ok {
+				return nil, fmt.Errorf("missing field %q", name)
+			}
+			fields[i] = v
+		}
+	}
+	for i, typ := range schema.Types {
+		if i >= len(fields) {
+			break
+		}
+		if s, ok := fields[i].(string); ok && typ == "bool" {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, &gomerk.EncodeError{Index: -1, Field: i, Type: typ, Err: gomerk.ErrAbiEncode}
+			}
+			fields[i] = b
+		}
+	}
+	return fields, nil
+}
+
+// Next returns the next value, or io.EOF after the last. A value that does
+// not encode with the schema's types is reported as a *LineError, and so are
+// malformed lines.
+func (r *Reader) Next() ([]any, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	line, fields, err := r.next()
+	if err == nil {
+		if len(fields) != len(r.schema.Types) {
+			err = fmt.Errorf("%w: %d fields, want %d", gomerk.ErrMismatchedCount, len(fields), len(r.schema.Types))
+		} else {
+			_, err = gomerk.ABIEncodePacked(r.schema.Types, fields)
+		}
+	}
+	if err != nil {
+		if err != io.EOF {
+			err = &LineError{Line: line, Err: err}
+		}
+		r.err = err
+		return nil, err
+	}
+	return fields, nil
+}
+
+// All iterates over the remaining values, stopping at the first error.
+func (r *Reader) All() iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		for {
+			v, err := r.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ReadAll returns the remaining values.
+func (r *Reader) ReadAll() ([][]any, error) {
+	var values [][]any
+	for v, err := range r.All() {
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// Build reads the remaining values and builds a tree of them with the
+// schema's types.
+func (r *Reader) Build(opts ...gomerk.Option) (*gomerk.StandardMerkleTree, error) {
+	values, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return gomerk.BuildStandardMerkleTree(values, r.schema.Types, opts...)
+}
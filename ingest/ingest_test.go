@@ -0,0 +1,122 @@
+package ingest_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/ingest"
+)
+
+var types = []string{"address", "uint256", "bool"}
+
+var values = [][]any{
+	{"0x1111111111111111111111111111111111111111", "5000000000000000000", true},
+	{"0x2222222222222222222222222222222222222222", "0x10", false},
+}
+
+func TestFromCSV(t *testing.T) {
+	in := `active,account,amount
+true,0x1111111111111111111111111111111111111111,5000000000000000000
+false, 0x2222222222222222222222222222222222222222,0x10
+`
+	r := ingest.FromCSV(strings.NewReader(in), ingest.Schema{
+		Types:   types,
+		Columns: []string{"account", "amount", "active"},
+		Header:  true,
+	})
+	tree, err := r.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := gomerk.BuildStandardMerkleTree(values, types)
+	if tree.Root() != want.Root() {
+		t.Errorf("root = %s, want %s", tree.Root(), want.Root())
+	}
+}
+
+func TestFromCSVPositional(t *testing.T) {
+	in := "0x1111111111111111111111111111111111111111,\"[1,2]\"\n"
+	r := ingest.FromCSV(strings.NewReader(in), ingest.Schema{Types: []string{"address", "uint256[]"}})
+	v, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arr, ok := v[1].([]any); !ok || len(arr) != 2 {
+		t.Errorf("array cell = %#v", v[1])
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() at end = %v, want io.EOF", err)
+	}
+}
+
+func TestFromJSONL(t *testing.T) {
+	in := `{"account": "0x1111111111111111111111111111111111111111", "amount": 5000000000000000000, "active": true}
+
+{"account": "0x2222222222222222222222222222222222222222", "amount": "0x10", "active": "false"}
+`
+	r := ingest.FromJSONL(strings.NewReader(in), ingest.Schema{Types: types, Columns: []string{"account", "amount", "active"}})
+	tree, err := r.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := gomerk.BuildStandardMerkleTree(values, types)
+	if tree.Root() != want.Root() {
+		t.Errorf("root = %s, want %s", tree.Root(), want.Root())
+	}
+
+	arrays := `["0x1111111111111111111111111111111111111111", "1", true]` + "\n"
+	got, err := ingest.FromJSONL(strings.NewReader(arrays), ingest.Schema{Types: types}).ReadAll()
+	if err != nil || len(got) != 1 {
+		t.Errorf("ReadAll() = %v, %v", got, err)
+	}
+}
+
+func TestLineErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		r    *ingest.Reader
+		line int
+		want error
+	}{
+		{
+			name: "csv bad address",
+			r:    ingest.FromCSV(strings.NewReader("a,b,c\n0x11,1,true\n"), ingest.Schema{Types: types, Header: true}),
+			line: 2,
+			want: gomerk.ErrAbiEncode,
+		},
+		{
+			name: "csv bad bool",
+			r:    ingest.FromCSV(strings.NewReader("0x1111111111111111111111111111111111111111,1,yes\n"), ingest.Schema{Types: types}),
+			line: 1,
+			want: gomerk.ErrAbiEncode,
+		},
+		{
+			name: "jsonl bad amount",
+			r:    ingest.FromJSONL(strings.NewReader("[\"0x1111111111111111111111111111111111111111\", \"1\", true]\n\n[\"0x1111111111111111111111111111111111111111\", \"x\", true]\n"), ingest.Schema{Types: types}),
+			line: 3,
+			want: gomerk.ErrAbiEncode,
+		},
+		{
+			name: "jsonl short line",
+			r:    ingest.FromJSONL(strings.NewReader(`["0x1111111111111111111111111111111111111111"]`), ingest.Schema{Types: types}),
+			line: 1,
+			want: gomerk.ErrMismatchedCount,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.r.ReadAll()
+			var le *ingest.LineError
+			if !errors.As(err, &le) || le.Line != tc.line || !errors.Is(err, tc.want) {
+				t.Errorf("err = %v, want line %d wrapping %v", err, tc.line, tc.want)
+			}
+		})
+	}
+
+	r := ingest.FromCSV(strings.NewReader("x,y\n"), ingest.Schema{Types: types, Columns: []string{"account", "amount", "active"}, Header: true})
+	if _, err := r.Next(); err == nil || !strings.Contains(err.Error(), "account") {
+		t.Errorf("missing column: err = %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package gomerk
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Tree is the behavior shared by StandardMerkleTree and SimpleMerkleTree.
+type Tree interface {
+	Root() string
+	RootBytes() Bytes32
+	Len() int
+	GetProofByIndex(i int) ([]string, error)
+	Validate() error
+	Render() (string, error)
+}
+
+var (
+	_ Tree = (*StandardMerkleTree)(nil)
+	_ Tree = (*SimpleMerkleTree)(nil)
+)
+
+// LoadFromJSON loads a standard-v1 or simple-v1 dump, such as one written by
+// OpenZeppelin's @openzeppelin/merkle-tree, returning a *StandardMerkleTree or
+// *SimpleMerkleTree. OpenZeppelin encodes string and bytes fields in full, so
+// a standard dump that records no encoding and does not validate with hashed
+// fields is loaded as if built with WithFullABIEncoding. As with
+// StandardTreeData.UnmarshalJSON, JSON numbers in values keep full precision
+// and marshal back exactly as read.
+func LoadFromJSON(r io.Reader) (Tree, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Format string `json:"format"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, ErrInvalidFormat
+	}
+	switch header.Format {
+	case FormatStandardV1:
+		var data StandardTreeData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, ErrInvalidFormat
+		}
+		t, err := LoadStandardMerkleTree(data)
+		if err != nil && data.Encoding == "" && data.EIP712 == nil {
+			data.Encoding = EncodingFullABI
+			if full, ferr := LoadStandardMerkleTree(data); ferr == nil {
+				return full, nil
+			}
+		}
+		return t, err
+	case FormatSimpleV1:
+		var data SimpleTreeData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, ErrInvalidFormat
+		}
+		return LoadSimpleMerkleTree(data)
+	default:
+		return nil, ErrInvalidFormat
+	}
+}
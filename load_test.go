@@ -0,0 +1,117 @@
+package gomerk_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+// ozStandardDump is the dump @openzeppelin/merkle-tree writes for the example
+// in its README.
+const ozStandardDump = `{"format":"standard-v1","leafEncoding":["address","uint256"],"tree":["0xd4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77","0xeb02c421cfa48976e66dfb29120745909ea3a0f843456c263cf8f1253483e283","0xb92c48e9d7abe27fd8dfd6b5dfdbfb1c9a463f80c712b66f3a5180a090cccafc"],"values":[{"value":["0x1111111111111111111111111111111111111111","5000000000000000000"],"treeIndex":1},{"value":["0x2222222222222222222222222222222222222222","2500000000000000000"],"treeIndex":2}]}`
+
+func TestLoadFromJSONOpenZeppelin(t *testing.T) {
+	tree, err := gomerk.LoadFromJSON(strings.NewReader(ozStandardDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	std, ok := tree.(*gomerk.StandardMerkleTree)
+	if !ok {
+		t.Fatalf("got %T, want *StandardMerkleTree", tree)
+	}
+	if std.Root() != "0xd4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77" {
+		t.Errorf("root = %s", std.Root())
+	}
+	if out, _ := json.Marshal(std.Dump()); string(out) != ozStandardDump {
+		t.Errorf("dump differs from the OpenZeppelin dump:\n%s", out)
+	}
+
+	fresh, _ := gomerk.NewStandardMerkleTree([][]any{
+		{"0x1111111111111111111111111111111111111111", "5000000000000000000"},
+		{"0x2222222222222222222222222222222222222222", "2500000000000000000"},
+	}, []string{"address", "uint256"}, true)
+	if out, _ := json.Marshal(fresh.Dump()); string(out) != ozStandardDump {
+		t.Errorf("fresh dump differs from the OpenZeppelin dump:\n%s", out)
+	}
+}
+
+// ozStringDump is the dump @openzeppelin/merkle-tree writes for values with
+// a string field, which it abi-encodes in full.
+const ozStringDump = `{"format":"standard-v1","leafEncoding":["string","uint256"],"tree":["0x9e9a62b3dddb3565622e5c2bfc83c768229d512ca935caae4295904087181111","0xca0a2e8a2d52266717c029771257cb7eb083d2836584d796a886ac93cba1c8ff","0x8e2710ac5d40907bc92061a618c118b78510911bedf9a159898b341b02d963a4","0x516a9a7bbcdf0fc24a6dbfd6230e15642f9dd50ab1cb1ea8325ef87c8ef6da3c","0x3caa2da2734548e9dc3065590746e2f296d422752c92080b3d7a7ef5f875bde2"],"values":[{"value":["alice","100"],"treeIndex":4},{"value":["bob","250"],"treeIndex":3},{"value":["carol with a name longer than thirty-two bytes","7"],"treeIndex":2}]}`
+
+func TestLoadFromJSONOpenZeppelinString(t *testing.T) {
+	tree, err := gomerk.LoadFromJSON(strings.NewReader(ozStringDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := tree.(*gomerk.StandardMerkleTree)
+	if std.Root() != "0x9e9a62b3dddb3565622e5c2bfc83c768229d512ca935caae4295904087181111" {
+		t.Errorf("root = %s", std.Root())
+	}
+	if enc := std.Dump().Encoding; enc != gomerk.EncodingFullABI {
+		t.Errorf("encoding = %q", enc)
+	}
+	value := []any{"carol with a name longer than thirty-two bytes", "7"}
+	proof, err := std.GetProof(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := std.Verify(value, proof); !ok || err != nil {
+		t.Errorf("verify = %v, %v", ok, err)
+	}
+
+	fresh, _ := gomerk.NewStandardMerkleTree([][]any{
+		{"alice", "100"}, {"bob", "250"}, value,
+	}, []string{"string", "uint256"}, true, gomerk.WithFullABIEncoding())
+	if fresh.Root() != std.Root() {
+		t.Errorf("fresh root %s, loaded root %s", fresh.Root(), std.Root())
+	}
+	if _, err := gomerk.NewStandardMerkleTree([][]any{value}, []string{"string", "uint256"}, true, gomerk.WithFullABIEncoding(), gomerk.WithPackedLeaves()); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("full ABI packed leaves: err = %v", err)
+	}
+}
+
+func TestLoadFromJSONNumbers(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	vals := [][]any{{"0x" + padAddr(1), "123456789012345678901234567"}, {"0x" + padAddr(2), "7"}}
+	want, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+
+	raw, _ := json.Marshal(want.Dump())
+	numeric := strings.NewReplacer(`"123456789012345678901234567"`, `123456789012345678901234567`, `"7"`, `7e0`).Replace(string(raw))
+	tree, err := gomerk.LoadFromJSON(strings.NewReader(numeric))
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := tree.(*gomerk.StandardMerkleTree)
+	if std.Root() != want.Root() {
+		t.Error("root differs from a tree built with string amounts")
+	}
+	proof, _ := std.GetProof(vals[0])
+	if ok, _ := std.Verify(vals[0], proof); !ok {
+		t.Error("loaded tree rejected a proof for a large amount")
+	}
+	if out, _ := json.Marshal(std.Dump()); string(out) != numeric {
+		t.Errorf("numbers not preserved:\n%s\n%s", out, numeric)
+	}
+}
+
+func TestLoadFromJSONSimple(t *testing.T) {
+	want, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(3), true)
+	raw, _ := json.Marshal(want.Dump())
+	tree, err := gomerk.LoadFromJSON(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tree.(*gomerk.SimpleMerkleTree); !ok || tree.Root() != want.Root() {
+		t.Errorf("got %T with root %s, want simple tree with root %s", tree, tree.Root(), want.Root())
+	}
+
+	for _, bad := range []string{`{"format":"other-v1"}`, `not json`, `{"format":"simple-v1","tree":1}`} {
+		if _, err := gomerk.LoadFromJSON(strings.NewReader(bad)); err != gomerk.ErrInvalidFormat {
+			t.Errorf("%s: got %v, want ErrInvalidFormat", bad, err)
+		}
+	}
+}
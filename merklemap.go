@@ -0,0 +1,163 @@
+package gomerk
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MerkleMap is a key-value map committed to by a SparseMerkleTree. Each key
+// is stored in the slot MapKey(k) with the value MapValue(v), so its
+// proofs are SparseProofs checked with VerifyMapInclusion and
+// VerifyMapExclusion, or with VerifySparseMembership and
+// VerifySparseNonMembership for custom key hashes.
+type MerkleMap[K comparable] struct {
+	tree    *SparseMerkleTree
+	values  map[K][]byte
+	keyHash func(K) Bytes32
+}
+
+// NewMerkleMap creates an empty MerkleMap hashing keys with MapKey. It
+// returns ErrUnsupportedType if MapKey does not support K.
+func NewMerkleMap[K comparable]() (*MerkleMap[K], error) {
+	var zero K
+	if _, err := MapKey(zero); err != nil {
+		return nil, err
+	}
+	return NewMerkleMapFunc(func(k K) Bytes32 {
+		h, _ := MapKey(k)
+		return h
+	}), nil
+}
+
+// NewMerkleMapFunc creates an empty MerkleMap hashing keys with keyHash,
+// which must be deterministic and collision-resistant.
+func NewMerkleMapFunc[K comparable](keyHash func(K) Bytes32) *MerkleMap[K] {
+	return &MerkleMap[K]{tree: NewSparseMerkleTree(), values: make(map[K][]byte), keyHash: keyHash}
+}
+
+func (m *MerkleMap[K]) Root() Bytes32 { return m.tree.Root() }
+func (m *MerkleMap[K]) Len() int      { return len(m.values) }
+
+// Get returns the value stored under k.
+func (m *MerkleMap[K]) Get(k K) ([]byte, bool) {
+	v, ok := m.values[k]
+	return v, ok
+}
+
+// Set stores v under k, replacing any previous value.
+func (m *MerkleMap[K]) Set(k K, v []byte) error {
+	key, value := m.keyHash(k), MapValue(v)
+	var err error
+	if _, ok := m.values[k]; ok {
+		err = m.tree.Update(key, value)
+	} else {
+		err = m.tree.Insert(key, value)
+	}
+	if err != nil {
+		return err
+	}
+	m.values[k] = append([]byte(nil), v...)
+	return nil
+}
+
+// Delete removes k and reports whether it was present. If the tree rejects
+// the deletion, k is kept and the error returned.
+func (m *MerkleMap[K]) Delete(k K) (bool, error) {
+	if _, ok := m.values[k]; !ok {
+		return false, nil
+	}
+	if err := m.tree.Delete(m.keyHash(k)); err != nil {
+		return false, err
+	}
+	delete(m.values, k)
+	return true, nil
+}
+
+// ProveInclusion returns the proof that k holds its value.
+func (m *MerkleMap[K]) ProveInclusion(k K) (SparseProof, error) {
+	if _, ok := m.values[k]; !ok {
+		return SparseProof{}, ErrLeafNotInTree
+	}
+	return m.tree.Prove(m.keyHash(k)), nil
+}
+
+// ProveExclusion returns the proof that k is absent.
+func (m *MerkleMap[K]) ProveExclusion(k K) (SparseProof, error) {
+	if _, ok := m.values[k]; ok {
+		return SparseProof{}, ErrLeafInTree
+	}
+	return m.tree.Prove(m.keyHash(k)), nil
+}
+
+// VerifyMapInclusion checks that proof shows k holding v in the MerkleMap
+// with the given root.
+func VerifyMapInclusion[K comparable](root Bytes32, k K, v []byte, proof SparseProof) (bool, error) {
+	key, err := MapKey(k)
+	if err != nil {
+		return false, err
+	}
+	return VerifySparseMembership(root, key, MapValue(v), proof)
+}
+
+// VerifyMapExclusion checks that proof shows k absent from the MerkleMap
+// with the given root.
+func VerifyMapExclusion[K comparable](root Bytes32, k K, proof SparseProof) (bool, error) {
+	key, err := MapKey(k)
+	if err != nil {
+		return false, err
+	}
+	return VerifySparseNonMembership(root, key, proof)
+}
+
+// MapKey returns the slot of a key as keccak256 of its Solidity encoding:
+// the bytes of a string, the 32-byte word of an integer or bool, and the
+// 32 bytes of a Bytes32. Other key types need NewMerkleMapFunc.
+func MapKey[K comparable](k K) (Bytes32, error) {
+	var word Bytes32
+	switch k := any(k).(type) {
+	case string:
+		return Keccak256([]byte(k)), nil
+	case Bytes32:
+		return Keccak256(k[:]), nil
+	case bool:
+		if k {
+			word[31] = 1
+		}
+	case int:
+		putInt(&word, int64(k))
+	case int8:
+		putInt(&word, int64(k))
+	case int16:
+		putInt(&word, int64(k))
+	case int32:
+		putInt(&word, int64(k))
+	case int64:
+		putInt(&word, k)
+	case uint:
+		binary.BigEndian.PutUint64(word[24:], uint64(k))
+	case uint8:
+		word[31] = k
+	case uint16:
+		binary.BigEndian.PutUint64(word[24:], uint64(k))
+	case uint32:
+		binary.BigEndian.PutUint64(word[24:], uint64(k))
+	case uint64:
+		binary.BigEndian.PutUint64(word[24:], k)
+	default:
+		return Bytes32{}, fmt.Errorf("%w: %T map key", ErrUnsupportedType, k)
+	}
+	return Keccak256(word[:]), nil
+}
+
+// putInt writes v as a two's complement 32-byte word.
+func putInt(word *Bytes32, v int64) {
+	if v < 0 {
+		for i := range 24 {
+			word[i] = 0xff
+		}
+	}
+	binary.BigEndian.PutUint64(word[24:], uint64(v))
+}
+
+// MapValue returns the slot value of v, keccak256(v).
+func MapValue(v []byte) Bytes32 { return Keccak256(v) }
@@ -0,0 +1,132 @@
+package gomerk_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestMerkleMap(t *testing.T) {
+	m, err := gomerk.NewMerkleMap[string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Root().IsZero() {
+		t.Fatal("empty map has a non-zero root")
+	}
+	for i := range 20 {
+		if err := m.Set(fmt.Sprint("key", i), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Set("key3", []byte("updated")); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := m.Get("key3"); !ok || string(v) != "updated" || m.Len() != 20 {
+		t.Fatalf("Get = %q, %v", v, ok)
+	}
+	if ok, err := m.Delete("key4"); !ok || err != nil {
+		t.Fatalf("Delete = %v, %v", ok, err)
+	}
+	if ok, err := m.Delete("key4"); ok || err != nil {
+		t.Fatalf("Delete of a missing key = %v, %v", ok, err)
+	}
+
+	root := m.Root()
+	p, err := m.ProveInclusion("key3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := gomerk.VerifyMapInclusion(root, "key3", []byte("updated"), p); !ok || err != nil {
+		t.Fatal("inclusion does not verify", err)
+	}
+	if ok, _ := gomerk.VerifyMapInclusion(root, "key3", []byte{3}, p); ok {
+		t.Fatal("stale value verifies")
+	}
+	if _, err := m.ProveExclusion("key3"); !errors.Is(err, gomerk.ErrLeafInTree) {
+		t.Fatal(err)
+	}
+
+	p, err = m.ProveExclusion("key4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := gomerk.VerifyMapExclusion(root, "key4", p); !ok || err != nil {
+		t.Fatal("exclusion does not verify", err)
+	}
+	if ok, _ := gomerk.VerifyMapExclusion(root, "key5", p); ok {
+		t.Fatal("exclusion verifies for a present key")
+	}
+	if _, err := m.ProveInclusion("key4"); !errors.Is(err, gomerk.ErrLeafNotInTree) {
+		t.Fatal(err)
+	}
+
+	// The root depends only on the contents.
+	other, _ := gomerk.NewMerkleMap[string]()
+	for i := 19; i >= 0; i-- {
+		if i != 4 {
+			other.Set(fmt.Sprint("key", i), []byte{byte(i)})
+		}
+	}
+	other.Set("key3", []byte("updated"))
+	if other.Root() != root {
+		t.Fatal("root depends on insertion order")
+	}
+}
+
+func TestMapKey(t *testing.T) {
+	word := func(b ...byte) gomerk.Bytes32 {
+		var w gomerk.Bytes32
+		copy(w[32-len(b):], b)
+		return gomerk.Keccak256(w[:])
+	}
+	var negOne gomerk.Bytes32
+	for i := range negOne {
+		negOne[i] = 0xff
+	}
+	for _, tc := range []struct {
+		key  any
+		want gomerk.Bytes32
+	}{
+		{"abc", gomerk.Keccak256([]byte("abc"))},
+		{uint64(258), word(1, 2)},
+		{int16(258), word(1, 2)},
+		{uint8(7), word(7)},
+		{true, word(1)},
+		{-1, gomerk.Keccak256(negOne[:])},
+		{gomerk.Bytes32{1}, gomerk.Keccak256(append([]byte{1}, make([]byte, 31)...))},
+	} {
+		got, err := gomerk.MapKey(tc.key)
+		if err != nil || got != tc.want {
+			t.Errorf("MapKey(%v) = %s, %v", tc.key, got, err)
+		}
+	}
+
+	if _, err := gomerk.NewMerkleMap[[2]int](); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Fatal(err)
+	}
+	m := gomerk.NewMerkleMapFunc(func(k [2]int) gomerk.Bytes32 { return gomerk.Keccak256([]byte{byte(k[0]), byte(k[1])}) })
+	if err := m.Set([2]int{1, 2}, []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := m.ProveInclusion([2]int{1, 2})
+	if ok, _ := gomerk.VerifySparseMembership(m.Root(), gomerk.Keccak256([]byte{1, 2}), gomerk.MapValue([]byte("v")), p); !ok {
+		t.Fatal("custom key hash does not verify")
+	}
+
+	// A key hash that changes between calls makes the tree reject Delete.
+	calls := 0
+	bad := gomerk.NewMerkleMapFunc(func(string) gomerk.Bytes32 {
+		calls++
+		return gomerk.Bytes32{byte(calls)}
+	})
+	bad.Set("k", []byte("v"))
+	if ok, err := bad.Delete("k"); ok || !errors.Is(err, gomerk.ErrLeafNotInTree) {
+		t.Fatalf("Delete = %v, %v", ok, err)
+	}
+	if _, ok := bad.Get("k"); !ok || bad.Len() != 1 {
+		t.Fatal("failed Delete removed the value")
+	}
+}
@@ -0,0 +1,223 @@
+package gomerk
+
+import "math/bits"
+
+// FormatMMRV1 is the Format of serialized MMR data.
+const FormatMMRV1 = "mmr-v1"
+
+// MMR is a Merkle Mountain Range: an append-only list of perfect Merkle trees
+// (mountains) of strictly decreasing height, whose peaks are bagged into a
+// single root. Nodes are numbered by 0-based position in insertion order, as
+// in the ckb and Polkadot BEEFY implementations, and hashed with HashNode.
+type MMR struct {
+	nodes []Bytes32
+}
+
+// MMRProof proves that the leaf at Pos is committed by an MMR of Size nodes.
+// Siblings lead from the leaf to its mountain's peak; Peaks holds the other
+// mountains' peaks from left to right.
+type MMRProof struct {
+	Size     uint64   `json:"size"`
+	Pos      uint64   `json:"pos"`
+	Siblings []string `json:"siblings"`
+	Peaks    []string `json:"peaks"`
+}
+
+// MMRData is the serialized form of an MMR: every node in position order.
+type MMRData struct {
+	Format string   `json:"format"`
+	Nodes  []string `json:"nodes"`
+}
+
+// NewMMR creates an empty MMR.
+func NewMMR() *MMR { return &MMR{} }
+
+// Append adds leaf and returns its position.
+func (m *MMR) Append(leaf Bytes32) uint64 {
+	pos := uint64(len(m.nodes))
+	m.nodes = append(m.nodes, leaf)
+	for h, next := 0, pos+1; mmrHeight(next) > h; h, next = h+1, next+1 {
+		left := next - 2<<h
+		right := next - 1
+		m.nodes = append(m.nodes, HashNode(m.nodes[left], m.nodes[right]))
+	}
+	return pos
+}
+
+// LoadMMR loads an MMR from serialized data, checking that every internal
+// node matches its children.
+func LoadMMR(data MMRData) (*MMR, error) {
+	if data.Format != FormatMMRV1 {
+		return nil, ErrInvalidFormat
+	}
+	if _, err := mmrPeaks(uint64(len(data.Nodes))); err != nil {
+		return nil, err
+	}
+	m := &MMR{nodes: make([]Bytes32, 0, len(data.Nodes))}
+	for pos, s := range data.Nodes {
+		b, err := HexToBytes32(s)
+		if err != nil {
+			return nil, err
+		}
+		if h := mmrHeight(uint64(pos)); h > 0 && b != HashNode(m.nodes[pos-1<<h], m.nodes[pos-1]) {
+			return nil, ErrInvariant
+		}
+		m.nodes = append(m.nodes, b)
+	}
+	return m, nil
+}
+
+// Dump serializes the MMR.
+func (m *MMR) Dump() MMRData {
+	nodes := make([]string, len(m.nodes))
+	for i, n := range m.nodes {
+		nodes[i] = n.Hex()
+	}
+	return MMRData{Format: FormatMMRV1, Nodes: nodes}
+}
+
+// Size returns the number of nodes, leaves and internal, in the MMR.
+func (m *MMR) Size() uint64 { return uint64(len(m.nodes)) }
+
+// Root bags the peaks from right to left into a single hash, or returns the
+// zero hash for an empty MMR.
+func (m *MMR) Root() Bytes32 {
+	peaks, _ := mmrPeaks(m.Size())
+	hashes := make([]Bytes32, len(peaks))
+	for i, p := range peaks {
+		hashes[i] = m.nodes[p]
+	}
+	return bagPeaks(hashes)
+}
+
+// Prove returns an inclusion proof for the leaf at pos.
+func (m *MMR) Prove(pos uint64) (MMRProof, error) {
+	if pos >= m.Size() {
+		return MMRProof{}, ErrIndexOutOfBounds
+	}
+	if mmrHeight(pos) != 0 {
+		return MMRProof{}, ErrNotALeaf
+	}
+	peaks, _ := mmrPeaks(m.Size())
+	proof := MMRProof{Size: m.Size(), Pos: pos, Siblings: []string{}, Peaks: []string{}}
+
+	cur := pos
+	for h := 0; !containsPos(peaks, cur); h++ {
+		if mmrHeight(cur+1) > h {
+			proof.Siblings = append(proof.Siblings, m.nodes[cur-(2<<h-1)].Hex())
+			cur++
+		} else {
+			proof.Siblings = append(proof.Siblings, m.nodes[cur+(2<<h-1)].Hex())
+			cur += 2 << h
+		}
+	}
+	for _, p := range peaks {
+		if p != cur {
+			proof.Peaks = append(proof.Peaks, m.nodes[p].Hex())
+		}
+	}
+	return proof, nil
+}
+
+// GetProof is an alias of Prove.
+func (m *MMR) GetProof(pos uint64) (MMRProof, error) { return m.Prove(pos) }
+
+// VerifyMMR checks that proof links leaf to root. The proof's shape must
+// match the MMR size it claims.
+func VerifyMMR(root, leaf Bytes32, proof MMRProof) (bool, error) {
+	peaks, err := mmrPeaks(proof.Size)
+	if err != nil {
+		return false, err
+	}
+	if proof.Pos >= proof.Size {
+		return false, ErrIndexOutOfBounds
+	}
+	if mmrHeight(proof.Pos) != 0 {
+		return false, ErrNotALeaf
+	}
+
+	// Find the mountain holding pos: mountains occupy consecutive ranges
+	// ending at their peaks.
+	k := 0
+	for peaks[k] < proof.Pos {
+		k++
+	}
+	height := mmrHeight(peaks[k])
+	if len(proof.Siblings) != height || len(proof.Peaks) != len(peaks)-1 {
+		return false, ErrInvariant
+	}
+
+	cur := leaf
+	for _, s := range proof.Siblings {
+		b, err := HexToBytes32(s)
+		if err != nil {
+			return false, err
+		}
+		cur = HashNode(cur, b)
+	}
+	hashes := make([]Bytes32, 0, len(peaks))
+	for i, p := range proof.Peaks {
+		if i == k {
+			hashes = append(hashes, cur)
+		}
+		b, err := HexToBytes32(p)
+		if err != nil {
+			return false, err
+		}
+		hashes = append(hashes, b)
+	}
+	if k == len(proof.Peaks) {
+		hashes = append(hashes, cur)
+	}
+	return bagPeaks(hashes) == root, nil
+}
+
+func bagPeaks(peaks []Bytes32) Bytes32 {
+	if len(peaks) == 0 {
+		return Bytes32{}
+	}
+	acc := peaks[len(peaks)-1]
+	for i := len(peaks) - 2; i >= 0; i-- {
+		acc = HashNode(peaks[i], acc)
+	}
+	return acc
+}
+
+// mmrHeight returns the height of the node at pos, 0 for leaves.
+func mmrHeight(pos uint64) int {
+	pos++
+	for pos&(pos+1) != 0 {
+		pos -= 1<<(bits.Len64(pos)-1) - 1
+	}
+	return bits.Len64(pos) - 1
+}
+
+// mmrPeaks returns the peak positions of an MMR of size nodes, left to right,
+// or ErrInvalidMMRSize if no MMR has that many nodes. Sizes whose peaks
+// would not fit in uint64 positions are rejected too.
+func mmrPeaks(size uint64) ([]uint64, error) {
+	var peaks []uint64
+	var offset uint64
+	prev := 64
+	for remaining := size; remaining > 0; {
+		h := bits.Len64(remaining+1) - 2
+		if h < 0 || h >= prev {
+			return nil, ErrInvalidMMRSize
+		}
+		n := uint64(1)<<(h+1) - 1
+		peaks = append(peaks, offset+n-1)
+		offset += n
+		remaining -= n
+		prev = h
+	}
+	return peaks, nil
+}
+
+func containsPos(ps []uint64, p uint64) bool {
+	for _, x := range ps {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package gomerk_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestMMRHandComputed(t *testing.T) {
+	l := simpleLeaves(5)
+	m := gomerk.NewMMR()
+	for i, want := range []uint64{0, 1, 3, 4, 7} {
+		if pos := m.Append(l[i]); pos != want {
+			t.Errorf("leaf %d at pos %d, want %d", i, pos, want)
+		}
+	}
+	if m.Size() != 8 {
+		t.Fatalf("size = %d, want 8", m.Size())
+	}
+
+	// Positions 0..7: l0 l1 n2 l2 l3 n5 n6 l4, with peaks n6 and l4.
+	n2 := gomerk.HashNode(l[0], l[1])
+	n5 := gomerk.HashNode(l[2], l[3])
+	n6 := gomerk.HashNode(n2, n5)
+	if want := gomerk.HashNode(n6, l[4]); m.Root() != want {
+		t.Errorf("root = %s, want %s", m.Root(), want)
+	}
+
+	proof, err := m.Prove(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSibs := []string{l[3].Hex(), n2.Hex()}
+	if len(proof.Siblings) != 2 || proof.Siblings[0] != wantSibs[0] || proof.Siblings[1] != wantSibs[1] {
+		t.Errorf("siblings = %v, want %v", proof.Siblings, wantSibs)
+	}
+	if len(proof.Peaks) != 1 || proof.Peaks[0] != l[4].Hex() {
+		t.Errorf("peaks = %v, want [%s]", proof.Peaks, l[4].Hex())
+	}
+
+	last, _ := m.Prove(7)
+	if len(last.Siblings) != 0 || len(last.Peaks) != 1 || last.Peaks[0] != n6.Hex() {
+		t.Errorf("unexpected proof for lone peak: %+v", last)
+	}
+}
+
+func TestMMRProveVerify(t *testing.T) {
+	leaves := simpleLeaves(40)
+	m := gomerk.NewMMR()
+	if m.Root() != (gomerk.Bytes32{}) {
+		t.Error("empty MMR root should be zero")
+	}
+	var positions []uint64
+	for i, leaf := range leaves {
+		positions = append(positions, m.Append(leaf))
+		root := m.Root()
+		for j := 0; j <= i; j++ {
+			proof, err := m.Prove(positions[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := gomerk.VerifyMMR(root, leaves[j], proof)
+			if err != nil || !ok {
+				t.Fatalf("n=%d leaf %d: got (%v, %v), want (true, nil)", i+1, j, ok, err)
+			}
+			if ok, _ := gomerk.VerifyMMR(root, leaves[(j+1)%len(leaves)], proof); ok {
+				t.Fatalf("n=%d leaf %d: proof accepted for another leaf", i+1, j)
+			}
+		}
+	}
+}
+
+func TestMMRErrors(t *testing.T) {
+	m := gomerk.NewMMR()
+	for _, leaf := range simpleLeaves(4) {
+		m.Append(leaf)
+	}
+	if _, err := m.Prove(2); err != gomerk.ErrNotALeaf {
+		t.Errorf("got %v, want ErrNotALeaf", err)
+	}
+	if _, err := m.Prove(7); err != gomerk.ErrIndexOutOfBounds {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+
+	proof, _ := m.Prove(0)
+	bad := proof
+	bad.Size = 5
+	if _, err := gomerk.VerifyMMR(m.Root(), simpleLeaves(1)[0], bad); err != gomerk.ErrInvalidMMRSize {
+		t.Errorf("got %v, want ErrInvalidMMRSize", err)
+	}
+	for _, size := range []uint64{math.MaxUint64, math.MaxUint64 - 1} {
+		if _, err := gomerk.VerifyMMR(gomerk.Bytes32{}, gomerk.Bytes32{}, gomerk.MMRProof{Size: size}); err != gomerk.ErrInvalidMMRSize {
+			t.Errorf("size %d: got %v, want ErrInvalidMMRSize", size, err)
+		}
+	}
+	bad = proof
+	bad.Siblings = bad.Siblings[:1]
+	if _, err := gomerk.VerifyMMR(m.Root(), simpleLeaves(1)[0], bad); err != gomerk.ErrInvariant {
+		t.Errorf("got %v, want ErrInvariant", err)
+	}
+}
+
+func TestMMRDumpLoad(t *testing.T) {
+	m := gomerk.NewMMR()
+	for _, leaf := range simpleLeaves(11) {
+		m.Append(leaf)
+	}
+	data := m.Dump()
+	if data.Format != gomerk.FormatMMRV1 {
+		t.Errorf("format = %q", data.Format)
+	}
+	loaded, err := gomerk.LoadMMR(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Root() != m.Root() || loaded.Size() != m.Size() {
+		t.Error("loaded MMR differs")
+	}
+	extra := simpleLeaves(12)[11]
+	m.Append(extra)
+	loaded.Append(extra)
+	if loaded.Root() != m.Root() {
+		t.Error("loaded MMR diverges after append")
+	}
+	p1, _ := m.GetProof(0)
+	p2, _ := loaded.Prove(0)
+	if ok, err := gomerk.VerifyMMR(loaded.Root(), simpleLeaves(1)[0], p1); err != nil || !ok || len(p1.Siblings) != len(p2.Siblings) {
+		t.Errorf("proof from loaded MMR: got (%v, %v)", ok, err)
+	}
+
+	tampered := m.Dump()
+	tampered.Nodes[2] = tampered.Nodes[0]
+	if _, err := gomerk.LoadMMR(tampered); err != gomerk.ErrInvariant {
+		t.Errorf("got %v, want ErrInvariant", err)
+	}
+	short := m.Dump()
+	short.Nodes = short.Nodes[:2]
+	if _, err := gomerk.LoadMMR(short); err != gomerk.ErrInvalidMMRSize {
+		t.Errorf("got %v, want ErrInvalidMMRSize", err)
+	}
+	if _, err := gomerk.LoadMMR(gomerk.MMRData{Format: "bad"}); err != gomerk.ErrInvalidFormat {
+		t.Errorf("got %v, want ErrInvalidFormat", err)
+	}
+}
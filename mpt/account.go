@@ -0,0 +1,173 @@
+package mpt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/pyroth/gomerk"
+)
+
+// EmptyCodeHash is the code hash of accounts without code, keccak256("").
+var EmptyCodeHash = gomerk.Keccak256(nil)
+
+// Account is the state of an Ethereum account.
+type Account struct {
+	Nonce       uint64
+	Balance     *big.Int
+	StorageRoot gomerk.Bytes32
+	CodeHash    gomerk.Bytes32
+}
+
+// AccountProof is the result of eth_getProof.
+type AccountProof struct {
+	Address      string         `json:"address"`
+	AccountProof []string       `json:"accountProof"`
+	Balance      string         `json:"balance"`
+	CodeHash     string         `json:"codeHash"`
+	Nonce        string         `json:"nonce"`
+	StorageHash  string         `json:"storageHash"`
+	StorageProof []StorageProof `json:"storageProof"`
+}
+
+// StorageProof is the proof of one storage slot in an eth_getProof result.
+type StorageProof struct {
+	Key   string   `json:"key"`
+	Value string   `json:"value"`
+	Proof []string `json:"proof"`
+}
+
+// VerifyAccount returns the account at a hex address in the state trie with
+// the given root, or nil if the proof shows that it does not exist.
+func VerifyAccount(stateRoot gomerk.Bytes32, address string, proof []string) (*Account, error) {
+	addr, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil || len(addr) != 20 {
+		return nil, gomerk.ErrInvalidAddress
+	}
+	nodes, err := decodeHexList(proof)
+	if err != nil {
+		return nil, err
+	}
+	key := gomerk.Keccak256(addr)
+	enc, err := VerifyProof(stateRoot, key[:], nodes)
+	if err != nil || enc == nil {
+		return nil, err
+	}
+	it, err := decodeRLP(enc)
+	if err != nil {
+		return nil, err
+	}
+	if !it.isList || len(it.list) != 4 {
+		return nil, fmt.Errorf("%w: account is not a 4-item list", gomerk.ErrInvalidProof)
+	}
+	f := it.list
+	for _, x := range f {
+		if x.isList {
+			return nil, fmt.Errorf("%w: account field is a list", gomerk.ErrInvalidProof)
+		}
+	}
+	if len(f[0].str) > 8 || len(f[2].str) != 32 || len(f[3].str) != 32 {
+		return nil, fmt.Errorf("%w: malformed account", gomerk.ErrInvalidProof)
+	}
+	return &Account{
+		Nonce:       new(big.Int).SetBytes(f[0].str).Uint64(),
+		Balance:     new(big.Int).SetBytes(f[1].str),
+		StorageRoot: gomerk.Bytes32(f[2].str),
+		CodeHash:    gomerk.Bytes32(f[3].str),
+	}, nil
+}
+
+// VerifyStorage returns the value of a storage slot in the storage trie with
+// the given root, zero if the proof shows that the slot is empty.
+func VerifyStorage(storageRoot, slot gomerk.Bytes32, proof []string) (*big.Int, error) {
+	nodes, err := decodeHexList(proof)
+	if err != nil {
+		return nil, err
+	}
+	key := gomerk.Keccak256(slot[:])
+	enc, err := VerifyProof(storageRoot, key[:], nodes)
+	if err != nil || enc == nil {
+		return new(big.Int), err
+	}
+	it, err := decodeRLP(enc)
+	if err != nil {
+		return nil, err
+	}
+	if it.isList || len(it.str) > 32 || len(it.str) > 0 && it.str[0] == 0 {
+		return nil, fmt.Errorf("%w: malformed storage value", gomerk.ErrInvalidProof)
+	}
+	return new(big.Int).SetBytes(it.str), nil
+}
+
+// VerifyAccountProof checks an eth_getProof result against a state root:
+// that the account and every storage slot hold the values it claims. A
+// missing account must be claimed with zero nonce and balance, the empty
+// storage root and the empty code hash, as clients report it.
+func VerifyAccountProof(stateRoot gomerk.Bytes32, p *AccountProof) error {
+	acc, err := VerifyAccount(stateRoot, p.Address, p.AccountProof)
+	if err != nil {
+		return fmt.Errorf("account %s: %w", p.Address, err)
+	}
+	if acc == nil {
+		acc = &Account{Balance: new(big.Int), StorageRoot: EmptyRoot, CodeHash: EmptyCodeHash}
+	}
+	nonce, err := parseQuantity(p.Nonce)
+	if err != nil {
+		return fmt.Errorf("nonce: %w", err)
+	}
+	balance, err := parseQuantity(p.Balance)
+	if err != nil {
+		return fmt.Errorf("balance: %w", err)
+	}
+	storageHash, err := gomerk.HexToBytes32(p.StorageHash)
+	if err != nil {
+		return fmt.Errorf("storageHash: %w", err)
+	}
+	codeHash, err := gomerk.HexToBytes32(p.CodeHash)
+	if err != nil {
+		return fmt.Errorf("codeHash: %w", err)
+	}
+	switch {
+	case !nonce.IsUint64() || nonce.Uint64() != acc.Nonce:
+		return fmt.Errorf("%w: nonce %s, proven %d", gomerk.ErrInvalidProof, p.Nonce, acc.Nonce)
+	case balance.Cmp(acc.Balance) != 0:
+		return fmt.Errorf("%w: balance %s, proven %#x", gomerk.ErrInvalidProof, p.Balance, acc.Balance)
+	case storageHash != acc.StorageRoot:
+		return fmt.Errorf("%w: storageHash %s, proven %s", gomerk.ErrInvalidProof, p.StorageHash, acc.StorageRoot)
+	case codeHash != acc.CodeHash:
+		return fmt.Errorf("%w: codeHash %s, proven %s", gomerk.ErrInvalidProof, p.CodeHash, acc.CodeHash)
+	}
+
+	for _, s := range p.StorageProof {
+		k, err := parseQuantity(s.Key)
+		if err != nil || k.BitLen() > 256 {
+			return fmt.Errorf("storage key %q: %w", s.Key, gomerk.ErrInvalidHex)
+		}
+		want, err := parseQuantity(s.Value)
+		if err != nil {
+			return fmt.Errorf("storage %s value: %w", s.Key, err)
+		}
+		var slot gomerk.Bytes32
+		k.FillBytes(slot[:])
+		got, err := VerifyStorage(acc.StorageRoot, slot, s.Proof)
+		if err != nil {
+			return fmt.Errorf("storage %s: %w", s.Key, err)
+		}
+		if got.Cmp(want) != 0 {
+			return fmt.Errorf("%w: storage %s holds %s, proven %#x", gomerk.ErrInvalidProof, s.Key, s.Value, got)
+		}
+	}
+	return nil
+}
+
+// parseQuantity parses a 0x-prefixed hex number. Storage keys may be given
+// as full 32-byte words, so leading zeros are accepted.
+func parseQuantity(s string) (*big.Int, error) {
+	digits, ok := strings.CutPrefix(s, "0x")
+	n, valid := new(big.Int).SetString(digits, 16)
+	if !ok || !valid {
+		return nil, gomerk.ErrInvalidHex
+	}
+	return n, nil
+}
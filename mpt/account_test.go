@@ -0,0 +1,94 @@
+package mpt_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/mpt"
+)
+
+// getproof.json holds eth_getProof results from a go-ethereum state with 40
+// accounts, one of them a contract with 21 storage slots.
+type fixture struct {
+	StateRoot gomerk.Bytes32   `json:"-"`
+	Root      string           `json:"stateRoot"`
+	Contract  mpt.AccountProof `json:"contract"`
+	EOA       mpt.AccountProof `json:"eoa"`
+	Missing   mpt.AccountProof `json:"missing"`
+}
+
+func loadFixture(t *testing.T) *fixture {
+	t.Helper()
+	b, err := os.ReadFile("testdata/getproof.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f fixture
+	if err := json.Unmarshal(b, &f); err != nil {
+		t.Fatal(err)
+	}
+	f.StateRoot = gomerk.MustHexToBytes32(f.Root)
+	return &f
+}
+
+func TestVerifyAccountProof(t *testing.T) {
+	f := loadFixture(t)
+	for _, p := range []*mpt.AccountProof{&f.Contract, &f.EOA, &f.Missing} {
+		if err := mpt.VerifyAccountProof(f.StateRoot, p); err != nil {
+			t.Fatalf("%s: %v", p.Address, err)
+		}
+	}
+
+	acc, err := mpt.VerifyAccount(f.StateRoot, f.EOA.Address, f.EOA.AccountProof)
+	if err != nil || acc.Nonce != 18 || acc.Balance.String() != "18000000000000000" || acc.StorageRoot != mpt.EmptyRoot || acc.CodeHash != mpt.EmptyCodeHash {
+		t.Fatalf("account = %+v, %v", acc, err)
+	}
+	if acc, err := mpt.VerifyAccount(f.StateRoot, f.Missing.Address, f.Missing.AccountProof); acc != nil || err != nil {
+		t.Fatalf("missing account = %+v, %v", acc, err)
+	}
+	// A proof of one account does not prove another.
+	if _, err := mpt.VerifyAccount(f.StateRoot, f.EOA.Address, f.Contract.AccountProof); !errors.Is(err, gomerk.ErrInvalidProof) {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyAccountProofMismatch(t *testing.T) {
+	for name, mutate := range map[string]func(*fixture){
+		"nonce":        func(f *fixture) { f.Contract.Nonce = "0x6" },
+		"balance":      func(f *fixture) { f.Contract.Balance = "0x0" },
+		"storage hash": func(f *fixture) { f.Contract.StorageHash = mpt.EmptyRoot.Hex() },
+		"code hash":    func(f *fixture) { f.Contract.CodeHash = mpt.EmptyCodeHash.Hex() },
+		"slot value":   func(f *fixture) { f.Contract.StorageProof[1].Value = "0xb" },
+		"empty slot":   func(f *fixture) { f.Contract.StorageProof[4].Value = "0x1" },
+		"slot key":     func(f *fixture) { f.Contract.StorageProof[0].Key = "0x3" },
+		"state root":   func(f *fixture) { f.StateRoot[0] ^= 1 },
+		"missing node": func(f *fixture) { f.Contract.AccountProof = f.Contract.AccountProof[:2] },
+		"missing balance": func(f *fixture) {
+			f.Contract = f.Missing
+			f.Contract.Balance = "0x1"
+		},
+	} {
+		f := loadFixture(t)
+		mutate(f)
+		if err := mpt.VerifyAccountProof(f.StateRoot, &f.Contract); !errors.Is(err, gomerk.ErrInvalidProof) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestVerifyStorage(t *testing.T) {
+	f := loadFixture(t)
+	root := gomerk.MustHexToBytes32(f.Contract.StorageHash)
+	var slot gomerk.Bytes32
+	slot[30], slot[31] = 0x03, 0xe8
+	v, err := mpt.VerifyStorage(root, slot, f.Contract.StorageProof[3].Proof)
+	if err != nil || v.BitLen() != 256 {
+		t.Fatal(v, err)
+	}
+	if v, err := mpt.VerifyStorage(mpt.EmptyRoot, slot, nil); err != nil || v.Sign() != 0 {
+		t.Fatal(v, err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package mpt verifies Merkle Patricia Trie proofs, such as the account and
+// storage proofs returned by Ethereum's eth_getProof (EIP-1186).
+package mpt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pyroth/gomerk"
+)
+
+// EmptyRoot is the root of the empty trie, keccak256(rlp("")).
+var EmptyRoot = gomerk.Keccak256([]byte{0x80})
+
+// VerifyProof returns the value stored under key in the trie with the given
+// root, or nil if the proof shows that key is absent. proof holds the
+// RLP-encoded nodes on key's path; they are looked up by hash, so their
+// order does not matter and unused nodes are ignored. Ethereum's state and
+// storage tries are keyed by the keccak256 of the address or slot.
+func VerifyProof(root gomerk.Bytes32, key []byte, proof [][]byte) ([]byte, error) {
+	nodes := make(map[gomerk.Bytes32][]byte, len(proof))
+	for _, n := range proof {
+		nodes[gomerk.Keccak256(n)] = n
+	}
+	if root == EmptyRoot {
+		return nil, nil
+	}
+	path := make([]byte, 0, 2*len(key))
+	for _, b := range key {
+		path = append(path, b>>4, b&0xf)
+	}
+
+	ref := item{str: root[:]}
+	for {
+		n := ref
+		if !ref.isList {
+			if len(ref.str) == 0 {
+				return nil, nil
+			}
+			if len(ref.str) != 32 {
+				return nil, fmt.Errorf("%w: %d byte node reference", gomerk.ErrInvalidProof, len(ref.str))
+			}
+			enc, ok := nodes[gomerk.Bytes32(ref.str)]
+			if !ok {
+				return nil, fmt.Errorf("%w: missing node 0x%x", gomerk.ErrInvalidProof, ref.str)
+			}
+			var err error
+			if n, err = decodeRLP(enc); err != nil {
+				return nil, err
+			}
+		}
+		if !n.isList {
+			return nil, fmt.Errorf("%w: node is not a list", gomerk.ErrInvalidProof)
+		}
+
+		switch len(n.list) {
+		case 17:
+			if len(path) == 0 {
+				return value(n.list[16])
+			}
+			ref, path = n.list[path[0]], path[1:]
+		case 2:
+			if n.list[0].isList {
+				return nil, fmt.Errorf("%w: node path is a list", gomerk.ErrInvalidProof)
+			}
+			prefix, leaf, err := decodeHexPrefix(n.list[0].str)
+			if err != nil {
+				return nil, err
+			}
+			if leaf {
+				if !bytes.Equal(prefix, path) {
+					return nil, nil
+				}
+				return value(n.list[1])
+			}
+			if !bytes.HasPrefix(path, prefix) {
+				return nil, nil
+			}
+			ref, path = n.list[1], path[len(prefix):]
+		default:
+			return nil, fmt.Errorf("%w: node has %d items", gomerk.ErrInvalidProof, len(n.list))
+		}
+	}
+}
+
+// value returns a stored value, nil if it is empty.
+func value(it item) ([]byte, error) {
+	if it.isList {
+		return nil, fmt.Errorf("%w: value is a list", gomerk.ErrInvalidProof)
+	}
+	if len(it.str) == 0 {
+		return nil, nil
+	}
+	return it.str, nil
+}
+
+// decodeHexPrefix decodes the compact path encoding of leaf and extension
+// nodes into nibbles.
+func decodeHexPrefix(b []byte) (nibbles []byte, leaf bool, err error) {
+	if len(b) == 0 || b[0]>>4 > 3 || b[0]&0x10 == 0 && b[0]&0xf != 0 {
+		return nil, false, fmt.Errorf("%w: bad hex-prefix path", gomerk.ErrInvalidProof)
+	}
+	if b[0]&0x10 != 0 {
+		nibbles = append(nibbles, b[0]&0xf)
+	}
+	for _, c := range b[1:] {
+		nibbles = append(nibbles, c>>4, c&0xf)
+	}
+	return nibbles, b[0]&0x20 != 0, nil
+}
+
+// decodeHexList decodes 0x-prefixed hex strings such as the nodes of an
+// eth_getProof response.
+func decodeHexList(list []string) ([][]byte, error) {
+	out := make([][]byte, len(list))
+	for i, s := range list {
+		b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, gomerk.ErrInvalidHex
+		}
+		out[i] = b
+	}
+	return out, nil
+}
@@ -0,0 +1,130 @@
+package mpt_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/mpt"
+)
+
+// rlpString and rlpList encode the short RLP items these tests need.
+func rlpString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	if len(b) < 56 {
+		return append([]byte{0x80 + byte(len(b))}, b...)
+	}
+	return append([]byte{0xb8, byte(len(b))}, b...)
+}
+
+func rlpList(items ...[]byte) []byte {
+	payload := bytes.Join(items, nil)
+	if len(payload) < 56 {
+		return append([]byte{0xc0 + byte(len(payload))}, payload...)
+	}
+	return append([]byte{0xf8, byte(len(payload))}, payload...)
+}
+
+// branch returns a branch node with the given children, which are encoded
+// nodes, embedded if shorter than 32 bytes and referenced by hash otherwise.
+func branch(children map[int][]byte) []byte {
+	items := make([][]byte, 17)
+	for i := range items {
+		items[i] = rlpString(nil)
+		if c, ok := children[i]; ok {
+			items[i] = ref(c)
+		}
+	}
+	return rlpList(items...)
+}
+
+func ref(node []byte) []byte {
+	if len(node) < 32 {
+		return node
+	}
+	h := gomerk.Keccak256(node)
+	return rlpString(h[:])
+}
+
+// testTrie maps 0x12, 0x13, 0x2345, 0x4567 and 0x4568 to values, with
+// embedded branch, leaf and extension nodes.
+func testTrie() (root gomerk.Bytes32, proof [][]byte, long []byte) {
+	long = bytes.Repeat([]byte{0xab}, 40)
+	leaf := func(path, v []byte) []byte { return rlpList(rlpString(path), rlpString(v)) }
+	a := branch(map[int][]byte{2: leaf([]byte{0x20}, []byte("a")), 3: leaf([]byte{0x20}, []byte("b"))})
+	b := leaf([]byte{0x33, 0x45}, long)
+	c := rlpList(rlpString([]byte{0x00, 0x56}), branch(map[int][]byte{
+		7: leaf([]byte{0x20}, []byte("x")),
+		8: leaf([]byte{0x20}, []byte("y")),
+	}))
+	top := branch(map[int][]byte{1: a, 2: b, 4: c})
+	return gomerk.Keccak256(top), [][]byte{top, b}, long
+}
+
+func TestVerifyProof(t *testing.T) {
+	root, proof, long := testTrie()
+	for _, tc := range []struct {
+		key  []byte
+		want []byte
+	}{
+		{[]byte{0x12}, []byte("a")},
+		{[]byte{0x13}, []byte("b")},
+		{[]byte{0x23, 0x45}, long},
+		{[]byte{0x45, 0x67}, []byte("x")},
+		{[]byte{0x45, 0x68}, []byte("y")},
+		{[]byte{0x14}, nil},
+		{[]byte{0x23, 0x46}, nil},
+		{[]byte{0x45, 0x77}, nil},
+		{[]byte{0x45, 0x69}, nil},
+		{[]byte{0x30}, nil},
+	} {
+		got, err := mpt.VerifyProof(root, tc.key, proof)
+		if err != nil || !bytes.Equal(got, tc.want) {
+			t.Errorf("key %x = %x, %v, want %x", tc.key, got, err, tc.want)
+		}
+	}
+
+	// Order does not matter, and absence in an empty trie needs no nodes.
+	if got, err := mpt.VerifyProof(root, []byte{0x23, 0x45}, [][]byte{proof[1], proof[0]}); err != nil || !bytes.Equal(got, long) {
+		t.Fatal(got, err)
+	}
+	if got, err := mpt.VerifyProof(mpt.EmptyRoot, []byte{0x12}, nil); got != nil || err != nil {
+		t.Fatal(got, err)
+	}
+}
+
+func TestVerifyProofInvalid(t *testing.T) {
+	root, proof, _ := testTrie()
+	if _, err := mpt.VerifyProof(root, []byte{0x23, 0x45}, proof[:1]); !errors.Is(err, gomerk.ErrInvalidProof) {
+		t.Fatal(err)
+	}
+	changed := bytes.Clone(proof[1])
+	changed[len(changed)-1] ^= 1
+	if _, err := mpt.VerifyProof(root, []byte{0x23, 0x45}, [][]byte{proof[0], changed}); !errors.Is(err, gomerk.ErrInvalidProof) {
+		t.Fatal(err)
+	}
+
+	for _, node := range [][]byte{
+		{0xc3, 0x01},                // truncated list
+		{0x81, 0x05},                // single byte as a string
+		{0xb8, 0x05, 1, 2, 3, 4, 5}, // long form for a short string
+		{0xc1, 0x01, 0x02},          // trailing bytes
+	} {
+		if _, err := mpt.VerifyProof(gomerk.Keccak256(node), []byte{1}, [][]byte{node}); !errors.Is(err, mpt.ErrInvalidRLP) {
+			t.Errorf("node %x: %v", node, err)
+		}
+	}
+	for _, node := range [][]byte{
+		rlpString([]byte("not a list")),
+		rlpList(rlpString(nil), rlpString(nil), rlpString(nil)),
+		rlpList(rlpString([]byte{0x40}), rlpString([]byte("v"))), // bad path flag
+		rlpList(rlpString([]byte{0x01}), rlpString([]byte("v"))), // even path with a nibble
+	} {
+		if _, err := mpt.VerifyProof(gomerk.Keccak256(node), []byte{1}, [][]byte{node}); !errors.Is(err, gomerk.ErrInvalidProof) {
+			t.Errorf("node %x: %v", node, err)
+		}
+	}
+}
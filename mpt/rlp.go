@@ -0,0 +1,94 @@
+package mpt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRLP is returned for malformed or non-canonical RLP.
+var ErrInvalidRLP = errors.New("invalid rlp")
+
+// item is a decoded RLP item: a byte string, or a list if isList is set.
+type item struct {
+	isList bool
+	str    []byte
+	list   []item
+}
+
+// decodeRLP decodes b, which must hold exactly one item.
+func decodeRLP(b []byte) (item, error) {
+	it, rest, err := decodeItem(b)
+	if err != nil {
+		return item{}, err
+	}
+	if len(rest) > 0 {
+		return item{}, fmt.Errorf("%w: %d trailing bytes", ErrInvalidRLP, len(rest))
+	}
+	return it, nil
+}
+
+func decodeItem(b []byte) (item, []byte, error) {
+	if len(b) == 0 {
+		return item{}, nil, fmt.Errorf("%w: unexpected end", ErrInvalidRLP)
+	}
+	isList, size, header, err := itemHeader(b)
+	if err != nil {
+		return item{}, nil, err
+	}
+	payload, rest := b[header:header+size], b[header+size:]
+	if !isList {
+		return item{str: payload}, rest, nil
+	}
+	it := item{isList: true, list: []item{}}
+	for len(payload) > 0 {
+		var e item
+		if e, payload, err = decodeItem(payload); err != nil {
+			return item{}, nil, err
+		}
+		it.list = append(it.list, e)
+	}
+	return it, rest, nil
+}
+
+// itemHeader returns the kind and payload size of the item at the start of
+// b, and the length of its header.
+func itemHeader(b []byte) (isList bool, size, header int, err error) {
+	p := b[0]
+	switch {
+	case p < 0x80:
+		return false, 1, 0, nil
+	case p < 0xb8:
+		size, header = int(p-0x80), 1
+		if size == 1 && len(b) > 1 && b[1] < 0x80 {
+			return false, 0, 0, fmt.Errorf("%w: single byte as string", ErrInvalidRLP)
+		}
+	case p < 0xc0:
+		size, header, err = longSize(b, int(p-0xb7))
+	case p < 0xf8:
+		isList, size, header = true, int(p-0xc0), 1
+	default:
+		isList = true
+		size, header, err = longSize(b, int(p-0xf7))
+	}
+	if err == nil && len(b)-header < size {
+		err = fmt.Errorf("%w: unexpected end", ErrInvalidRLP)
+	}
+	return isList, size, header, err
+}
+
+// longSize reads the n-byte big-endian payload size after the prefix byte.
+func longSize(b []byte, n int) (size, header int, err error) {
+	if len(b) < 1+n || n > 4 {
+		return 0, 0, fmt.Errorf("%w: bad length", ErrInvalidRLP)
+	}
+	if b[1] == 0 {
+		return 0, 0, fmt.Errorf("%w: length has leading zeros", ErrInvalidRLP)
+	}
+	for _, c := range b[1 : 1+n] {
+		size = size<<8 | int(c)
+	}
+	if size < 56 {
+		return 0, 0, fmt.Errorf("%w: long form for short payload", ErrInvalidRLP)
+	}
+	return size, 1 + n, nil
+}
@@ -0,0 +1,97 @@
+package gomerk
+
+// GetNonInclusionProof returns a proof that value is not in the tree. The
+// tree must be sorted and use a positional hasher, and it returns
+// ErrLeafInTree if value is present. The proof is a RangeProof of the empty
+// range at value's leaf hash, holding the two leaves around it at
+// consecutive positions, or the tree's first or last leaf alone.
+func (t *StandardMerkleTree) GetNonInclusionProof(value []any) (*RangeProof, error) {
+	if !t.sortLeaves {
+		return nil, ErrTreeNotSorted
+	}
+	if commutative(t.opts.hash()) {
+		return nil, ErrNotPositional
+	}
+	h, err := t.LeafHash(value)
+	if err != nil {
+		return nil, err
+	}
+	if t.hashIndex(h) >= 0 {
+		return nil, ErrLeafInTree
+	}
+	return getRangeProof(t.tree, len(t.values), h, h), nil
+}
+
+// GetNonInclusionProof returns a proof that leaf is not in the tree. The
+// tree must be sorted and use a positional hasher, and it returns
+// ErrLeafInTree if leaf is present.
+func (t *SimpleMerkleTree) GetNonInclusionProof(leaf Bytes32) (*RangeProof, error) {
+	if !t.sorted {
+		return nil, ErrTreeNotSorted
+	}
+	if commutative(t.opts.hash()) {
+		return nil, ErrNotPositional
+	}
+	h := t.opts.hash().LeafHash(leaf[:])
+	if _, ok := t.index[h]; ok {
+		return nil, ErrLeafInTree
+	}
+	return getRangeProof(t.tree, len(t.values), h, h), nil
+}
+
+// VerifyNonInclusion checks that proof shows leafHash is absent from the
+// sorted tree with the given root and leaf count, hashed with
+// PositionalKeccak256Hasher.
+func VerifyNonInclusion(root string, leafCount int, leafHash Bytes32, proof *RangeProof) (bool, error) {
+	return verifyNonInclusion(PositionalKeccak256Hasher, root, leafCount, leafHash, proof)
+}
+
+// VerifySimpleNonInclusion checks a non-inclusion proof for a
+// SimpleMerkleTree leaf.
+func VerifySimpleNonInclusion(root string, leafCount int, leaf Bytes32, proof *RangeProof) (bool, error) {
+	return VerifyNonInclusion(root, leafCount, HashLeaf(leaf[:]), proof)
+}
+
+// VerifyStandardNonInclusion checks a non-inclusion proof for a
+// StandardMerkleTree value.
+func VerifyStandardNonInclusion(root string, leafCount int, leafEncoding []string, value []any, proof *RangeProof) (bool, error) {
+	h, err := leafCodec{}.encodeAndHash(leafEncoding, value)
+	if err != nil {
+		return false, err
+	}
+	return VerifyNonInclusion(root, leafCount, h, proof)
+}
+
+// VerifyNonInclusion is VerifyNonInclusion with the verifier's hasher, which
+// must be positional.
+func (v *Verifier) VerifyNonInclusion(root string, leafCount int, leafHash Bytes32, proof *RangeProof) (bool, error) {
+	return verifyNonInclusion(v.opts.hash(), root, leafCount, leafHash, proof)
+}
+
+// VerifySimpleNonInclusion checks a non-inclusion proof for a
+// SimpleMerkleTree leaf with the verifier's hasher.
+func (v *Verifier) VerifySimpleNonInclusion(root string, leafCount int, leaf Bytes32, proof *RangeProof) (bool, error) {
+	return v.VerifyNonInclusion(root, leafCount, v.opts.hash().LeafHash(leaf[:]), proof)
+}
+
+// VerifyStandardNonInclusion checks a non-inclusion proof for a
+// StandardMerkleTree value with the verifier's number encoding and hasher.
+func (v *Verifier) VerifyStandardNonInclusion(root string, leafCount int, leafEncoding []string, value []any, proof *RangeProof) (bool, error) {
+	h, err := v.opts.codec().encodeAndHash(leafEncoding, value)
+	if err != nil {
+		return false, err
+	}
+	return v.VerifyNonInclusion(root, leafCount, h, proof)
+}
+
+func verifyNonInclusion(h Hasher, root string, n int, leaf Bytes32, p *RangeProof) (bool, error) {
+	if commutative(h) {
+		return false, ErrNotPositional
+	}
+	for _, s := range p.Leaves {
+		if b, err := HexToBytes32(s); err == nil && b == leaf {
+			return false, nil
+		}
+	}
+	return verifyRangeProof(h, root, n, leaf, leaf, p)
+}
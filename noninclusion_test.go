@@ -0,0 +1,96 @@
+package gomerk_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestStandardNonInclusion(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := airdropData(40)
+	tree, _ := gomerk.NewStandardMerkleTree(values[:30], enc, true, gomerk.WithPositionalHashing())
+	for _, v := range values[30:] {
+		p, err := tree.GetNonInclusionProof(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Leaves) == 0 || len(p.Leaves) > 2 {
+			t.Errorf("proof has %d leaves", len(p.Leaves))
+		}
+		ok, err := gomerk.VerifyStandardNonInclusion(tree.Root(), tree.Len(), enc, v, p)
+		if err != nil || !ok {
+			t.Errorf("verify = %v, %v", ok, err)
+		}
+		// The proof of one absent value does not cover a present one.
+		if ok, _ := gomerk.VerifyStandardNonInclusion(tree.Root(), tree.Len(), enc, values[0], p); ok {
+			t.Error("non-inclusion proof verified for a value in the tree")
+		}
+	}
+	if _, err := tree.GetNonInclusionProof(values[3]); !errors.Is(err, gomerk.ErrLeafInTree) {
+		t.Errorf("present value: err = %v", err)
+	}
+}
+
+func TestSimpleNonInclusion(t *testing.T) {
+	leaves := simpleLeaves(12)
+	tree, _ := gomerk.BuildSimpleMerkleTree(leaves[:9], gomerk.WithPositionalHashing())
+	v := gomerk.NewVerifier(gomerk.WithPositionalHashing())
+	for _, leaf := range leaves[9:] {
+		p, err := tree.GetNonInclusionProof(leaf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := v.VerifySimpleNonInclusion(tree.Root(), 9, leaf, p); err != nil || !ok {
+			t.Errorf("verify = %v, %v", ok, err)
+		}
+		if ok, _ := gomerk.VerifySimpleNonInclusion(tree.Root(), 9, leaf, p); !ok {
+			t.Error("package-level verify failed")
+		}
+		sha := gomerk.NewVerifier(gomerk.WithHasher(gomerk.SHA256PositionalHasher))
+		if ok, _ := sha.VerifySimpleNonInclusion(tree.Root(), 9, leaf, p); ok {
+			t.Error("verified with the wrong hasher")
+		}
+	}
+
+	// A proof built for a present leaf by hand, with the leaf itself as a
+	// bracket, must not verify.
+	p, _ := tree.GetRangeProof(gomerk.Bytes32{}, gomerk.Bytes32{0xff})
+	h := gomerk.HashLeaf(leaves[0][:])
+	if ok, _ := v.VerifyNonInclusion(tree.Root(), 9, h, p); ok {
+		t.Error("present leaf proven absent")
+	}
+	if _, err := tree.GetNonInclusionProof(leaves[0]); !errors.Is(err, gomerk.ErrLeafInTree) {
+		t.Errorf("present leaf: err = %v", err)
+	}
+}
+
+func TestNonInclusionSwappedSiblings(t *testing.T) {
+	// With leaves h0 < h1 < h2 < h3, swapping h2 with its sibling h3 keeps a
+	// sorted-pair root, so h1 and h3 look adjacent and h2 looks absent.
+	for _, opts := range [][]gomerk.Option{nil, {gomerk.WithPositionalHashing()}} {
+		tree, _ := gomerk.BuildSimpleMerkleTree(simpleLeaves(4), opts...)
+		dump := tree.Dump().Tree
+		h := make([]gomerk.Bytes32, 4)
+		for i, s := range dump[len(dump)-4:] {
+			h[i], _ = gomerk.HexToBytes32(s)
+		}
+		slices.SortFunc(h, gomerk.Bytes32.Compare)
+		forged := &gomerk.RangeProof{
+			Start:  1,
+			Leaves: []string{h[1].Hex(), h[3].Hex()},
+			Proof:  []string{h[0].Hex(), h[2].Hex()},
+		}
+		if ok, err := gomerk.NewVerifier(opts...).VerifyNonInclusion(tree.Root(), 4, h[2], forged); ok || opts == nil && !errors.Is(err, gomerk.ErrNotPositional) {
+			t.Errorf("positional=%v: forged proof = %v, %v", opts != nil, ok, err)
+		}
+		if ok, _ := gomerk.VerifyNonInclusion(tree.Root(), 4, h[2], forged); ok {
+			t.Errorf("positional=%v: package-level verify accepted the forged proof", opts != nil)
+		}
+		if _, err := tree.GetNonInclusionProof(simpleLeaves(5)[4]); opts == nil && !errors.Is(err, gomerk.ErrNotPositional) {
+			t.Errorf("sorted-pair tree: err = %v", err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package onchain checks trees against roots stored in deployed contracts,
+// reading them with eth_call.
+package onchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pyroth/gomerk"
+)
+
+// ErrEmptyResult is returned when a call returns no data, as calls to
+// addresses without code or to missing functions do.
+var ErrEmptyResult = errors.New("call returned no data")
+
+// Caller executes read-only contract calls at the latest block, as eth_call
+// does. to is a hex address.
+type Caller interface {
+	CallContract(ctx context.Context, to string, data []byte) ([]byte, error)
+}
+
+// CallerFunc adapts a function to Caller. With go-ethereum's ethclient:
+//
+//	caller := onchain.CallerFunc(func(ctx context.Context, to string, data []byte) ([]byte, error) {
+//		addr := common.HexToAddress(to)
+//		return client.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: data}, nil)
+//	})
+type CallerFunc func(ctx context.Context, to string, data []byte) ([]byte, error)
+
+func (f CallerFunc) CallContract(ctx context.Context, to string, data []byte) ([]byte, error) {
+	return f(ctx, to, data)
+}
+
+// RPCCaller calls contracts through an Ethereum JSON-RPC endpoint.
+type RPCCaller struct {
+	URL string
+
+	// Block is the block tag or hex number calls run at, "latest" if empty.
+	Block string
+
+	// Client sends requests, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// NewRPCCaller returns an RPCCaller for the endpoint at url.
+func NewRPCCaller(url string) *RPCCaller { return &RPCCaller{URL: url} }
+
+// CallContract runs eth_call.
+func (c *RPCCaller) CallContract(ctx context.Context, to string, data []byte) ([]byte, error) {
+	block := c.Block
+	if block == "" {
+		block = "latest"
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_call",
+		"params":  []any{map[string]string{"to": to, "data": "0x" + hex.EncodeToString(data)}, block},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("eth_call: %s", resp.Status)
+	}
+	var out struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("eth_call: %w", err)
+	}
+	if out.Error != nil {
+		return nil, fmt.Errorf("eth_call: %s (code %d)", out.Error.Message, out.Error.Code)
+	}
+	result, err := hex.DecodeString(strings.TrimPrefix(out.Result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("eth_call: %w", gomerk.ErrInvalidHex)
+	}
+	return result, nil
+}
+
+// FetchRoot calls getter, the signature of a function taking no arguments
+// and returning bytes32 such as "merkleRoot()", on contract.
+func FetchRoot(ctx context.Context, c Caller, contract, getter string) (string, error) {
+	if !strings.HasSuffix(getter, "()") {
+		return "", fmt.Errorf("%w: getter %q takes arguments", gomerk.ErrUnsupportedType, getter)
+	}
+	if b, err := hex.DecodeString(strings.TrimPrefix(contract, "0x")); err != nil || len(b) != 20 {
+		return "", fmt.Errorf("%w: %q", gomerk.ErrInvalidAddress, contract)
+	}
+	sel := gomerk.Selector(getter)
+	result, err := c.CallContract(ctx, contract, sel[:])
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "", fmt.Errorf("%s on %s: %w", getter, contract, ErrEmptyResult)
+	}
+	if len(result) < 32 {
+		return "", fmt.Errorf("%s on %s: %w", getter, contract, gomerk.ErrInvalidNodeLength)
+	}
+	return gomerk.Bytes32(result[:32]).Hex(), nil
+}
+
+// Tree is any tree with a root, such as a StandardMerkleTree.
+type Tree interface {
+	Root() string
+}
+
+// RootReport is the result of comparing a tree's root with a deployed one.
+type RootReport struct {
+	Contract string `json:"contract"`
+	Getter   string `json:"getter"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Match    bool   `json:"match"`
+}
+
+// Err returns nil if the roots match, or an error wrapping
+// gomerk.ErrRootMismatch.
+func (r *RootReport) Err() error {
+	if r.Match {
+		return nil
+	}
+	return fmt.Errorf("%w: %s %s returned %s, expected %s", gomerk.ErrRootMismatch, r.Contract, r.Getter, r.Actual, r.Expected)
+}
+
+// CheckRoot fetches the root stored in contract with getter and compares it
+// with tree's. A mismatch is reported in the result, not as an error.
+func CheckRoot(ctx context.Context, c Caller, contract, getter string, tree Tree) (*RootReport, error) {
+	actual, err := FetchRoot(ctx, c, contract, getter)
+	if err != nil {
+		return nil, err
+	}
+	return &RootReport{
+		Contract: contract,
+		Getter:   getter,
+		Expected: tree.Root(),
+		Actual:   actual,
+		Match:    gomerk.RootsEqual(actual, tree.Root()),
+	}, nil
+}
@@ -0,0 +1,114 @@
+package onchain_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/onchain"
+)
+
+const contract = "0x1111111111111111111111111111111111111111"
+
+func newTree(t *testing.T) *gomerk.StandardMerkleTree {
+	t.Helper()
+	tree, err := gomerk.BuildStandardMerkleTree([][]any{
+		{"0x2222222222222222222222222222222222222222", "100"},
+		{"0x3333333333333333333333333333333333333333", "200"},
+	}, []string{"address", "uint256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// rpcServer answers eth_call for merkleRoot() on contract with root.
+func rpcServer(t *testing.T, root string) *httptest.Server {
+	t.Helper()
+	sel := gomerk.Selector("merkleRoot()")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "eth_call" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var call struct{ To, Data string }
+		json.Unmarshal(req.Params[0], &call)
+		result := "0x"
+		if strings.EqualFold(call.To, contract) && call.Data == "0x"+hex.EncodeToString(sel[:]) {
+			result = root
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestCheckRoot(t *testing.T) {
+	tree := newTree(t)
+	caller := onchain.NewRPCCaller(rpcServer(t, tree.Root()).URL)
+	report, err := onchain.CheckRoot(context.Background(), caller, contract, "merkleRoot()", tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Match || report.Actual != tree.Root() || report.Err() != nil {
+		t.Errorf("report = %+v, want a match", report)
+	}
+}
+
+func TestCheckRootMismatch(t *testing.T) {
+	tree := newTree(t)
+	other := gomerk.Keccak256([]byte("other")).Hex()
+	caller := onchain.NewRPCCaller(rpcServer(t, other).URL)
+	report, err := onchain.CheckRoot(context.Background(), caller, contract, "merkleRoot()", tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Match || report.Actual != other || report.Expected != tree.Root() {
+		t.Errorf("report = %+v, want a mismatch", report)
+	}
+	if !errors.Is(report.Err(), gomerk.ErrRootMismatch) {
+		t.Errorf("Err() = %v, want ErrRootMismatch", report.Err())
+	}
+}
+
+func TestFetchRootErrors(t *testing.T) {
+	ctx := context.Background()
+	caller := onchain.NewRPCCaller(rpcServer(t, newTree(t).Root()).URL)
+	if _, err := onchain.FetchRoot(ctx, caller, "0x2222222222222222222222222222222222222222", "merkleRoot()"); !errors.Is(err, onchain.ErrEmptyResult) {
+		t.Errorf("no code: err = %v, want ErrEmptyResult", err)
+	}
+	if _, err := onchain.FetchRoot(ctx, caller, "0x1234", "merkleRoot()"); !errors.Is(err, gomerk.ErrInvalidAddress) {
+		t.Errorf("bad address: err = %v", err)
+	}
+	if _, err := onchain.FetchRoot(ctx, caller, contract, "roots(uint256)"); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("getter with arguments: err = %v", err)
+	}
+
+	failing := onchain.CallerFunc(func(context.Context, string, []byte) ([]byte, error) {
+		return nil, errors.New("execution reverted")
+	})
+	if _, err := onchain.FetchRoot(ctx, failing, contract, "merkleRoot()"); err == nil || !strings.Contains(err.Error(), "reverted") {
+		t.Errorf("failing caller: err = %v", err)
+	}
+}
+
+func TestRPCCallerError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`))
+	}))
+	defer ts.Close()
+	_, err := onchain.NewRPCCaller(ts.URL).CallContract(context.Background(), contract, []byte{1, 2, 3, 4})
+	if err == nil || !strings.Contains(err.Error(), "execution reverted") {
+		t.Errorf("err = %v, want the RPC error", err)
+	}
+}
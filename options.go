@@ -0,0 +1,184 @@
+package gomerk
+
+import "io"
+
+// Option configures trees and verifiers. Options that do not apply to the
+// receiving constructor are ignored.
+type Option func(*options)
+
+type options struct {
+	maxProofLength   int
+	strictOrder      bool
+	dropValues       bool
+	littleEndian     bool
+	packed           bool
+	fullABI          bool
+	unsorted         bool
+	dedup            bool
+	rejectDuplicates bool
+	parallelism      int
+	truncateRoot     int
+	hasher           Hasher
+	salted           bool
+	saltSource       io.Reader
+	eip712           *EIP712Type
+	sortBuffer       int
+	tempDir          string
+	progress         func(Progress)
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// hash returns the configured hasher, defaulting to Keccak256Hasher.
+func (o options) hash() Hasher {
+	if o.hasher == nil {
+		return Keccak256Hasher
+	}
+	return o.hasher
+}
+
+// WithSortLeaves sets whether BuildStandardMerkleTree and
+// BuildSimpleMerkleTree sort leaves by hash, which they do by default.
+func WithSortLeaves(sort bool) Option {
+	return func(o *options) { o.unsorted = !sort }
+}
+
+// WithDeduplication builds trees from the first occurrence of each distinct
+// leaf, dropping later duplicates instead of giving them separate leaves.
+// Value indices then refer to the deduplicated values. It is
+// WithDuplicates(DuplicatesDrop).
+func WithDeduplication() Option {
+	return WithDuplicates(DuplicatesDrop)
+}
+
+// DuplicatePolicy selects how trees treat values with the same leaf hash.
+type DuplicatePolicy int
+
+const (
+	// DuplicatesAllow gives each duplicate its own leaf. GetProof and
+	// IndexOf then refer to the first of them; IndicesOf lists them all.
+	DuplicatesAllow DuplicatePolicy = iota
+	// DuplicatesReject fails with a *DuplicateError naming the duplicates.
+	DuplicatesReject
+	// DuplicatesDrop keeps only the first occurrence of each leaf.
+	DuplicatesDrop
+)
+
+// WithDuplicates sets how StandardMerkleTree, SimpleMerkleTree and
+// BuildTreeFile treat duplicate leaves when building, appending and
+// updating. Duplicates are allowed by default.
+func WithDuplicates(p DuplicatePolicy) Option {
+	return func(o *options) {
+		o.dedup = p == DuplicatesDrop
+		o.rejectDuplicates = p == DuplicatesReject
+	}
+}
+
+// WithParallelism spreads leaf and node hashing over n goroutines when
+// building trees. The result is identical to a serial build. A custom Hasher
+// must be safe for concurrent use; n <= 1 builds serially.
+func WithParallelism(n int) Option {
+	return func(o *options) { o.parallelism = n }
+}
+
+// WithMaxProofLength rejects proofs with more than n siblings. Use
+// MaxProofLength to derive n from the tree's leaf count.
+func WithMaxProofLength(n int) Option {
+	return func(o *options) { o.maxProofLength = n }
+}
+
+// WithStrictProofOrder makes a Verifier checking proofs against a tree reject
+// any proof that differs from the canonical one the tree generates, even if
+// it reaches the same root. Static verification has no canonical proof to
+// compare with and is unaffected.
+func WithStrictProofOrder() Option {
+	return func(o *options) { o.strictOrder = true }
+}
+
+// WithoutValues builds a StandardMerkleTree that keeps only leaf hashes and
+// positions, discarding the original values to save memory. Proofs by index
+// or by value and verification still work, but At reports false, All yields
+// nothing and Entries yields nil values. Trees loaded from a dump whose values
+// are all null behave the same way.
+func WithoutValues() Option {
+	return func(o *options) { o.dropValues = true }
+}
+
+// WithLittleEndianNumbers encodes integer leaf fields as little-endian 32-byte
+// words, for chains that use that convention instead of the EVM's big-endian
+// ABI encoding. The setting is recorded in the tree's dump.
+func WithLittleEndianNumbers() Option {
+	return func(o *options) { o.littleEndian = true }
+}
+
+// WithPackedLeaves hashes StandardMerkleTree leaves once as
+// keccak256(abi.encodePacked(...)), as Uniswap's MerkleDistributor and its
+// forks do, instead of double-hashing their abi.encode encoding. Tuples and
+// arrays of dynamic types cannot be packed, and WithLittleEndianNumbers does
+// not apply. Leaves are always hashed with keccak256, so only the keccak
+// hashers may be combined with it. The setting is recorded in the tree's
+// dump.
+func WithPackedLeaves() Option {
+	return func(o *options) { o.packed = true }
+}
+
+// WithFullABIEncoding encodes the string and bytes fields of
+// StandardMerkleTree leaves in full, as abi.encode and OpenZeppelin's
+// @openzeppelin/merkle-tree do, instead of as their keccak256 hash. It cannot
+// be combined with WithPackedLeaves or WithEIP712Leaves. The setting is
+// recorded in the tree's dump.
+func WithFullABIEncoding() Option {
+	return func(o *options) { o.fullABI = true }
+}
+
+// WithTruncatedRoot makes a Verifier compare only the first n bytes of the
+// computed root with the expected one, for contracts that store a truncated
+// root. A truncated comparison is weaker: forging a proof against a fixed
+// n-byte prefix takes about 2^(8n) work, and only 2^(4n) if the attacker also
+// chooses the tree's contents, so use the largest n the deployment allows.
+// Values of n outside 1..31 compare the full root.
+func WithTruncatedRoot(n int) Option {
+	return func(o *options) { o.truncateRoot = n }
+}
+
+// WithHasher builds trees, or verifies proofs, with h instead of the default
+// Keccak256Hasher. Trees record h.ID() in their dumps, so h must be
+// registered with RegisterHasher for them to load.
+func WithHasher(h Hasher) Option {
+	return func(o *options) { o.hasher = h }
+}
+
+// WithPositionalHashing builds trees with PositionalKeccak256Hasher, hashing
+// each node as keccak256(left || right). Such trees must be proven with
+// GetProofWithPath and verified with VerifyWithPath; plain proofs do not
+// record which side each sibling is on.
+func WithPositionalHashing() Option {
+	return WithHasher(PositionalKeccak256Hasher)
+}
+
+// WithSaltedLeaves prefixes every StandardMerkleTree value with a random
+// bytes32 salt read from r, or from crypto/rand if r is nil, and its leaf
+// encoding with "bytes32". Leaves become keccak256(keccak256(abi.encode(salt,
+// value...))), so the leaf set cannot be brute-forced from the root or from
+// published proofs. The salts are part of the values: they appear in At, the
+// dump and exported proofs, and a claimant needs theirs to prove a value.
+// Values passed to Rebuild must carry their salts.
+func WithSaltedLeaves(r io.Reader) Option {
+	return func(o *options) { o.salted, o.saltSource = true, r }
+}
+
+// WithEIP712Leaves hashes StandardMerkleTree leaves as the EIP-712
+// hashStruct of typ's primary type, or as the _hashTypedDataV4 digest if typ
+// has a domain separator, hashing each leaf once. The leaf encoding may be
+// nil, in which case it is derived from typ. The type is recorded in the
+// tree's dump. WithPackedLeaves, WithLittleEndianNumbers and WithHasher's
+// leaf hash do not apply.
+func WithEIP712Leaves(typ *EIP712Type) Option {
+	return func(o *options) { o.eip712 = typ }
+}
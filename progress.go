@@ -0,0 +1,61 @@
+package gomerk
+
+import (
+	"context"
+	"sync"
+)
+
+// progressStep is how many leaves are hashed between progress reports and
+// cancellation checks.
+const progressStep = 4096
+
+// Progress reports how far a tree build has got.
+type Progress struct {
+	// LeavesHashed counts the values hashed so far out of Leaves.
+	LeavesHashed, Leaves int
+
+	// LevelsBuilt counts the internal levels hashed so far out of Levels,
+	// which is zero until all leaves are hashed.
+	LevelsBuilt, Levels int
+}
+
+// WithProgress calls fn as BuildStandardMerkleTreeContext and the
+// constructors built on it hash leaves and levels. Calls are serialized but
+// may come from different goroutines, and fn should return quickly.
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) { o.progress = fn }
+}
+
+// buildProgress tracks a build for cancellation and progress reports.
+type buildProgress struct {
+	ctx context.Context
+	fn  func(Progress)
+
+	mu sync.Mutex
+	p  Progress
+}
+
+// hashed records n more hashed leaves and returns the context's error, if
+// any.
+func (b *buildProgress) hashed(n int) error {
+	if b.fn != nil {
+		b.mu.Lock()
+		b.p.LeavesHashed += n
+		b.fn(b.p)
+		b.mu.Unlock()
+	}
+	return b.ctx.Err()
+}
+
+// level records a built level out of levels and returns the context's error,
+// if any.
+func (b *buildProgress) level(levels int) error {
+	if b.fn != nil {
+		b.mu.Lock()
+		b.p.LevelsBuilt++
+		b.p.Levels = levels
+		b.fn(b.p)
+		b.mu.Unlock()
+	}
+	return b.ctx.Err()
+}
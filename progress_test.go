@@ -0,0 +1,65 @@
+package gomerk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestBuildProgress(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := airdropData(10000)
+	want, _ := gomerk.NewStandardMerkleTree(values, enc, true)
+
+	for _, workers := range []int{1, 4} {
+		var reports []gomerk.Progress
+		tree, err := gomerk.BuildStandardMerkleTreeContext(context.Background(), values, enc,
+			gomerk.WithParallelism(workers),
+			gomerk.WithProgress(func(p gomerk.Progress) { reports = append(reports, p) }))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tree.Root() != want.Root() {
+			t.Fatalf("workers=%d: root %s, want %s", workers, tree.Root(), want.Root())
+		}
+		last := reports[len(reports)-1]
+		if last != (gomerk.Progress{LeavesHashed: 10000, Leaves: 10000, LevelsBuilt: 14, Levels: 14}) {
+			t.Errorf("workers=%d: last progress %+v", workers, last)
+		}
+		for i := 1; i < len(reports); i++ {
+			if reports[i].LeavesHashed < reports[i-1].LeavesHashed || reports[i].LevelsBuilt < reports[i-1].LevelsBuilt {
+				t.Fatalf("workers=%d: progress went backwards: %+v then %+v", workers, reports[i-1], reports[i])
+			}
+		}
+	}
+}
+
+func TestBuildContextCanceled(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	values := airdropData(10000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := gomerk.BuildStandardMerkleTreeContext(ctx, values, enc); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled context: err = %v", err)
+	}
+
+	// Cancel once the leaves are hashed, while levels are being built.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	levels := 0
+	_, err := gomerk.BuildStandardMerkleTreeContext(ctx, values, enc, gomerk.WithProgress(func(p gomerk.Progress) {
+		if p.LeavesHashed == p.Leaves {
+			cancel()
+		}
+		levels = p.LevelsBuilt
+	}))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled during build: err = %v", err)
+	}
+	if levels > 1 {
+		t.Errorf("built %d levels after cancellation", levels)
+	}
+}
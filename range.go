@@ -0,0 +1,166 @@
+package gomerk
+
+import "sort"
+
+// RangeProof proves which leaves of a sorted tree fall within a range of leaf
+// hashes. Leaves holds, in sorted order from position Start, every leaf in
+// the range preceded and followed by its outer neighbours, if any, whose
+// positions show that no other leaf lies in the range. Proof holds the
+// remaining nodes needed to rebuild the root.
+//
+// Positions are only bound by the root when the verifier knows the tree's
+// leaf count and nodes are hashed in position order, as with
+// WithPositionalHashing. Under sorted-pair hashing a dishonest prover could
+// swap sibling subtrees to hide leaves, so range proofs need a positional
+// hasher and ErrNotPositional is returned otherwise.
+type RangeProof struct {
+	Start  int      `json:"start"`
+	Leaves []string `json:"leaves"`
+	Proof  []string `json:"proof"`
+}
+
+// GetRangeProof returns a proof of the leaves whose hash h satisfies
+// lo <= h <= hi. The tree must be sorted and use a positional hasher.
+func (t *StandardMerkleTree) GetRangeProof(lo, hi Bytes32) (*RangeProof, error) {
+	if !t.sortLeaves {
+		return nil, ErrTreeNotSorted
+	}
+	if commutative(t.opts.hash()) {
+		return nil, ErrNotPositional
+	}
+	return getRangeProof(t.tree, len(t.values), lo, hi), nil
+}
+
+// GetRangeProof returns a proof of the leaves whose hash h satisfies
+// lo <= h <= hi. The tree must be sorted and use a positional hasher.
+func (t *SimpleMerkleTree) GetRangeProof(lo, hi Bytes32) (*RangeProof, error) {
+	if !t.sorted {
+		return nil, ErrTreeNotSorted
+	}
+	if commutative(t.opts.hash()) {
+		return nil, ErrNotPositional
+	}
+	return getRangeProof(t.tree, len(t.values), lo, hi), nil
+}
+
+// VerifyRangeProof reports whether proof shows that its leaves are exactly
+// the leaves within [lo, hi] of the sorted tree with the given root and leaf
+// count, hashed with PositionalKeccak256Hasher.
+func VerifyRangeProof(root string, leafCount int, lo, hi Bytes32, proof *RangeProof) (bool, error) {
+	return verifyRangeProof(PositionalKeccak256Hasher, root, leafCount, lo, hi, proof)
+}
+
+// VerifyRangeProof is VerifyRangeProof with the verifier's hasher, which
+// must be positional.
+func (v *Verifier) VerifyRangeProof(root string, leafCount int, lo, hi Bytes32, proof *RangeProof) (bool, error) {
+	h := v.opts.hash()
+	if commutative(h) {
+		return false, ErrNotPositional
+	}
+	return verifyRangeProof(h, root, leafCount, lo, hi, proof)
+}
+
+// rangeNode is a node of a range proof and its tree index.
+type rangeNode struct {
+	index int
+	hash  Bytes32
+}
+
+func getRangeProof(tree []Bytes32, n int, lo, hi Bytes32) *RangeProof {
+	leaf := func(k int) Bytes32 { return tree[len(tree)-1-k] }
+	a := sort.Search(n, func(k int) bool { return !leaf(k).Less(lo) })
+	b := sort.Search(n, func(k int) bool { return hi.Less(leaf(k)) })
+	start, end := max(a-1, 0), min(max(a, b)+1, n)
+
+	p := &RangeProof{Start: start}
+	queue := make([]rangeNode, 0, end-start)
+	for k := start; k < end; k++ {
+		p.Leaves = append(p.Leaves, leaf(k).Hex())
+		queue = append(queue, rangeNode{len(tree) - 1 - k, leaf(k)})
+	}
+	// Only the missing siblings are needed, so internal nodes are not hashed.
+	walkRange(queue, func(i int) (Bytes32, bool) {
+		p.Proof = append(p.Proof, tree[i].Hex())
+		return tree[i], true
+	}, func(_, _ Bytes32) Bytes32 { return Bytes32{} })
+	if p.Proof == nil {
+		p.Proof = []string{}
+	}
+	return p
+}
+
+// walkRange rebuilds the root from queue, which holds nodes in descending
+// tree index order, taking siblings not in the queue from missing and
+// combining left and right children with node. It reports false if missing
+// does.
+func walkRange(queue []rangeNode, missing func(i int) (Bytes32, bool), node func(left, right Bytes32) Bytes32) (Bytes32, bool) {
+	for len(queue) > 1 || queue[0].index > 0 {
+		a := queue[0]
+		queue = queue[1:]
+		var b rangeNode
+		if s := sibling(a.index); len(queue) > 0 && queue[0].index == s {
+			b, queue = queue[0], queue[1:]
+		} else {
+			h, ok := missing(s)
+			if !ok {
+				return Bytes32{}, false
+			}
+			b = rangeNode{s, h}
+		}
+		if a.index > b.index {
+			a, b = b, a
+		}
+		queue = append(queue, rangeNode{parent(a.index), node(a.hash, b.hash)})
+	}
+	return queue[0].hash, true
+}
+
+func verifyRangeProof(h Hasher, root string, n int, lo, hi Bytes32, p *RangeProof) (bool, error) {
+	end := p.Start + len(p.Leaves)
+	if n <= 0 || p.Start < 0 || len(p.Leaves) == 0 || end > n {
+		return false, ErrInvariant
+	}
+	total := 2*n - 1
+	queue := make([]rangeNode, len(p.Leaves))
+	for j, s := range p.Leaves {
+		b, err := HexToBytes32(s)
+		if err != nil {
+			return false, err
+		}
+		queue[j] = rangeNode{total - 1 - (p.Start + j), b}
+	}
+
+	// Only the first and last leaves may be outside the range, and they must
+	// be unless they are the tree's first and last leaves.
+	for j, leaf := range queue {
+		if j > 0 && leaf.hash.Less(queue[j-1].hash) {
+			return false, nil
+		}
+		below, above := leaf.hash.Less(lo), hi.Less(leaf.hash)
+		if below && j > 0 || above && j < len(queue)-1 {
+			return false, nil
+		}
+	}
+	if p.Start > 0 && !queue[0].hash.Less(lo) || end < n && !hi.Less(queue[len(queue)-1].hash) {
+		return false, nil
+	}
+
+	var err error
+	used := 0
+	r, ok := walkRange(queue, func(int) (Bytes32, bool) {
+		if used == len(p.Proof) {
+			return Bytes32{}, false
+		}
+		var b Bytes32
+		b, err = HexToBytes32(p.Proof[used])
+		used++
+		return b, err == nil
+	}, h.NodeHash)
+	if err != nil {
+		return false, err
+	}
+	if !ok || used != len(p.Proof) {
+		return false, ErrInvariant
+	}
+	return r.Hex() == root, nil
+}
@@ -0,0 +1,123 @@
+package gomerk_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestRangeProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 8, 33} {
+		for _, opts := range [][]gomerk.Option{{gomerk.WithPositionalHashing()}, {gomerk.WithHasher(gomerk.SHA256PositionalHasher)}} {
+			tree, _ := gomerk.BuildSimpleMerkleTree(simpleLeaves(n), opts...)
+			v := gomerk.NewVerifier(opts...)
+			var leaves []gomerk.Bytes32
+			for _, e := range tree.Dump().Tree[len(tree.Dump().Tree)-n:] {
+				b, _ := gomerk.HexToBytes32(e)
+				leaves = append(leaves, b)
+			}
+			slices.SortFunc(leaves, gomerk.Bytes32.Compare)
+
+			bounds := append(slices.Clone(leaves), gomerk.Bytes32{}, gomerk.Bytes32{0xff, 0xff})
+			for _, lo := range bounds {
+				for _, hi := range bounds {
+					if hi.Less(lo) {
+						continue
+					}
+					p, err := tree.GetRangeProof(lo, hi)
+					if err != nil {
+						t.Fatal(err)
+					}
+					ok, err := v.VerifyRangeProof(tree.Root(), n, lo, hi, p)
+					if err != nil || !ok {
+						t.Fatalf("n=%d: [%s, %s]: verify = %v, %v", n, lo.Hex()[:6], hi.Hex()[:6], ok, err)
+					}
+					var want, got []string
+					for _, l := range leaves {
+						if !l.Less(lo) && !hi.Less(l) {
+							want = append(want, l.Hex())
+						}
+					}
+					for _, s := range p.Leaves {
+						l, _ := gomerk.HexToBytes32(s)
+						if !l.Less(lo) && !hi.Less(l) {
+							got = append(got, s)
+						}
+					}
+					if !slices.Equal(got, want) {
+						t.Fatalf("n=%d: leaves in range %v, want %v", n, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRangeProofRejects(t *testing.T) {
+	tree, _ := gomerk.BuildSimpleMerkleTree(simpleLeaves(20), gomerk.WithPositionalHashing())
+	v := gomerk.NewVerifier(gomerk.WithPositionalHashing())
+	dump := tree.Dump().Tree
+	lo, _ := gomerk.HexToBytes32(dump[len(dump)-5])
+	hi, _ := gomerk.HexToBytes32(dump[len(dump)-9])
+	p, _ := tree.GetRangeProof(lo, hi)
+	if len(p.Leaves) != 7 {
+		t.Fatalf("got %d leaves, want 5 in range and 2 neighbours", len(p.Leaves))
+	}
+	if ok, err := v.VerifyRangeProof(tree.Root(), 20, lo, hi, p); !ok || err != nil {
+		t.Fatalf("verify = %v, %v", ok, err)
+	}
+
+	// Hiding a leaf in range moves the others out of place.
+	hidden := *p
+	hidden.Leaves = slices.Delete(slices.Clone(p.Leaves), 3, 4)
+	if ok, _ := v.VerifyRangeProof(tree.Root(), 20, lo, hi, &hidden); ok {
+		t.Error("proof with a hidden leaf verified")
+	}
+	// Dropping a neighbour leaves the range open.
+	open := *p
+	open.Leaves = p.Leaves[:len(p.Leaves)-1]
+	if ok, _ := v.VerifyRangeProof(tree.Root(), 20, lo, hi, &open); ok {
+		t.Error("proof without its upper neighbour verified")
+	}
+	// A narrower claimed range puts in-range leaves outside it.
+	if ok, _ := v.VerifyRangeProof(tree.Root(), 20, lo, lo, p); ok {
+		t.Error("proof verified for a narrower range")
+	}
+	if ok, _ := v.VerifyRangeProof(tree.Root(), 21, lo, hi, p); ok {
+		t.Error("proof verified with the wrong leaf count")
+	}
+	if _, err := v.VerifyRangeProof(tree.Root(), 5, lo, hi, p); !errors.Is(err, gomerk.ErrInvariant) {
+		t.Errorf("leaf count below the proof: err = %v", err)
+	}
+
+	unsorted, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(4), false)
+	if _, err := unsorted.GetRangeProof(lo, hi); !errors.Is(err, gomerk.ErrTreeNotSorted) {
+		t.Errorf("unsorted: err = %v", err)
+	}
+	sortedPair, _ := gomerk.BuildSimpleMerkleTree(simpleLeaves(4))
+	if _, err := sortedPair.GetRangeProof(lo, hi); !errors.Is(err, gomerk.ErrNotPositional) {
+		t.Errorf("sorted-pair tree: err = %v", err)
+	}
+	if _, err := gomerk.NewVerifier().VerifyRangeProof(tree.Root(), 20, lo, hi, p); !errors.Is(err, gomerk.ErrNotPositional) {
+		t.Errorf("sorted-pair verifier: err = %v", err)
+	}
+}
+
+func TestStandardRangeProof(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(50), enc, true, gomerk.WithPositionalHashing())
+	lo, hi := gomerk.Bytes32{0x40}, gomerk.Bytes32{0xa0}
+	p, err := tree.GetRangeProof(lo, hi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := gomerk.VerifyRangeProof(tree.Root(), tree.Len(), lo, hi, p); !ok || err != nil {
+		t.Errorf("verify = %v, %v", ok, err)
+	}
+	sortedPair, _ := gomerk.NewStandardMerkleTree(airdropData(50), enc, true)
+	if _, err := sortedPair.GetRangeProof(lo, hi); !errors.Is(err, gomerk.ErrNotPositional) {
+		t.Errorf("sorted-pair tree: err = %v", err)
+	}
+}
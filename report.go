@@ -0,0 +1,121 @@
+package gomerk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ProofItem is a value and the proof claiming its inclusion.
+type ProofItem struct {
+	Value []any    `json:"value"`
+	Proof []string `json:"proof"`
+}
+
+// VerificationStatus is the outcome of verifying one ProofItem.
+type VerificationStatus string
+
+const (
+	StatusVerified       VerificationStatus = "verified"
+	StatusBadEncoding    VerificationStatus = "bad-encoding"
+	StatusMalformedProof VerificationStatus = "malformed-proof"
+	StatusWrongProof     VerificationStatus = "wrong-proof"
+)
+
+// ItemResult records the verification of the item at Index.
+type ItemResult struct {
+	Index        int                `json:"index"`
+	Status       VerificationStatus `json:"status"`
+	Reason       string             `json:"reason,omitempty"`
+	LeafHash     string             `json:"leafHash,omitempty"`
+	ComputedRoot string             `json:"computedRoot,omitempty"`
+}
+
+// VerificationReport summarizes the verification of a batch of items.
+type VerificationReport struct {
+	Root     string       `json:"root"`
+	Total    int          `json:"total"`
+	Verified int          `json:"verified"`
+	Failed   int          `json:"failed"`
+	Items    []ItemResult `json:"items"`
+}
+
+// BuildVerificationReport verifies every item against root with
+// VerifyStandardDetailed and reports the outcome of each, distinguishing
+// values that fail to encode, proofs that fail to parse and proofs that
+// lead to a different root.
+func BuildVerificationReport(root string, leafEncoding []string, items []ProofItem) VerificationReport {
+	r := VerificationReport{Root: root, Total: len(items), Items: make([]ItemResult, len(items))}
+	for i, it := range items {
+		res := ItemResult{Index: i}
+		leafHash, computed, ok, err := VerifyStandardDetailed(root, leafEncoding, it.Value, it.Proof)
+		var encErr *EncodeError
+		switch {
+		case errors.As(err, &encErr):
+			res.Status, res.Reason = StatusBadEncoding, err.Error()
+		case err != nil:
+			res.Status, res.Reason = StatusMalformedProof, err.Error()
+			res.LeafHash = leafHash.Hex()
+		case !ok:
+			res.Status, res.Reason = StatusWrongProof, ErrRootMismatch.Error()
+			res.LeafHash, res.ComputedRoot = leafHash.Hex(), computed
+		default:
+			res.Status = StatusVerified
+			res.LeafHash, res.ComputedRoot = leafHash.Hex(), computed
+		}
+		if res.Status == StatusVerified {
+			r.Verified++
+		} else {
+			r.Failed++
+		}
+		r.Items[i] = res
+	}
+	return r
+}
+
+// VerifyProofsAgainstTree cross-checks a proofs file against the tree it was
+// generated from, as produced by the airdrop example. It loads the tree and,
+// for every entry, checks that the value is a leaf of the tree, that the
+// proof verifies against the root and, if the tree retains values, that the
+// value encodes identically to the stored one. Entries are checked in key
+// order and the first failure is returned with its key.
+func VerifyProofsAgainstTree(treeData StandardTreeData, proofs map[string]ProofItem) error {
+	t, err := LoadStandardMerkleTree(treeData)
+	if err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(proofs)) {
+		if err := t.checkProofItem(proofs[key]); err != nil {
+			return fmt.Errorf("proof %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (t *StandardMerkleTree) checkProofItem(it ProofItem) error {
+	i, err := t.leafIndex(it.Value)
+	if err != nil {
+		return err
+	}
+	ok, err := t.Verify(it.Value, it.Proof)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidProof
+	}
+	if !t.HasValues() {
+		return nil
+	}
+	stored, err := t.LeafPreimage(i)
+	if err != nil {
+		return err
+	}
+	given, _ := t.preimage(it.Value)
+	if !bytes.Equal(stored, given) {
+		return ErrInvariant
+	}
+	return nil
+}
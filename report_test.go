@@ -0,0 +1,88 @@
+package gomerk_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestBuildVerificationReport(t *testing.T) {
+	vals := airdropData(4)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+
+	p0, _ := tree.GetProof(vals[0])
+	p1, _ := tree.GetProof(vals[1])
+	items := []gomerk.ProofItem{
+		{Value: vals[0], Proof: p0},
+		{Value: vals[1], Proof: p0},
+		{Value: []any{"0x1234", 1}, Proof: p1},
+		{Value: vals[1], Proof: []string{"0xzz"}},
+		{Value: vals[1], Proof: p1},
+	}
+	r := gomerk.BuildVerificationReport(tree.Root(), enc, items)
+
+	if r.Total != 5 || r.Verified != 2 || r.Failed != 3 {
+		t.Errorf("counts = %d/%d/%d, want 5/2/3", r.Total, r.Verified, r.Failed)
+	}
+	want := []gomerk.VerificationStatus{
+		gomerk.StatusVerified,
+		gomerk.StatusWrongProof,
+		gomerk.StatusBadEncoding,
+		gomerk.StatusMalformedProof,
+		gomerk.StatusVerified,
+	}
+	for i, res := range r.Items {
+		if res.Index != i || res.Status != want[i] {
+			t.Errorf("item %d: got %+v, want status %s", i, res, want[i])
+		}
+		if res.Status != gomerk.StatusVerified && res.Reason == "" {
+			t.Errorf("item %d: missing reason", i)
+		}
+	}
+	if r.Items[0].ComputedRoot != tree.Root() {
+		t.Errorf("computed root = %s, want %s", r.Items[0].ComputedRoot, tree.Root())
+	}
+
+	raw, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back gomerk.VerificationReport
+	if err := json.Unmarshal(raw, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Verified != 2 || back.Items[2].Status != gomerk.StatusBadEncoding {
+		t.Errorf("round trip lost data: %+v", back)
+	}
+}
+
+func TestVerifyProofsAgainstTree(t *testing.T) {
+	vals := airdropData(5)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+
+	proofs := make(map[string]gomerk.ProofItem)
+	for i, v := range tree.All() {
+		proof, _ := tree.GetProofByIndex(i)
+		proofs[v[0].(string)] = gomerk.ProofItem{Value: v, Proof: proof}
+	}
+	if err := gomerk.VerifyProofsAgainstTree(tree.Dump(), proofs); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, _ := gomerk.NewStandardMerkleTree(append(vals, []any{"0x" + padAddr(77), 1}), enc, true)
+	if err := gomerk.VerifyProofsAgainstTree(stale.Dump(), proofs); !errors.Is(err, gomerk.ErrInvalidProof) {
+		t.Errorf("stale tree: got %v, want ErrInvalidProof", err)
+	}
+
+	key := vals[1][0].(string)
+	proofs[key] = gomerk.ProofItem{Value: []any{key, "999"}, Proof: proofs[key].Proof}
+	err := gomerk.VerifyProofsAgainstTree(tree.Dump(), proofs)
+	if !errors.Is(err, gomerk.ErrLeafNotInTree) || !strings.Contains(err.Error(), key) {
+		t.Errorf("got %v, want ErrLeafNotInTree naming %s", err, key)
+	}
+}
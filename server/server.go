@@ -0,0 +1,171 @@
+// Package server serves proofs from a StandardMerkleTree over HTTP.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pyroth/gomerk"
+)
+
+// KeyFunc returns the lookup key of a tree value for GET /proof/{key}.
+type KeyFunc func(value []any) (string, error)
+
+// FieldKey returns a KeyFunc that uses field i of each value, which must be a
+// string.
+func FieldKey(i int) KeyFunc {
+	return func(value []any) (string, error) {
+		if i < 0 || i >= len(value) {
+			return "", gomerk.ErrIndexOutOfBounds
+		}
+		s, ok := value[i].(string)
+		if !ok {
+			return "", fmt.Errorf("%w: field %d is %T, not a string", gomerk.ErrUnsupportedType, i, value[i])
+		}
+		return s, nil
+	}
+}
+
+// Option configures a ProofServer.
+type Option func(*ProofServer)
+
+// WithKey sets how values are keyed. The default is FieldKey(0).
+func WithKey(fn KeyFunc) Option {
+	return func(s *ProofServer) { s.key = fn }
+}
+
+// WithNormalizer sets the function applied to both value keys and requested
+// keys before they are compared. The default is strings.ToLower, which suits
+// address keys; use the identity for case-sensitive keys.
+func WithNormalizer(fn func(string) string) Option {
+	return func(s *ProofServer) { s.normalize = fn }
+}
+
+// ProofServer is an http.Handler serving proofs from a tree:
+//
+//	GET  /root                     {"root": ...}
+//	GET  /proof/{key}              {"value": [...], "proof": [...]}
+//	POST /verify                   {"value": [...], "proof": [...]} -> {"valid": bool}
+//	GET  /multiproof?indices=1,2   {"leaves": [...], "proof": [...], "proofFlags": [...]}
+//
+// Errors are returned as {"error": ...} with a matching status code. Mount it
+// under a prefix with http.StripPrefix.
+type ProofServer struct {
+	tree      *gomerk.StandardMerkleTree
+	key       KeyFunc
+	normalize func(string) string
+	index     map[string]int
+	mux       *http.ServeMux
+}
+
+// New returns a ProofServer for tree, which must retain its values. It
+// returns gomerk.ErrDuplicatedID if two values have the same key.
+func New(tree *gomerk.StandardMerkleTree, opts ...Option) (*ProofServer, error) {
+	if !tree.HasValues() {
+		return nil, gomerk.ErrValuesNotRetained
+	}
+	s := &ProofServer{tree: tree, key: FieldKey(0), normalize: strings.ToLower}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.index = make(map[string]int, tree.Len())
+	for i, v := range tree.All() {
+		k, err := s.key(v)
+		if err != nil {
+			return nil, fmt.Errorf("value %d: %w", i, err)
+		}
+		k = s.normalize(k)
+		if _, dup := s.index[k]; dup {
+			return nil, fmt.Errorf("%w: %s", gomerk.ErrDuplicatedID, k)
+		}
+		s.index[k] = i
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /root", s.handleRoot)
+	s.mux.HandleFunc("GET /proof/{key}", s.handleProof)
+	s.mux.HandleFunc("POST /verify", s.handleVerify)
+	s.mux.HandleFunc("GET /multiproof", s.handleMultiProof)
+	return s, nil
+}
+
+func (s *ProofServer) ServeHTTP(w http.ResponseWriter, r *http.Request) { s.mux.ServeHTTP(w, r) }
+
+func (s *ProofServer) handleRoot(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"root": s.tree.Root()})
+}
+
+func (s *ProofServer) handleProof(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.index[s.normalize(r.PathValue("key"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, gomerk.ErrLeafNotInTree)
+		return
+	}
+	proof, err := s.tree.GetProofByIndex(i)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	v, _ := s.tree.At(i)
+	writeJSON(w, http.StatusOK, gomerk.ProofItem{Value: v, Proof: proof})
+}
+
+func (s *ProofServer) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var item gomerk.ProofItem
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&item); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ok, err := s.tree.Verify(item.Value, item.Proof)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"valid": ok})
+}
+
+func (s *ProofServer) handleMultiProof(w http.ResponseWriter, r *http.Request) {
+	param := r.URL.Query().Get("indices")
+	if param == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing indices"))
+		return
+	}
+	fields := strings.Split(param, ",")
+	values := make([][]any, len(fields))
+	for k, f := range fields {
+		i, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid index %q", f))
+			return
+		}
+		v, ok := s.tree.At(i)
+		if !ok {
+			writeError(w, http.StatusBadRequest, gomerk.ErrIndexOutOfBounds)
+			return
+		}
+		values[k] = v
+	}
+	mp, err := s.tree.GetMultiProof(values)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
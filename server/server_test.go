@@ -0,0 +1,142 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+	"github.com/pyroth/gomerk/server"
+)
+
+var values = [][]any{
+	{"0x1111111111111111111111111111111111111111", "5000000000000000000"},
+	{"0x2222222222222222222222222222222222222222", "2500000000000000000"},
+	{"0x3333333333333333333333333333333333333333", "1000000000000000000"},
+}
+
+func newServer(t *testing.T) (*gomerk.StandardMerkleTree, *httptest.Server) {
+	t.Helper()
+	tree, err := gomerk.BuildStandardMerkleTree(values, []string{"address", "uint256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := server.New(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return tree, ts
+}
+
+func do(t *testing.T, req *http.Request, wantStatus int, out any) {
+	t.Helper()
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != wantStatus {
+		t.Fatalf("%s %s: status %d, want %d", req.Method, req.URL, res.StatusCode, wantStatus)
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func get(t *testing.T, url string, wantStatus int, out any) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	do(t, req, wantStatus, out)
+}
+
+func TestProofServerRootAndProof(t *testing.T) {
+	tree, ts := newServer(t)
+
+	var root map[string]string
+	get(t, ts.URL+"/root", http.StatusOK, &root)
+	if root["root"] != tree.Root() {
+		t.Errorf("root = %s, want %s", root["root"], tree.Root())
+	}
+
+	var item gomerk.ProofItem
+	get(t, ts.URL+"/proof/0x2222222222222222222222222222222222222222", http.StatusOK, &item)
+	if ok, err := tree.Verify(item.Value, item.Proof); err != nil || !ok {
+		t.Errorf("served proof: got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	var e map[string]string
+	get(t, ts.URL+"/proof/0x4444444444444444444444444444444444444444", http.StatusNotFound, &e)
+	if e["error"] == "" {
+		t.Error("missing error message")
+	}
+}
+
+func TestProofServerVerify(t *testing.T) {
+	tree, ts := newServer(t)
+	proof, _ := tree.GetProof(values[0])
+	body, _ := json.Marshal(gomerk.ProofItem{Value: values[0], Proof: proof})
+
+	var res map[string]bool
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/verify", strings.NewReader(string(body)))
+	do(t, req, http.StatusOK, &res)
+	if !res["valid"] {
+		t.Error("valid proof rejected")
+	}
+
+	body, _ = json.Marshal(gomerk.ProofItem{Value: values[1], Proof: proof})
+	req, _ = http.NewRequest(http.MethodPost, ts.URL+"/verify", strings.NewReader(string(body)))
+	do(t, req, http.StatusOK, &res)
+	if res["valid"] {
+		t.Error("invalid proof accepted")
+	}
+
+	var e map[string]string
+	req, _ = http.NewRequest(http.MethodPost, ts.URL+"/verify", strings.NewReader("{"))
+	do(t, req, http.StatusBadRequest, &e)
+}
+
+func TestProofServerMultiProof(t *testing.T) {
+	tree, ts := newServer(t)
+
+	var mp gomerk.StandardMultiProof
+	get(t, ts.URL+"/multiproof?indices=2,0", http.StatusOK, &mp)
+	if ok, err := tree.VerifyMultiProofValues(&mp); err != nil || !ok {
+		t.Errorf("served multiproof: got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	var e map[string]string
+	for _, q := range []string{"", "?indices=x", "?indices=0,9"} {
+		get(t, ts.URL+"/multiproof"+q, http.StatusBadRequest, &e)
+	}
+}
+
+func TestProofServerOptions(t *testing.T) {
+	tree, _ := gomerk.BuildStandardMerkleTree([][]any{{"alice", "1"}, {"Alice", "2"}}, []string{"string", "uint256"})
+	if _, err := server.New(tree); !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Fatalf("got %v, want ErrDuplicatedID", err)
+	}
+	s, err := server.New(tree, server.WithNormalizer(func(k string) string { return k }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.StripPrefix("/api", s))
+	defer ts.Close()
+
+	var item gomerk.ProofItem
+	get(t, ts.URL+"/api/proof/Alice", http.StatusOK, &item)
+	if item.Value[1] != "2" {
+		t.Errorf("value = %v, want Alice's", item.Value)
+	}
+
+	if _, err := server.New(tree, server.WithKey(server.FieldKey(1))); err != nil {
+		t.Error(err)
+	}
+	if _, err := server.New(tree, server.WithKey(server.FieldKey(2))); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+}
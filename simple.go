@@ -5,6 +5,9 @@ import (
 	"slices"
 )
 
+// FormatSimpleV1 is the Format of serialized SimpleMerkleTree data.
+const FormatSimpleV1 = "simple-v1"
+
 // SimpleValue holds a leaf value and its tree index.
 type SimpleValue struct {
 	Value     string `json:"value"`
@@ -16,28 +19,61 @@ type SimpleTreeData struct {
 	Format string        `json:"format"`
 	Tree   []string      `json:"tree"`
 	Values []SimpleValue `json:"values"`
+
+	// Hasher is the ID of the tree's Hasher, empty for Keccak256Hasher.
+	Hasher string `json:"hasher,omitempty"`
+
+	// Unsorted is set for trees built with WithSortLeaves(false). Without it
+	// a tree is loaded as sorted if its leaves are in hash order.
+	Unsorted bool `json:"unsorted,omitempty"`
 }
 
 // SimpleMerkleTree is a Merkle tree for Bytes32 values.
 type SimpleMerkleTree struct {
-	tree   []string
+	tree   []Bytes32
 	values []SimpleValue
+	sorted bool
+	opts   options
+	index  map[Bytes32]int
+}
+
+// NewSimpleMerkleTree creates a new SimpleMerkleTree from values. It is
+// BuildSimpleMerkleTree with WithSortLeaves(sortLeaves) applied first.
+func NewSimpleMerkleTree(values []Bytes32, sortLeaves bool, opts ...Option) (*SimpleMerkleTree, error) {
+	return BuildSimpleMerkleTree(values, append([]Option{WithSortLeaves(sortLeaves)}, opts...)...)
 }
 
-// NewSimpleMerkleTree creates a new SimpleMerkleTree from values.
-func NewSimpleMerkleTree(values []Bytes32, sortLeaves bool) (*SimpleMerkleTree, error) {
+// BuildSimpleMerkleTree creates a new SimpleMerkleTree configured by opts,
+// sorting leaves unless WithSortLeaves(false) is given.
+func BuildSimpleMerkleTree(values []Bytes32, opts ...Option) (*SimpleMerkleTree, error) {
 	type hashed struct {
 		value Bytes32
 		hash  Bytes32
 		index int
 	}
 
+	if len(values) == 0 {
+		return nil, ErrEmptyTree
+	}
+	o := newOptions(opts)
+	h := o.hash()
+
 	items := make([]hashed, len(values))
-	for i, v := range values {
-		items[i] = hashed{v, HashLeaf(v[:]), i}
+	parallelFor(len(values), o.parallelism, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			items[i] = hashed{values[i], h.LeafHash(values[i][:]), i}
+		}
+	})
+	if o.rejectDuplicates {
+		if err := findDuplicate(items, func(it hashed) Bytes32 { return it.hash }); err != nil {
+			return nil, err
+		}
+	}
+	if o.dedup {
+		items = dedupLeaves(items, func(it hashed) Bytes32 { return it.hash }, func(it *hashed, i int) { it.index = i })
 	}
 
-	if sortLeaves {
+	if !o.unsorted {
 		slices.SortFunc(items, func(a, b hashed) int { return a.hash.Compare(b.hash) })
 	}
 
@@ -46,10 +82,7 @@ func NewSimpleMerkleTree(values []Bytes32, sortLeaves bool) (*SimpleMerkleTree,
 		leaves[i] = it.hash
 	}
 
-	tree, err := MakeTree(leaves)
-	if err != nil {
-		return nil, err
-	}
+	tree := makeNodes(h, nil, leaves, o.parallelism)
 
 	vals := make([]SimpleValue, len(items))
 	for i, it := range items {
@@ -59,23 +92,41 @@ func NewSimpleMerkleTree(values []Bytes32, sortLeaves bool) (*SimpleMerkleTree,
 		}
 	}
 
-	return &SimpleMerkleTree{tree: tree, values: vals}, nil
+	t := &SimpleMerkleTree{tree: tree, values: vals, sorted: !o.unsorted, opts: o}
+	t.indexLeaves()
+	return t, nil
 }
 
 // LoadSimpleMerkleTree loads a tree from serialized data.
 func LoadSimpleMerkleTree(data SimpleTreeData) (*SimpleMerkleTree, error) {
-	if data.Format != "simple-v1" {
+	if data.Format != FormatSimpleV1 {
 		return nil, ErrInvalidFormat
 	}
-	t := &SimpleMerkleTree{tree: data.Tree, values: data.Values}
+	h, err := LookupHasher(data.Hasher)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := parseNodes(data.Tree)
+	if err != nil {
+		return nil, ErrInvariant
+	}
+	t := &SimpleMerkleTree{tree: tree, values: data.Values}
+	t.opts.hasher = h
+	t.opts.unsorted = data.Unsorted
 	if err := t.Validate(); err != nil {
 		return nil, err
 	}
+	t.sorted = !data.Unsorted && leavesSorted(t.tree)
+	t.indexLeaves()
 	return t, nil
 }
 
-func (t *SimpleMerkleTree) Root() string { return t.tree[0] }
-func (t *SimpleMerkleTree) Len() int     { return len(t.values) }
+func (t *SimpleMerkleTree) Root() string       { return t.tree[0].Hex() }
+func (t *SimpleMerkleTree) RootBytes() Bytes32 { return t.tree[0] }
+func (t *SimpleMerkleTree) Len() int           { return len(t.values) }
+
+// Hasher returns the hasher the tree was built with.
+func (t *SimpleMerkleTree) Hasher() Hasher { return t.opts.hash() }
 
 func (t *SimpleMerkleTree) At(i int) (string, bool) {
 	if i < 0 || i >= len(t.values) {
@@ -102,27 +153,108 @@ func (t *SimpleMerkleTree) Validate() error {
 		if err != nil {
 			return err
 		}
-		if t.tree[v.TreeIndex] != HashLeaf(leaf[:]).Hex() {
+		if !isLeafNode(len(t.tree), v.TreeIndex) || t.tree[v.TreeIndex] != t.opts.hash().LeafHash(leaf[:]) {
 			return ErrInvariant
 		}
 	}
-	if !IsValidTree(t.tree) {
+	if !isValidNodes(t.opts.hash(), t.tree) {
 		return ErrInvariant
 	}
 	return nil
 }
 
-func (t *SimpleMerkleTree) leafIndex(leaf Bytes32) (int, error) {
-	h := HashLeaf(leaf[:]).Hex()
-	for i, v := range t.values {
-		if t.tree[v.TreeIndex] == h {
-			vb, _ := HexToBytes32(v.Value)
-			if vb == leaf {
-				return i, nil
+// indexLeaves rebuilds the leaf hash lookup used to find values.
+func (t *SimpleMerkleTree) indexLeaves() {
+	t.index = indexLeaves(t.tree, len(t.values), t.treeIndex)
+}
+
+// Append adds values to the tree, giving them value indices from Len() on.
+// Only the new values are hashed, but every internal node is recomputed, since
+// adding leaves moves all of them in the tree's layout. If the tree sorts its
+// leaves and they are still in hash order the new leaves are merged into it,
+// so existing values may change tree index; otherwise they follow the
+// existing leaves. With WithDeduplication, values already in the tree are
+// skipped; with DuplicatesReject they fail the call. Proofs obtained before
+// the call no longer verify. On error the tree is left unchanged.
+func (t *SimpleMerkleTree) Append(values ...Bytes32) error {
+	h := t.opts.hash()
+	var kept, added []Bytes32
+	seen := make(map[Bytes32]int)
+	for _, v := range values {
+		leaf := h.LeafHash(v[:])
+		if t.opts.dedup || t.opts.rejectDuplicates {
+			j, ok := seen[leaf]
+			if !ok {
+				j, ok = t.index[leaf]
 			}
+			if ok {
+				if t.opts.rejectDuplicates {
+					return &DuplicateError{Leaf: leaf, Indices: []int{j, len(t.values) + len(kept)}}
+				}
+				continue
+			}
+			seen[leaf] = len(t.values) + len(kept)
 		}
+		kept = append(kept, v)
+		added = append(added, leaf)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	n := len(t.values)
+	leaves, owners := mergeLeaves(t.tree, n, t.treeIndex, added, t.sorted)
+	t.tree = makeNodes(h, t.tree, leaves, t.opts.parallelism)
+	for _, v := range kept {
+		t.values = append(t.values, SimpleValue{Value: v.Hex()})
+	}
+	for pos, i := range owners {
+		t.values[i].TreeIndex = len(t.tree) - 1 - pos
+	}
+	t.indexLeaves()
+	return nil
+}
+
+// UpdateLeafByIndex replaces the value at index i with value, keeping its
+// position, and recomputes only the nodes on its path. It returns the new
+// root. Proofs obtained before the update may no longer verify.
+func (t *SimpleMerkleTree) UpdateLeafByIndex(i int, value Bytes32) (string, error) {
+	if i < 0 || i >= len(t.values) {
+		return "", ErrIndexOutOfBounds
+	}
+	h := t.opts.hash().LeafHash(value[:])
+	if j, ok := t.index[h]; t.opts.rejectDuplicates && ok && j != i {
+		return "", &DuplicateError{Leaf: h, Indices: []int{min(i, j), max(i, j)}}
+	}
+	ti := t.values[i].TreeIndex
+	old := t.tree[ti]
+	updateLeaf(t.opts.hash(), t.tree, ti, h)
+	t.values[i].Value = value.Hex()
+	t.sorted = t.sorted && leafInOrder(t.tree, ti)
+	reindexLeaf(t.index, t.tree, len(t.values), t.treeIndex, i, old)
+	return t.Root(), nil
+}
+
+// IndexOf returns the index of the first value equal to leaf, in constant
+// time.
+func (t *SimpleMerkleTree) IndexOf(leaf Bytes32) (int, error) { return t.leafIndex(leaf) }
+
+// IndicesOf returns the indices of all values equal to leaf, in ascending
+// order. It is as fast as IndexOf unless the tree holds duplicate leaves.
+func (t *SimpleMerkleTree) IndicesOf(leaf Bytes32) ([]int, error) {
+	h := t.opts.hash().LeafHash(leaf[:])
+	if out := leafIndices(t.index, t.tree, len(t.values), t.treeIndex, h); out != nil {
+		return out, nil
 	}
-	return -1, ErrLeafNotInTree
+	return nil, ErrLeafNotInTree
+}
+
+func (t *SimpleMerkleTree) leafIndex(leaf Bytes32) (int, error) {
+	i, ok := t.index[t.opts.hash().LeafHash(leaf[:])]
+	if !ok {
+		return -1, ErrLeafNotInTree
+	}
+	return i, nil
 }
 
 // GetProof returns a proof for the given leaf.
@@ -139,16 +271,81 @@ func (t *SimpleMerkleTree) GetProofByIndex(i int) ([]string, error) {
 	if i < 0 || i >= len(t.values) {
 		return nil, ErrIndexOutOfBounds
 	}
-	return GetProof(t.tree, t.values[i].TreeIndex)
+	return getProof(t.tree, t.values[i].TreeIndex)
 }
 
-// Verify checks if a leaf is in the tree using the given proof.
-func (t *SimpleMerkleTree) Verify(leaf Bytes32, proof []string) (bool, error) {
-	root, err := ProcessProof(HashLeaf(leaf[:]), proof)
+// GetProofsByIndices returns the proofs of the values at indices, keyed by
+// index. It formats nodes shared by several proofs only once, so it is
+// faster than calling GetProofByIndex for each index.
+func (t *SimpleMerkleTree) GetProofsByIndices(indices []int) (map[int][]string, error) {
+	return proofsByIndices(t.tree, len(t.values), t.treeIndex, indices)
+}
+
+// GetProofWithPath returns the proof for the leaf at index with per-level
+// direction bits, for trees built with positional hashing. path[i] is true
+// when proof[i] is the left sibling.
+func (t *SimpleMerkleTree) GetProofWithPath(i int) ([]string, []bool, error) {
+	if i < 0 || i >= len(t.values) {
+		return nil, nil, ErrIndexOutOfBounds
+	}
+	return getProofWithPath(t.tree, t.values[i].TreeIndex)
+}
+
+// Proofs returns an iterator over the proof of every value, by value index.
+// It is faster than calling GetProofByIndex for each value.
+func (t *SimpleMerkleTree) Proofs() iter.Seq2[int, []string] {
+	return proofsSeq(t.tree, len(t.values), t.treeIndex)
+}
+
+// AllProofs returns the proof of every value, by value index, building them
+// on up to workers goroutines.
+func (t *SimpleMerkleTree) AllProofs(workers int) [][]string {
+	return allProofs(t.tree, len(t.values), t.treeIndex, workers)
+}
+
+func (t *SimpleMerkleTree) treeIndex(i int) int { return t.values[i].TreeIndex }
+
+// GetProofBytes returns the proof for the leaf at index as parsed nodes.
+func (t *SimpleMerkleTree) GetProofBytes(i int) ([]Bytes32, error) {
+	if i < 0 || i >= len(t.values) {
+		return nil, ErrIndexOutOfBounds
+	}
+	return getProofBytes(t.tree, t.values[i].TreeIndex)
+}
+
+// ProveBoundary reports whether leaf is the first or last leaf of a sorted
+// tree, in leaf hash order, and returns its inclusion proof. It returns
+// ErrTreeNotSorted if the leaves are not in sorted order.
+func (t *SimpleMerkleTree) ProveBoundary(leaf Bytes32) (isFirst, isLast bool, proof []string, err error) {
+	if !t.sorted {
+		return false, false, nil, ErrTreeNotSorted
+	}
+	i, err := t.leafIndex(leaf)
 	if err != nil {
-		return false, err
+		return false, false, nil, err
 	}
-	return root == t.Root(), nil
+	proof, err = t.GetProofByIndex(i)
+	if err != nil {
+		return false, false, nil, err
+	}
+	ti := t.values[i].TreeIndex
+	return ti == len(t.tree)-1, ti == len(t.tree)-len(t.values), proof, nil
+}
+
+// Verify checks if a leaf is in the tree using the given proof. With
+// positional hashing the direction bits are taken from the leaf's position.
+func (t *SimpleMerkleTree) Verify(leaf Bytes32, proof []string) (bool, error) {
+	h := t.opts.hash()
+	if !commutative(h) {
+		nodes, err := parseNodes(proof)
+		if err != nil {
+			return false, err
+		}
+		lh := h.LeafHash(leaf[:])
+		positions := leafPositions(t.index, t.tree, len(t.values), t.treeIndex, lh)
+		return verifyPositional(h, t.tree, positions, lh, nodes), nil
+	}
+	return verify(h, t.Root(), h.LeafHash(leaf[:]), proof)
 }
 
 // GetMultiProof returns a proof for multiple leaves.
@@ -164,8 +361,12 @@ func (t *SimpleMerkleTree) GetMultiProof(leaves []Bytes32) (*MultiProof, error)
 	return t.GetMultiProofByIndices(indices)
 }
 
-// GetMultiProofByIndices returns a proof for leaves at the given indices.
+// GetMultiProofByIndices returns a proof for leaves at the given indices. It
+// returns ErrPositionalProof for positionally hashed trees.
 func (t *SimpleMerkleTree) GetMultiProofByIndices(indices []int) (*MultiProof, error) {
+	if !commutative(t.opts.hash()) {
+		return nil, ErrPositionalProof
+	}
 	for _, i := range indices {
 		if i < 0 || i >= len(t.values) {
 			return nil, ErrIndexOutOfBounds
@@ -175,7 +376,7 @@ func (t *SimpleMerkleTree) GetMultiProofByIndices(indices []int) (*MultiProof, e
 	for i, idx := range indices {
 		treeIndices[i] = t.values[idx].TreeIndex
 	}
-	mp, err := GetMultiProof(t.tree, treeIndices)
+	mp, err := getMultiProof(t.tree, treeIndices)
 	if err != nil {
 		return nil, err
 	}
@@ -195,13 +396,13 @@ func (t *SimpleMerkleTree) VerifyMultiProof(mp *MultiProof) (bool, error) {
 		if err != nil {
 			return false, err
 		}
-		hashed[i] = HashLeaf(b[:]).Hex()
+		hashed[i] = t.opts.hash().LeafHash(b[:]).Hex()
 	}
-	root, err := ProcessMultiProof(&MultiProof{
+	root, err := processMultiProof(t.opts.hash(), &MultiProof{
 		Leaves:     hashed,
 		Proof:      mp.Proof,
 		ProofFlags: mp.ProofFlags,
-	})
+	}, nil)
 	if err != nil {
 		return false, err
 	}
@@ -210,17 +411,30 @@ func (t *SimpleMerkleTree) VerifyMultiProof(mp *MultiProof) (bool, error) {
 
 // Dump serializes the tree.
 func (t *SimpleMerkleTree) Dump() SimpleTreeData {
-	return SimpleTreeData{Format: "simple-v1", Tree: t.tree, Values: t.values}
+	return SimpleTreeData{
+		Format:   FormatSimpleV1,
+		Tree:     hexNodes(t.tree),
+		Values:   t.values,
+		Hasher:   dumpHasherID(t.opts.hash()),
+		Unsorted: t.opts.unsorted,
+	}
 }
 
 // Render returns a string representation.
-func (t *SimpleMerkleTree) Render() (string, error) { return RenderTree(t.tree) }
+func (t *SimpleMerkleTree) Render() (string, error) { return RenderTree(hexNodes(t.tree)) }
 
 // VerifySimple is a static verification function.
 func VerifySimple(root string, leaf Bytes32, proof []string) (bool, error) {
+	return Verify(root, HashLeaf(leaf[:]), proof)
+}
+
+// VerifySimpleAny checks a proof against several candidate roots, returning
+// the index of the first matching root or -1. The proof is processed once.
+func VerifySimpleAny(roots []string, leaf Bytes32, proof []string) (int, bool, error) {
 	r, err := ProcessProof(HashLeaf(leaf[:]), proof)
 	if err != nil {
-		return false, err
+		return -1, false, err
 	}
-	return r == root, nil
+	i := slices.Index(roots, r)
+	return i, i >= 0, nil
 }
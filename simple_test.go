@@ -2,6 +2,8 @@ package gomerk_test
 
 import (
 	"encoding/json"
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/pyroth/gomerk"
@@ -42,6 +44,50 @@ func TestSimpleMerkleTreeSingle(t *testing.T) {
 	}
 }
 
+func TestSimpleMerkleTreeTwoLeaves(t *testing.T) {
+	vals := simpleLeaves(2)
+	tree, err := gomerk.NewSimpleMerkleTree(vals, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := tree.Dump()
+	l, r := gomerk.MustHexToBytes32(data.Tree[1]), gomerk.MustHexToBytes32(data.Tree[2])
+	if tree.Root() != gomerk.HashNode(l, r).Hex() {
+		t.Error("root should be the hash of the two leaves")
+	}
+
+	for i, v := range vals {
+		proof, err := tree.GetProofByIndex(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sib := data.Tree[3-data.Values[i].TreeIndex]
+		if len(proof) != 1 || proof[0] != sib {
+			t.Errorf("index %d: proof = %v, want [%s]", i, proof, sib)
+		}
+		if ok, _ := tree.Verify(v, proof); !ok {
+			t.Errorf("index %d: verify failed", i)
+		}
+	}
+
+	mp, err := tree.GetMultiProofByIndices([]int{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mp.Leaves) != 2 || len(mp.Proof) != 0 || !slices.Equal(mp.ProofFlags, []bool{true}) {
+		t.Errorf("unexpected multiproof %+v", mp)
+	}
+	if ok, _ := tree.VerifyMultiProof(mp); !ok {
+		t.Error("multiproof verify failed")
+	}
+
+	s, _ := tree.Render()
+	want := "0) " + data.Tree[0] + "\n├─ 1) " + data.Tree[1] + "\n└─ 2) " + data.Tree[2]
+	if s != want {
+		t.Errorf("render:\n%s\nwant:\n%s", s, want)
+	}
+}
+
 func TestSimpleMerkleTreeValidate(t *testing.T) {
 	tree, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(8), true)
 	if err := tree.Validate(); err != nil {
@@ -312,3 +358,249 @@ func TestSimpleMerkleTreeUnsorted(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifySimpleAny(t *testing.T) {
+	vals := simpleLeaves(4)
+	tree, _ := gomerk.NewSimpleMerkleTree(vals, true)
+	other1, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(3), true)
+	other2, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(5), true)
+
+	roots := []string{other1.Root(), tree.Root(), other2.Root()}
+	proof, _ := tree.GetProof(vals[1])
+	i, ok, err := gomerk.VerifySimpleAny(roots, vals[1], proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || i != 1 {
+		t.Errorf("got (%d, %v), want (1, true)", i, ok)
+	}
+
+	i, ok, _ = gomerk.VerifySimpleAny(roots[:1], vals[1], proof)
+	if ok || i != -1 {
+		t.Errorf("got (%d, %v), want (-1, false)", i, ok)
+	}
+}
+
+func TestSimpleMerkleTreeProveBoundary(t *testing.T) {
+	vals := simpleLeaves(5)
+	tree, _ := gomerk.NewSimpleMerkleTree(vals, true)
+	byHash := slices.Clone(vals)
+	slices.SortFunc(byHash, func(a, b gomerk.Bytes32) int {
+		return gomerk.HashLeaf(a[:]).Compare(gomerk.HashLeaf(b[:]))
+	})
+
+	for i, v := range byHash {
+		first, last, proof, err := tree.ProveBoundary(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first != (i == 0) || last != (i == len(byHash)-1) {
+			t.Errorf("position %d: got first=%v last=%v", i, first, last)
+		}
+		if ok, _ := tree.Verify(v, proof); !ok {
+			t.Errorf("position %d: proof does not verify", i)
+		}
+	}
+
+	if _, _, _, err := tree.ProveBoundary(gomerk.Bytes32{}); err != gomerk.ErrLeafNotInTree {
+		t.Errorf("got %v, want ErrLeafNotInTree", err)
+	}
+
+	loaded, _ := gomerk.LoadSimpleMerkleTree(tree.Dump())
+	if _, _, _, err := loaded.ProveBoundary(byHash[0]); err != nil {
+		t.Errorf("loaded sorted tree: %v", err)
+	}
+
+	slices.Reverse(byHash)
+	unsorted, _ := gomerk.NewSimpleMerkleTree(byHash, false)
+	if _, _, _, err := unsorted.ProveBoundary(byHash[0]); err != gomerk.ErrTreeNotSorted {
+		t.Errorf("got %v, want ErrTreeNotSorted", err)
+	}
+}
+
+func TestSimpleMerkleTreeIndexOf(t *testing.T) {
+	vals := simpleLeaves(7)
+	tree, _ := gomerk.NewSimpleMerkleTree(vals, true)
+	loaded, _ := gomerk.LoadSimpleMerkleTree(tree.Dump())
+	for _, tr := range []*gomerk.SimpleMerkleTree{tree, loaded} {
+		for i, v := range vals {
+			if got, err := tr.IndexOf(v); err != nil || got != i {
+				t.Errorf("IndexOf(value %d) = (%d, %v)", i, got, err)
+			}
+		}
+		if _, err := tr.IndexOf(gomerk.Bytes32{}); err != gomerk.ErrLeafNotInTree {
+			t.Errorf("got %v, want ErrLeafNotInTree", err)
+		}
+	}
+}
+
+func TestBuildSimpleMerkleTree(t *testing.T) {
+	leaves := simpleLeaves(6)
+	legacy, _ := gomerk.NewSimpleMerkleTree(leaves, true)
+	built, err := gomerk.BuildSimpleMerkleTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.Root() != legacy.Root() {
+		t.Error("leaves should be sorted by default")
+	}
+
+	dup := append([]gomerk.Bytes32{leaves[2]}, leaves...)
+	unsorted, _ := gomerk.BuildSimpleMerkleTree(dup, gomerk.WithSortLeaves(false), gomerk.WithDeduplication())
+	want, _ := gomerk.NewSimpleMerkleTree(append([]gomerk.Bytes32{leaves[2]}, slices.Delete(slices.Clone(leaves), 2, 3)...), false)
+	if unsorted.Root() != want.Root() || unsorted.Len() != len(leaves) {
+		t.Error("deduplication should keep the first occurrence in place")
+	}
+	if v, _ := unsorted.At(0); v != leaves[2].Hex() {
+		t.Errorf("value 0 = %s, want %s", v, leaves[2].Hex())
+	}
+}
+
+func TestSimpleMerkleTreeParallelism(t *testing.T) {
+	leaves := simpleLeaves(4097)
+	serial, _ := gomerk.NewSimpleMerkleTree(leaves, true)
+	parallel, _ := gomerk.NewSimpleMerkleTree(leaves, true, gomerk.WithParallelism(3))
+	if !slices.Equal(parallel.Dump().Tree, serial.Dump().Tree) {
+		t.Error("parallel build differs from serial")
+	}
+}
+
+func TestSimpleMerkleTreeBytesAccessors(t *testing.T) {
+	tree, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(5), true)
+	if tree.RootBytes().Hex() != tree.Root() {
+		t.Errorf("RootBytes = %s, want %s", tree.RootBytes(), tree.Root())
+	}
+	for i := range tree.Len() {
+		proof, _ := tree.GetProofByIndex(i)
+		parsed, err := tree.GetProofBytes(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parsed) != len(proof) {
+			t.Fatalf("index %d: %d parsed nodes, want %d", i, len(parsed), len(proof))
+		}
+		for j := range proof {
+			if parsed[j].Hex() != proof[j] {
+				t.Errorf("index %d node %d: got %s, want %s", i, j, parsed[j], proof[j])
+			}
+		}
+	}
+	if _, err := tree.GetProofBytes(5); err != gomerk.ErrIndexOutOfBounds {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+
+	data := tree.Dump()
+	data.Tree[0] = "0xzz"
+	if _, err := gomerk.LoadSimpleMerkleTree(data); err != gomerk.ErrInvariant {
+		t.Errorf("got %v, want ErrInvariant", err)
+	}
+}
+
+func TestSimpleMerkleTreeProofs(t *testing.T) {
+	tree, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(9), false)
+	all := tree.AllProofs(2)
+	for i, proof := range tree.Proofs() {
+		want, _ := tree.GetProofByIndex(i)
+		if !slices.Equal(proof, want) || !slices.Equal(all[i], want) {
+			t.Errorf("index %d: proofs differ from GetProofByIndex", i)
+		}
+	}
+}
+
+func TestSimpleMerkleTreeUpdateLeafByIndex(t *testing.T) {
+	leaves := []gomerk.Bytes32{{1}, {2}, {3}, {4}, {5}}
+	tree, err := gomerk.BuildSimpleMerkleTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := tree.UpdateLeafByIndex(2, gomerk.Bytes32{9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != tree.Root() {
+		t.Errorf("returned root %s, tree root %s", root, tree.Root())
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := tree.GetProof(gomerk.Bytes32{9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := tree.Verify(gomerk.Bytes32{9}, proof); !ok {
+		t.Error("proof of updated leaf does not verify")
+	}
+	if _, err := tree.GetProof(gomerk.Bytes32{3}); !errors.Is(err, gomerk.ErrLeafNotInTree) {
+		t.Errorf("replaced leaf: got %v, want ErrLeafNotInTree", err)
+	}
+	if _, err := tree.UpdateLeafByIndex(-1, gomerk.Bytes32{}); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+}
+
+func TestSimpleMerkleTreeAppend(t *testing.T) {
+	all := simpleLeaves(9)
+	tree, err := gomerk.BuildSimpleMerkleTree(all[:4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Append(all[4:]...); err != nil {
+		t.Fatal(err)
+	}
+	fresh, _ := gomerk.BuildSimpleMerkleTree(all)
+	if tree.Root() != fresh.Root() {
+		t.Errorf("appended root %s, rebuilt root %s", tree.Root(), fresh.Root())
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range all {
+		if j, err := tree.IndexOf(v); err != nil || j != i {
+			t.Errorf("IndexOf(%d) = (%d, %v)", i, j, err)
+		}
+	}
+}
+
+func TestSimpleMerkleTreeAppendUnsorted(t *testing.T) {
+	// Leaves given in hash order must still be appended, not merged.
+	all := simpleLeaves(6)
+	slices.SortFunc(all, func(a, b gomerk.Bytes32) int {
+		return gomerk.HashLeaf(a[:]).Compare(gomerk.HashLeaf(b[:]))
+	})
+	for k := 1; k <= 2; k++ {
+		base := slices.Concat(all[:2], all[2+k:])
+		tree, _ := gomerk.NewSimpleMerkleTree(base, false)
+		loaded, err := gomerk.LoadSimpleMerkleTree(tree.Dump())
+		if err != nil {
+			t.Fatal(err)
+		}
+		added := all[2 : 2+k]
+		if err := tree.Append(added...); err != nil {
+			t.Fatal(err)
+		}
+		if err := loaded.Append(added...); err != nil {
+			t.Fatal(err)
+		}
+		fresh, _ := gomerk.NewSimpleMerkleTree(append(base, added...), false)
+		if tree.Root() != fresh.Root() || loaded.Root() != fresh.Root() {
+			t.Errorf("k=%d: appended roots %s and %s, rebuilt %s", k, tree.Root(), loaded.Root(), fresh.Root())
+		}
+	}
+}
+
+func TestSimpleGetProofsByIndices(t *testing.T) {
+	tree, _ := gomerk.NewSimpleMerkleTree(simpleLeaves(9), false)
+	proofs, err := tree.GetProofsByIndices([]int{8, 3, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{8, 3, 0} {
+		want, _ := tree.GetProofByIndex(i)
+		if !slices.Equal(proofs[i], want) {
+			t.Errorf("proof %d = %v, want %v", i, proofs[i], want)
+		}
+	}
+	if _, err := tree.GetProofsByIndices([]int{-1}); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package gomerk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SolidityOptions configures GenerateSolidityVerifier.
+type SolidityOptions struct {
+	// Name is the contract or library name, "MerkleClaim" by default.
+	Name string
+
+	// FieldNames names the verify and claim parameters, one per leaf
+	// encoding field. By default they are value0, value1 and so on.
+	FieldNames []string
+
+	// Pragma is the solidity version constraint, "^0.8.20" by default.
+	Pragma string
+
+	// Library emits a library with an internal verify function instead of
+	// a contract with verify and claim.
+	Library bool
+}
+
+var solidityIdent = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// solidityField is a verify parameter.
+type solidityField struct {
+	name, typ string
+}
+
+// decl returns the parameter declaration, with loc as the data location of
+// dynamic types.
+func (f solidityField) decl(loc string) string {
+	if f.typ == "string" || f.typ == "bytes" || strings.HasSuffix(f.typ, "]") {
+		return f.typ + " " + loc + " " + f.name
+	}
+	return f.typ + " " + f.name
+}
+
+// GenerateSolidityVerifier returns the source of a Solidity contract that
+// embeds the tree's root and verifies proofs for values of its leaf encoding
+// with OpenZeppelin's MerkleProof. The contract's claim function marks each
+// leaf as claimed once and emits Claimed; extend it to transfer tokens.
+//
+// Trees with tuple fields, a hasher other than Keccak256Hasher or little
+// endian numbers are not supported, and EIP-712 leaves must not reference
+// other struct types.
+func (t *StandardMerkleTree) GenerateSolidityVerifier(opts SolidityOptions) (string, error) {
+	if opts.Name == "" {
+		opts.Name = "MerkleClaim"
+	}
+	if opts.Pragma == "" {
+		opts.Pragma = "^0.8.20"
+	}
+	if !solidityIdent.MatchString(opts.Name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidIdentifier, opts.Name)
+	}
+	if t.opts.hash().ID() != HasherKeccak256 {
+		return "", fmt.Errorf("%w: hasher %s", ErrUnsupportedType, t.opts.hash().ID())
+	}
+	if t.opts.littleEndian {
+		return "", fmt.Errorf("%w: little endian numbers", ErrUnsupportedType)
+	}
+	if opts.FieldNames != nil && len(opts.FieldNames) != len(t.leafEncoding) {
+		return "", ErrMismatchedCount
+	}
+
+	fields := make([]solidityField, len(t.leafEncoding))
+	for i, typ := range t.leafEncoding {
+		name := fmt.Sprintf("value%d", i)
+		if opts.FieldNames != nil {
+			name = opts.FieldNames[i]
+		}
+		if !solidityIdent.MatchString(name) || name == "proof" || name == "leaf" {
+			return "", fmt.Errorf("%w: %q", ErrInvalidIdentifier, name)
+		}
+		if strings.ContainsAny(typ, "()") {
+			return "", fmt.Errorf("%w: tuple field %s", ErrUnsupportedType, typ)
+		}
+		fields[i] = solidityField{name, typ}
+	}
+	leaf, consts, err := t.solidityLeaf(fields)
+	if err != nil {
+		return "", err
+	}
+
+	loc := "calldata"
+	if opts.Library {
+		loc = "memory"
+	}
+	params := make([]string, len(fields))
+	args := make([]string, len(fields))
+	for i, f := range fields {
+		params[i] = f.decl(loc)
+		args[i] = f.name
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// SPDX-License-Identifier: MIT\n")
+	fmt.Fprintf(&b, "pragma solidity %s;\n\n", opts.Pragma)
+	fmt.Fprintf(&b, "import {MerkleProof} from \"@openzeppelin/contracts/utils/cryptography/MerkleProof.sol\";\n\n")
+	if opts.Library {
+		fmt.Fprintf(&b, "library %s {\n", opts.Name)
+	} else {
+		fmt.Fprintf(&b, "contract %s {\n", opts.Name)
+	}
+	fmt.Fprintf(&b, "    bytes32 public constant MERKLE_ROOT = %s;\n", t.Root())
+	for _, c := range consts {
+		fmt.Fprintf(&b, "    %s\n", c)
+	}
+	b.WriteString("\n")
+
+	if opts.Library {
+		fmt.Fprintf(&b, "    function verify(bytes32[] memory proof, %s) internal pure returns (bool) {\n", strings.Join(params, ", "))
+		fmt.Fprintf(&b, "        return MerkleProof.verify(proof, MERKLE_ROOT, leafHash(%s));\n", strings.Join(args, ", "))
+		b.WriteString("    }\n\n")
+	} else {
+		b.WriteString("    mapping(bytes32 => bool) public claimed;\n\n")
+		fmt.Fprintf(&b, "    event Claimed(%s);\n\n", strings.Join(eventParams(fields), ", "))
+		b.WriteString("    error AlreadyClaimed();\n")
+		b.WriteString("    error InvalidProof();\n\n")
+		fmt.Fprintf(&b, "    function verify(%s, bytes32[] calldata proof) public pure returns (bool) {\n", strings.Join(params, ", "))
+		fmt.Fprintf(&b, "        return MerkleProof.verifyCalldata(proof, MERKLE_ROOT, leafHash(%s));\n", strings.Join(args, ", "))
+		b.WriteString("    }\n\n")
+		fmt.Fprintf(&b, "    function claim(%s, bytes32[] calldata proof) external {\n", strings.Join(params, ", "))
+		fmt.Fprintf(&b, "        bytes32 leaf = leafHash(%s);\n", strings.Join(args, ", "))
+		b.WriteString("        if (claimed[leaf]) revert AlreadyClaimed();\n")
+		b.WriteString("        if (!MerkleProof.verifyCalldata(proof, MERKLE_ROOT, leaf)) revert InvalidProof();\n")
+		b.WriteString("        claimed[leaf] = true;\n")
+		fmt.Fprintf(&b, "        emit Claimed(%s);\n", strings.Join(args, ", "))
+		b.WriteString("    }\n\n")
+	}
+	fmt.Fprintf(&b, "    function leafHash(%s) internal pure returns (bytes32) {\n", strings.Join(params, ", "))
+	fmt.Fprintf(&b, "        return %s;\n", leaf)
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// eventParams declares fields as event parameters, which take no data
+// location.
+func eventParams(fields []solidityField) []string {
+	out := make([]string, len(fields))
+	for i, f := range fields {
+		out[i] = f.typ + " " + f.name
+	}
+	return out
+}
+
+// solidityLeaf returns the expression computing a leaf from fields and the
+// constants it uses.
+func (t *StandardMerkleTree) solidityLeaf(fields []solidityField) (string, []string, error) {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	args := strings.Join(names, ", ")
+	typ := t.opts.eip712
+	switch {
+	case typ != nil:
+		enc, err := typ.EncodeType()
+		if err != nil {
+			return "", nil, err
+		}
+		words := []string{"TYPEHASH"}
+		for _, f := range fields {
+			w, err := solidityEIP712Word(typ, f)
+			if err != nil {
+				return "", nil, err
+			}
+			words = append(words, w)
+		}
+		consts := []string{fmt.Sprintf("bytes32 public constant TYPEHASH = keccak256(%q);", enc)}
+		hash := "keccak256(abi.encode(" + strings.Join(words, ", ") + "))"
+		if typ.DomainSeparator == "" {
+			return hash, consts, nil
+		}
+		consts = append(consts, "bytes32 public constant DOMAIN_SEPARATOR = "+typ.DomainSeparator+";")
+		return `keccak256(abi.encodePacked(hex"1901", DOMAIN_SEPARATOR, ` + hash + "))", consts, nil
+	case t.opts.packed:
+		return "keccak256(abi.encodePacked(" + args + "))", nil, nil
+	case t.opts.fullABI:
+		return "keccak256(bytes.concat(keccak256(abi.encode(" + args + "))))", nil, nil
+	default:
+		// Leaves hold strings and bytes as their hash, so only that is encoded.
+		words := make([]string, len(fields))
+		for i, f := range fields {
+			switch base := arrayBase(f.typ); {
+			case f.typ == "string":
+				words[i] = "keccak256(bytes(" + f.name + "))"
+			case f.typ == "bytes":
+				words[i] = "keccak256(" + f.name + ")"
+			case base == "string" || base == "bytes":
+				return "", nil, fmt.Errorf("%w: array field %s", ErrUnsupportedType, f.typ)
+			default:
+				words[i] = f.name
+			}
+		}
+		return "keccak256(bytes.concat(keccak256(abi.encode(" + strings.Join(words, ", ") + "))))", nil, nil
+	}
+}
+
+// solidityEIP712Word returns the expression for a field's word in an EIP-712
+// encodeData.
+func solidityEIP712Word(typ *EIP712Type, f solidityField) (string, error) {
+	base := eip712BaseType(f.typ)
+	switch {
+	case typ.Types[base] != nil:
+		return "", fmt.Errorf("%w: EIP-712 struct field %s", ErrUnsupportedType, f.typ)
+	case f.typ == "string":
+		return "keccak256(bytes(" + f.name + "))", nil
+	case f.typ == "bytes":
+		return "keccak256(" + f.name + ")", nil
+	case base != f.typ:
+		if base == "string" || base == "bytes" || strings.Count(f.typ, "[") > 1 {
+			return "", fmt.Errorf("%w: EIP-712 array field %s", ErrUnsupportedType, f.typ)
+		}
+		// abi.encodePacked pads array elements to words, as encodeData does.
+		return "keccak256(abi.encodePacked(" + f.name + "))", nil
+	default:
+		return f.name, nil
+	}
+}
@@ -0,0 +1,130 @@
+package gomerk_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestGenerateSolidityVerifier(t *testing.T) {
+	tree, err := gomerk.NewStandardMerkleTree(airdropData(4), []string{"address", "uint256"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := tree.GenerateSolidityVerifier(gomerk.SolidityOptions{Name: "Airdrop", FieldNames: []string{"account", "amount"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"pragma solidity ^0.8.20;",
+		"contract Airdrop {",
+		"bytes32 public constant MERKLE_ROOT = " + tree.Root() + ";",
+		"function verify(address account, uint256 amount, bytes32[] calldata proof) public pure returns (bool)",
+		"function claim(address account, uint256 amount, bytes32[] calldata proof) external",
+		"event Claimed(address account, uint256 amount);",
+		"return keccak256(bytes.concat(keccak256(abi.encode(account, amount))));",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("source does not contain %q:\n%s", want, src)
+		}
+	}
+
+	lib, err := tree.GenerateSolidityVerifier(gomerk.SolidityOptions{Library: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"library MerkleClaim {",
+		"function verify(bytes32[] memory proof, address value0, uint256 value1) internal pure returns (bool)",
+	} {
+		if !strings.Contains(lib, want) {
+			t.Errorf("library does not contain %q:\n%s", want, lib)
+		}
+	}
+	if strings.Contains(lib, "claim(") {
+		t.Errorf("library has a claim function")
+	}
+}
+
+func TestGenerateSolidityVerifierEncodings(t *testing.T) {
+	values := [][]any{{"a", []any{1, 2}}, {"b", []any{3}}}
+	packed, _ := gomerk.NewStandardMerkleTree(values, []string{"string", "uint256[]"}, true, gomerk.WithPackedLeaves())
+	src, err := packed.GenerateSolidityVerifier(gomerk.SolidityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"string calldata value0, uint256[] calldata value1",
+		"return keccak256(abi.encodePacked(value0, value1));",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("packed source does not contain %q:\n%s", want, src)
+		}
+	}
+
+	plain, _ := gomerk.NewStandardMerkleTree([][]any{{"a", "0x01", 1}}, []string{"string", "bytes", "uint256"}, true)
+	src, err = plain.GenerateSolidityVerifier(gomerk.SolidityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "return keccak256(bytes.concat(keccak256(abi.encode(keccak256(bytes(value0)), keccak256(value1), value2))));"; !strings.Contains(src, want) {
+		t.Errorf("source does not contain %q:\n%s", want, src)
+	}
+
+	full, _ := gomerk.NewStandardMerkleTree([][]any{{"a", "0x01", 1}}, []string{"string", "bytes", "uint256"}, true, gomerk.WithFullABIEncoding())
+	src, err = full.GenerateSolidityVerifier(gomerk.SolidityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "return keccak256(bytes.concat(keccak256(abi.encode(value0, value1, value2))));"; !strings.Contains(src, want) {
+		t.Errorf("full ABI source does not contain %q:\n%s", want, src)
+	}
+
+	typ := &gomerk.EIP712Type{
+		PrimaryType:     "Claim",
+		Types:           map[string][]gomerk.EIP712Field{"Claim": {{Name: "account", Type: "address"}, {Name: "note", Type: "string"}}},
+		DomainSeparator: "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f",
+	}
+	eip, _ := gomerk.BuildStandardMerkleTree([][]any{{"0x" + padAddr(1), "hi"}}, nil, gomerk.WithEIP712Leaves(typ))
+	src, err = eip.GenerateSolidityVerifier(gomerk.SolidityOptions{FieldNames: []string{"account", "note"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`bytes32 public constant TYPEHASH = keccak256("Claim(address account,string note)");`,
+		"bytes32 public constant DOMAIN_SEPARATOR = " + typ.DomainSeparator + ";",
+		`return keccak256(abi.encodePacked(hex"1901", DOMAIN_SEPARATOR, keccak256(abi.encode(TYPEHASH, account, keccak256(bytes(note))))));`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("EIP-712 source does not contain %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateSolidityVerifierErrors(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(2), []string{"address", "uint256"}, true)
+	if _, err := tree.GenerateSolidityVerifier(gomerk.SolidityOptions{Name: "bad name"}); !errors.Is(err, gomerk.ErrInvalidIdentifier) {
+		t.Errorf("bad name: err = %v", err)
+	}
+	if _, err := tree.GenerateSolidityVerifier(gomerk.SolidityOptions{FieldNames: []string{"account"}}); !errors.Is(err, gomerk.ErrMismatchedCount) {
+		t.Errorf("short field names: err = %v", err)
+	}
+	if _, err := tree.GenerateSolidityVerifier(gomerk.SolidityOptions{FieldNames: []string{"account", "proof"}}); !errors.Is(err, gomerk.ErrInvalidIdentifier) {
+		t.Errorf("reserved field name: err = %v", err)
+	}
+
+	sha, _ := gomerk.NewStandardMerkleTree(airdropData(2), []string{"address", "uint256"}, true, gomerk.WithHasher(gomerk.SHA256Hasher))
+	if _, err := sha.GenerateSolidityVerifier(gomerk.SolidityOptions{}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("sha256 tree: err = %v", err)
+	}
+	tuple, _ := gomerk.NewStandardMerkleTree([][]any{{[]any{1, 2}}}, []string{"(uint256,uint256)"}, true)
+	if _, err := tuple.GenerateSolidityVerifier(gomerk.SolidityOptions{}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("tuple tree: err = %v", err)
+	}
+	strs, _ := gomerk.NewStandardMerkleTree([][]any{{[]any{"a", "b"}}}, []string{"string[]"}, true)
+	if _, err := strs.GenerateSolidityVerifier(gomerk.SolidityOptions{}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("string[] tree: err = %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package gomerk
+
+// SparseMerkleTree is a 256-level Merkle tree with one slot per Bytes32 key,
+// supporting proofs that a key is absent as well as present. Unlike the dense
+// trees, pairs are hashed in order, left then right, since the key's bits
+// give each node's position. Empty subtrees hash to zero: the hash of two
+// zero children is zero, so an empty tree has the zero root. An occupied slot
+// holds HashLeaf(key || value); the zero value is reserved for empty slots.
+type SparseMerkleTree struct {
+	leaves map[Bytes32]Bytes32
+	nodes  map[sparseNode]Bytes32
+}
+
+// sparseNode identifies the subtree of the given height containing the keys
+// that share prefix, which has its low height bits cleared.
+type sparseNode struct {
+	height int
+	prefix Bytes32
+}
+
+// SparseProof holds the non-empty siblings on a key's path, from the leaf up.
+// Bit h of Bitmap, counted from the least significant bit, is set if the
+// sibling at height h is non-empty and therefore present in Siblings.
+type SparseProof struct {
+	Bitmap   string   `json:"bitmap"`
+	Siblings []string `json:"siblings"`
+}
+
+// NewSparseMerkleTree creates an empty SparseMerkleTree.
+func NewSparseMerkleTree() *SparseMerkleTree {
+	return &SparseMerkleTree{leaves: make(map[Bytes32]Bytes32), nodes: make(map[sparseNode]Bytes32)}
+}
+
+func (t *SparseMerkleTree) Root() Bytes32 { return t.nodes[sparseNode{height: 256}] }
+func (t *SparseMerkleTree) Len() int      { return len(t.leaves) }
+
+// Get returns the value stored under key.
+func (t *SparseMerkleTree) Get(key Bytes32) (Bytes32, bool) {
+	v, ok := t.leaves[key]
+	return v, ok
+}
+
+// Insert stores value under a key that is not yet present.
+func (t *SparseMerkleTree) Insert(key, value Bytes32) error {
+	if _, ok := t.leaves[key]; ok {
+		return ErrDuplicatedID
+	}
+	return t.set(key, value)
+}
+
+// Update replaces the value of a present key.
+func (t *SparseMerkleTree) Update(key, value Bytes32) error {
+	if _, ok := t.leaves[key]; !ok {
+		return ErrLeafNotInTree
+	}
+	return t.set(key, value)
+}
+
+// Delete empties the slot of a present key.
+func (t *SparseMerkleTree) Delete(key Bytes32) error {
+	if _, ok := t.leaves[key]; !ok {
+		return ErrLeafNotInTree
+	}
+	delete(t.leaves, key)
+	t.update(key, Bytes32{})
+	return nil
+}
+
+func (t *SparseMerkleTree) set(key, value Bytes32) error {
+	if value.IsZero() {
+		return ErrZeroValue
+	}
+	t.leaves[key] = value
+	t.update(key, sparseLeaf(key, value))
+	return nil
+}
+
+// update stores leaf in key's slot and rehashes the path to the root.
+func (t *SparseMerkleTree) update(key, leaf Bytes32) {
+	cur, prefix := leaf, key
+	for h := 0; ; h++ {
+		n := sparseNode{h, prefix}
+		if cur.IsZero() {
+			delete(t.nodes, n)
+		} else {
+			t.nodes[n] = cur
+		}
+		if h == 256 {
+			return
+		}
+		sib := t.nodes[sparseNode{h, flipBit(prefix, h)}]
+		cur = sparseNodeHash(keyBit(key, h), cur, sib)
+		prefix = clearBit(prefix, h)
+	}
+}
+
+// Prove returns the proof for key's slot, which shows membership if key is
+// present and non-membership otherwise.
+func (t *SparseMerkleTree) Prove(key Bytes32) SparseProof {
+	var bitmap Bytes32
+	siblings := []string{}
+	prefix := key
+	for h := range 256 {
+		if sib := t.nodes[sparseNode{h, flipBit(prefix, h)}]; !sib.IsZero() {
+			bitmap = flipBit(bitmap, h)
+			siblings = append(siblings, sib.Hex())
+		}
+		prefix = clearBit(prefix, h)
+	}
+	return SparseProof{Bitmap: bitmap.Hex(), Siblings: siblings}
+}
+
+// VerifySparseMembership checks that proof shows key holding value under root.
+func VerifySparseMembership(root, key, value Bytes32, proof SparseProof) (bool, error) {
+	if value.IsZero() {
+		return false, ErrZeroValue
+	}
+	return verifySparse(root, key, sparseLeaf(key, value), proof)
+}
+
+// VerifySparseNonMembership checks that proof shows key's slot empty under
+// root.
+func VerifySparseNonMembership(root, key Bytes32, proof SparseProof) (bool, error) {
+	return verifySparse(root, key, Bytes32{}, proof)
+}
+
+func verifySparse(root, key, leaf Bytes32, proof SparseProof) (bool, error) {
+	bitmap, err := HexToBytes32(proof.Bitmap)
+	if err != nil {
+		return false, err
+	}
+	cur, used := leaf, 0
+	for h := range 256 {
+		var sib Bytes32
+		if keyBit(bitmap, h) {
+			if used == len(proof.Siblings) {
+				return false, ErrInvalidProof
+			}
+			if sib, err = HexToBytes32(proof.Siblings[used]); err != nil {
+				return false, err
+			}
+			used++
+		}
+		cur = sparseNodeHash(keyBit(key, h), cur, sib)
+	}
+	if used != len(proof.Siblings) {
+		return false, ErrInvalidProof
+	}
+	return cur == root, nil
+}
+
+func sparseLeaf(key, value Bytes32) Bytes32 { return HashLeaf(append(key[:], value[:]...)) }
+
+// sparseNodeHash hashes cur with its sibling, cur being the right child if
+// right is set. Two empty children give an empty parent.
+func sparseNodeHash(right bool, cur, sib Bytes32) Bytes32 {
+	if cur.IsZero() && sib.IsZero() {
+		return Bytes32{}
+	}
+	if right {
+		cur, sib = sib, cur
+	}
+	return keccakPair(cur, sib)
+}
+
+// keyBit returns bit i of key, counted from the least significant bit of the
+// big-endian value.
+func keyBit(key Bytes32, i int) bool { return key[31-i/8]>>(i%8)&1 == 1 }
+
+func flipBit(key Bytes32, i int) Bytes32 {
+	key[31-i/8] ^= 1 << (i % 8)
+	return key
+}
+
+func clearBit(key Bytes32, i int) Bytes32 {
+	key[31-i/8] &^= 1 << (i % 8)
+	return key
+}
@@ -0,0 +1,128 @@
+package gomerk_test
+
+import (
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestSparseMerkleTreeProofs(t *testing.T) {
+	keys := simpleLeaves(20)
+	tree := gomerk.NewSparseMerkleTree()
+	if !tree.Root().IsZero() {
+		t.Error("empty tree should have the zero root")
+	}
+	for i, k := range keys[:10] {
+		if err := tree.Insert(k, gomerk.Keccak256([]byte{byte(i), 1})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := tree.Root()
+
+	for i, k := range keys[:10] {
+		v, ok := tree.Get(k)
+		if !ok {
+			t.Fatalf("key %d missing", i)
+		}
+		proof := tree.Prove(k)
+		if ok, err := gomerk.VerifySparseMembership(root, k, v, proof); err != nil || !ok {
+			t.Errorf("key %d: membership got (%v, %v)", i, ok, err)
+		}
+		if ok, _ := gomerk.VerifySparseNonMembership(root, k, proof); ok {
+			t.Errorf("key %d: present key proven absent", i)
+		}
+		if ok, _ := gomerk.VerifySparseMembership(root, k, gomerk.Keccak256([]byte{99}), proof); ok {
+			t.Errorf("key %d: wrong value accepted", i)
+		}
+	}
+	for i, k := range keys[10:] {
+		proof := tree.Prove(k)
+		if ok, err := gomerk.VerifySparseNonMembership(root, k, proof); err != nil || !ok {
+			t.Errorf("absent key %d: non-membership got (%v, %v)", i, ok, err)
+		}
+		// A non-membership proof for one empty slot must not work for a
+		// present key.
+		if ok, _ := gomerk.VerifySparseNonMembership(root, keys[0], proof); ok {
+			t.Errorf("absent key %d: proof replayed for a present key", i)
+		}
+	}
+}
+
+func TestSparseMerkleTreeMutations(t *testing.T) {
+	keys := simpleLeaves(6)
+	val := func(i int) gomerk.Bytes32 { return gomerk.Keccak256([]byte{byte(i), 2}) }
+
+	a, b := gomerk.NewSparseMerkleTree(), gomerk.NewSparseMerkleTree()
+	for i := range keys {
+		a.Insert(keys[i], val(i))
+		b.Insert(keys[len(keys)-1-i], val(len(keys)-1-i))
+	}
+	if a.Root() != b.Root() {
+		t.Error("root should not depend on insertion order")
+	}
+	if a.Len() != len(keys) {
+		t.Errorf("len = %d, want %d", a.Len(), len(keys))
+	}
+
+	before := a.Root()
+	if err := a.Update(keys[2], val(9)); err != nil {
+		t.Fatal(err)
+	}
+	if a.Root() == before {
+		t.Error("update should change the root")
+	}
+	a.Update(keys[2], val(2))
+	if a.Root() != before {
+		t.Error("restoring the value should restore the root")
+	}
+
+	if err := a.Delete(keys[5]); err != nil {
+		t.Fatal(err)
+	}
+	c := gomerk.NewSparseMerkleTree()
+	for i := range 5 {
+		c.Insert(keys[i], val(i))
+	}
+	if a.Root() != c.Root() {
+		t.Error("delete should match a tree built without the key")
+	}
+	for i := range 5 {
+		a.Delete(keys[i])
+	}
+	if !a.Root().IsZero() || a.Len() != 0 {
+		t.Error("deleting every key should empty the tree")
+	}
+
+	if err := b.Insert(keys[0], val(0)); err != gomerk.ErrDuplicatedID {
+		t.Errorf("got %v, want ErrDuplicatedID", err)
+	}
+	if err := c.Update(keys[5], val(5)); err != gomerk.ErrLeafNotInTree {
+		t.Errorf("got %v, want ErrLeafNotInTree", err)
+	}
+	if err := c.Delete(keys[5]); err != gomerk.ErrLeafNotInTree {
+		t.Errorf("got %v, want ErrLeafNotInTree", err)
+	}
+	if err := c.Insert(keys[5], gomerk.Bytes32{}); err != gomerk.ErrZeroValue {
+		t.Errorf("got %v, want ErrZeroValue", err)
+	}
+}
+
+func TestSparseProofMalformed(t *testing.T) {
+	keys := simpleLeaves(3)
+	tree := gomerk.NewSparseMerkleTree()
+	tree.Insert(keys[0], keys[1])
+	tree.Insert(keys[1], keys[2])
+
+	proof := tree.Prove(keys[0])
+	if len(proof.Siblings) == 0 {
+		t.Fatal("expected a non-empty sibling")
+	}
+	short := gomerk.SparseProof{Bitmap: proof.Bitmap, Siblings: nil}
+	if _, err := gomerk.VerifySparseMembership(tree.Root(), keys[0], keys[1], short); err != gomerk.ErrInvalidProof {
+		t.Errorf("got %v, want ErrInvalidProof", err)
+	}
+	long := gomerk.SparseProof{Bitmap: proof.Bitmap, Siblings: append(proof.Siblings, proof.Siblings[0])}
+	if _, err := gomerk.VerifySparseMembership(tree.Root(), keys[0], keys[1], long); err != gomerk.ErrInvalidProof {
+		t.Errorf("got %v, want ErrInvalidProof", err)
+	}
+}
@@ -1,53 +1,213 @@
 package gomerk
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"iter"
+	"math"
 	"math/big"
+	"reflect"
 	"slices"
+	"strconv"
 	"strings"
+	"unsafe"
 )
 
+// FormatStandardV1 is the Format of serialized StandardMerkleTree data.
+const FormatStandardV1 = "standard-v1"
+
 // StandardValue holds a leaf value and its tree index.
 type StandardValue struct {
 	Value     []any `json:"value"`
 	TreeIndex int   `json:"treeIndex"`
 }
 
+// StandardEntry holds a value together with its index and leaf hash.
+type StandardEntry struct {
+	Index    int
+	Value    []any
+	LeafHash Bytes32
+}
+
 // StandardTreeData is the serialization format for StandardMerkleTree.
 type StandardTreeData struct {
 	Format       string          `json:"format"`
 	LeafEncoding []string        `json:"leafEncoding"`
 	Tree         []string        `json:"tree"`
 	Values       []StandardValue `json:"values"`
+
+	// NumberEncoding is empty for the EVM's big-endian numbers or
+	// NumberEncodingLittleEndian for trees built with WithLittleEndianNumbers.
+	NumberEncoding string `json:"numberEncoding,omitempty"`
+
+	// Hasher is the ID of the tree's Hasher, empty for Keccak256Hasher.
+	Hasher string `json:"hasher,omitempty"`
+
+	// Encoding is empty for double-hashed abi.encode leaves, EncodingPacked
+	// for trees built with WithPackedLeaves or EncodingFullABI for trees
+	// built with WithFullABIEncoding.
+	Encoding string `json:"encoding,omitempty"`
+
+	// EIP712 is the leaf type of trees built with WithEIP712Leaves.
+	EIP712 *EIP712Type `json:"eip712,omitempty"`
+
+	// Unsorted is set for trees built with WithSortLeaves(false). Without it
+	// a tree is loaded as sorted if its leaves are in hash order.
+	Unsorted bool `json:"unsorted,omitempty"`
 }
 
+// UnmarshalJSON decodes numbers in values as json.Number rather than float64,
+// so integers beyond 2^53 keep their exact value.
+func (d *StandardTreeData) UnmarshalJSON(b []byte) error {
+	type plain StandardTreeData
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode((*plain)(d))
+}
+
+// NumberEncodingLittleEndian marks dumps of trees whose numeric fields are
+// encoded little-endian.
+const NumberEncodingLittleEndian = "little-endian"
+
+// EncodingPacked marks dumps of trees whose leaves are hashed once from their
+// abi.encodePacked encoding.
+const EncodingPacked = "packed"
+
+// EncodingFullABI marks dumps of trees whose string and bytes fields are
+// abi-encoded in full rather than as their hash.
+const EncodingFullABI = "full-abi"
+
 // StandardMerkleTree is a Merkle tree for ABI-encoded structured data.
 type StandardMerkleTree struct {
-	tree         []string
+	tree         []Bytes32
 	values       []StandardValue
 	leafEncoding []string
+	sortLeaves   bool
+	opts         options
+	index        map[Bytes32]int
+}
+
+// NewStandardMerkleTree creates a new StandardMerkleTree. It is
+// BuildStandardMerkleTree with WithSortLeaves(sortLeaves) applied first.
+func NewStandardMerkleTree(values [][]any, leafEncoding []string, sortLeaves bool, opts ...Option) (*StandardMerkleTree, error) {
+	return BuildStandardMerkleTree(values, leafEncoding, append([]Option{WithSortLeaves(sortLeaves)}, opts...)...)
+}
+
+// BuildStandardMerkleTree creates a new StandardMerkleTree configured by
+// opts, sorting leaves unless WithSortLeaves(false) is given.
+func BuildStandardMerkleTree(values [][]any, leafEncoding []string, opts ...Option) (*StandardMerkleTree, error) {
+	return BuildStandardMerkleTreeContext(context.Background(), values, leafEncoding, opts...)
+}
+
+// BuildStandardMerkleTreeContext is BuildStandardMerkleTree, returning ctx's
+// error if it is done before the tree is built. Use WithProgress to follow
+// the build.
+func BuildStandardMerkleTreeContext(ctx context.Context, values [][]any, leafEncoding []string, opts ...Option) (*StandardMerkleTree, error) {
+	o := newOptions(opts)
+	if o.eip712 != nil {
+		if o.salted {
+			return nil, fmt.Errorf("%w: salted EIP-712 leaves", ErrUnsupportedType)
+		}
+		enc := o.eip712.LeafEncoding()
+		if leafEncoding == nil {
+			leafEncoding = enc
+		} else if !slices.Equal(leafEncoding, enc) {
+			return nil, fmt.Errorf("%w: leaf encoding %v does not match EIP-712 type %v", ErrMismatchedCount, leafEncoding, enc)
+		}
+	}
+	if o.salted {
+		var err error
+		if values, err = saltValues(o.saltSource, values); err != nil {
+			return nil, err
+		}
+		leafEncoding = append([]string{"bytes32"}, leafEncoding...)
+	}
+	t := &StandardMerkleTree{leafEncoding: leafEncoding, sortLeaves: !o.unsorted, opts: o}
+	if err := t.buildContext(ctx, values); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// saltValues returns values with a random salt read from r prepended to each.
+func saltValues(r io.Reader, values [][]any) ([][]any, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	salted := make([][]any, len(values))
+	var salt Bytes32
+	for i, v := range values {
+		if _, err := io.ReadFull(r, salt[:]); err != nil {
+			return nil, fmt.Errorf("reading salt: %w", err)
+		}
+		salted[i] = append([]any{salt.Hex()}, v...)
+	}
+	return salted, nil
 }
 
-// NewStandardMerkleTree creates a new StandardMerkleTree.
-func NewStandardMerkleTree(values [][]any, leafEncoding []string, sortLeaves bool) (*StandardMerkleTree, error) {
+// Rebuild replaces the tree contents with values, reusing the existing node and
+// value storage where capacity allows. The result is identical to a fresh
+// NewStandardMerkleTree with the same encoding and sort setting. Proofs and
+// slices previously obtained from the tree must not be used after a rebuild.
+// On error the tree is left unchanged.
+func (t *StandardMerkleTree) Rebuild(values [][]any) error { return t.build(values) }
+
+func (t *StandardMerkleTree) build(values [][]any) error {
+	return t.buildContext(context.Background(), values)
+}
+
+func (t *StandardMerkleTree) buildContext(ctx context.Context, values [][]any) error {
 	type hashed struct {
 		value []any
 		hash  Bytes32
 		index int
 	}
 
+	if len(values) == 0 {
+		return ErrEmptyTree
+	}
+
 	items := make([]hashed, len(values))
-	for i, v := range values {
-		h, err := encodeAndHash(leafEncoding, v)
+	errs := make([]error, len(values))
+	codec := t.opts.codec()
+	bp := &buildProgress{ctx: ctx, fn: t.opts.progress, p: Progress{Leaves: len(values)}}
+	parallelFor(len(values), t.opts.parallelism, func(lo, hi int) {
+		for i := lo; i < hi; i += progressStep {
+			end := min(i+progressStep, hi)
+			for j := i; j < end; j++ {
+				h, err := codec.encodeAndHash(t.leafEncoding, values[j])
+				items[j], errs[j] = hashed{values[j], h, j}, err
+			}
+			if bp.hashed(end-i) != nil {
+				return
+			}
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for i, err := range errs {
 		if err != nil {
-			return nil, err
+			return withValueIndex(err, i)
 		}
-		items[i] = hashed{v, h, i}
+	}
+	if t.opts.rejectDuplicates {
+		if err := findDuplicate(items, func(it hashed) Bytes32 { return it.hash }); err != nil {
+			return err
+		}
+	}
+	if t.opts.dedup {
+		items = dedupLeaves(items, func(it hashed) Bytes32 { return it.hash }, func(it *hashed, i int) { it.index = i })
 	}
 
-	if sortLeaves {
+	if t.sortLeaves {
 		slices.SortFunc(items, func(a, b hashed) int { return a.hash.Compare(b.hash) })
 	}
 
@@ -56,40 +216,174 @@ func NewStandardMerkleTree(values [][]any, leafEncoding []string, sortLeaves boo
 		leaves[i] = it.hash
 	}
 
-	tree, err := MakeTree(leaves)
+	nodes, err := makeNodesProgress(t.opts.hash(), t.tree, leaves, t.opts.parallelism, bp)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	t.tree = nodes
 
-	vals := make([]StandardValue, len(items))
+	t.values = slices.Grow(t.values[:0], len(items))[:len(items)]
 	for i, it := range items {
-		vals[it.index] = StandardValue{
-			Value:     it.value,
-			TreeIndex: len(tree) - 1 - i,
+		t.values[it.index] = StandardValue{TreeIndex: len(t.tree) - 1 - i}
+		if !t.opts.dropValues {
+			t.values[it.index].Value = it.value
 		}
 	}
+	t.indexLeaves()
+	return nil
+}
 
-	return &StandardMerkleTree{tree: tree, values: vals, leafEncoding: leafEncoding}, nil
+// indexLeaves rebuilds the leaf hash lookup used to find values.
+func (t *StandardMerkleTree) indexLeaves() {
+	t.index = indexLeaves(t.tree, len(t.values), t.treeIndex)
 }
 
 // LoadStandardMerkleTree loads a tree from serialized data.
 func LoadStandardMerkleTree(data StandardTreeData) (*StandardMerkleTree, error) {
-	if data.Format != "standard-v1" {
+	if data.Format != FormatStandardV1 {
 		return nil, ErrInvalidFormat
 	}
-	t := &StandardMerkleTree{tree: data.Tree, values: data.Values, leafEncoding: data.LeafEncoding}
+	tree, err := parseNodes(data.Tree)
+	if err != nil {
+		return nil, ErrInvariant
+	}
+	return loadStandard(data, tree)
+}
+
+// loadStandard loads a tree from data, whose nodes have already been parsed
+// into tree.
+func loadStandard(data StandardTreeData, tree []Bytes32) (*StandardMerkleTree, error) {
+	t := &StandardMerkleTree{tree: tree, values: data.Values, leafEncoding: data.LeafEncoding}
+	if err := t.opts.applySettings(data.Hasher, data.NumberEncoding, data.Encoding, data.EIP712, data.LeafEncoding); err != nil {
+		return nil, err
+	}
+	t.opts.dropValues = len(t.values) > 0 && !slices.ContainsFunc(t.values, func(v StandardValue) bool { return v.Value != nil })
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	t.opts.unsorted = data.Unsorted
+	t.sortLeaves = !data.Unsorted && leavesSorted(t.tree)
+	t.indexLeaves()
+	return t, nil
+}
+
+// applySettings sets the hasher and leaf encoding recorded in a dump or proof
+// bundle.
+func (o *options) applySettings(hasher, numberEncoding, encoding string, eip712 *EIP712Type, leafEncoding []string) error {
+	h, err := LookupHasher(hasher)
+	if err != nil {
+		return err
+	}
+	o.hasher = h
+	switch numberEncoding {
+	case "":
+	case NumberEncodingLittleEndian:
+		o.littleEndian = true
+	default:
+		return ErrInvalidFormat
+	}
+	switch encoding {
+	case "":
+	case EncodingPacked:
+		o.packed = true
+	case EncodingFullABI:
+		o.fullABI = true
+	default:
+		return ErrInvalidFormat
+	}
+	if eip712 != nil {
+		if !slices.Equal(eip712.LeafEncoding(), leafEncoding) {
+			return ErrInvalidFormat
+		}
+		o.eip712 = eip712
+	}
+	return nil
+}
+
+// encodingSettings returns the number and leaf encodings recorded in dumps
+// and proof bundles, empty for the defaults.
+func (o options) encodingSettings() (numberEncoding, encoding string) {
+	if o.littleEndian {
+		numberEncoding = NumberEncodingLittleEndian
+	}
+	if o.packed {
+		encoding = EncodingPacked
+	}
+	if o.fullABI {
+		encoding = EncodingFullABI
+	}
+	return numberEncoding, encoding
+}
+
+// AdoptStandardTree builds a StandardMerkleTree from an existing tree array
+// and its values without recomputing internal nodes. Each value is matched to
+// the leaf holding its hash; there must be exactly one value per leaf.
+func AdoptStandardTree(tree []string, values [][]any, leafEncoding []string, opts ...Option) (*StandardMerkleTree, error) {
+	if len(tree) == 0 {
+		return nil, ErrEmptyTree
+	}
+	if len(values) != (len(tree)+1)/2 {
+		return nil, ErrMismatchedCount
+	}
+	nodes, err := parseNodes(tree)
+	if err != nil {
+		return nil, ErrInvariant
+	}
+	t := &StandardMerkleTree{tree: nodes, leafEncoding: leafEncoding, opts: newOptions(opts)}
+
+	positions := make(map[Bytes32][]int, len(values))
+	for i := len(nodes) - 1; isLeafNode(len(nodes), i); i-- {
+		positions[nodes[i]] = append(positions[nodes[i]], i)
+	}
+	t.values = make([]StandardValue, len(values))
+	for i, v := range values {
+		h, err := t.opts.codec().encodeAndHash(leafEncoding, v)
+		if err != nil {
+			return nil, withValueIndex(err, i)
+		}
+		idx := positions[h]
+		if len(idx) == 0 {
+			return nil, fmt.Errorf("value %d: %w", i, ErrLeafNotInTree)
+		}
+		positions[h] = idx[1:]
+		t.values[i] = StandardValue{Value: v, TreeIndex: idx[0]}
+	}
+
 	if err := t.Validate(); err != nil {
 		return nil, err
 	}
+	t.sortLeaves = !t.opts.unsorted && leavesSorted(nodes)
+	t.indexLeaves()
+	if t.opts.dropValues {
+		for i := range t.values {
+			t.values[i].Value = nil
+		}
+	}
 	return t, nil
 }
 
-func (t *StandardMerkleTree) Root() string           { return t.tree[0] }
+func (t *StandardMerkleTree) Root() string           { return t.tree[0].Hex() }
+func (t *StandardMerkleTree) RootBytes() Bytes32     { return t.tree[0] }
 func (t *StandardMerkleTree) Len() int               { return len(t.values) }
 func (t *StandardMerkleTree) LeafEncoding() []string { return t.leafEncoding }
 
+// Hasher returns the hasher the tree was built with.
+func (t *StandardMerkleTree) Hasher() Hasher { return t.opts.hash() }
+
+// RootTruncated returns the first nBytes bytes of the root as hex, for
+// comparison with contracts that store a truncated root. nBytes is clamped
+// to 0..32.
+func (t *StandardMerkleTree) RootTruncated(nBytes int) string {
+	return t.Root()[:2+2*min(max(nBytes, 0), 32)]
+}
+
+// HasValues reports whether the tree retains its original values. It is
+// false for trees built with WithoutValues or loaded from a dump without
+// values, in which case At, All and LeafPreimage have nothing to return.
+func (t *StandardMerkleTree) HasValues() bool { return !t.opts.dropValues }
+
 func (t *StandardMerkleTree) At(i int) ([]any, bool) {
-	if i < 0 || i >= len(t.values) {
+	if i < 0 || i >= len(t.values) || !t.HasValues() {
 		return nil, false
 	}
 	return t.values[i].Value, true
@@ -98,6 +392,9 @@ func (t *StandardMerkleTree) At(i int) ([]any, bool) {
 // All returns an iterator over all (index, value) pairs.
 func (t *StandardMerkleTree) All() iter.Seq2[int, []any] {
 	return func(yield func(int, []any) bool) {
+		if !t.HasValues() {
+			return
+		}
 		for i, v := range t.values {
 			if !yield(i, v.Value) {
 				return
@@ -106,36 +403,252 @@ func (t *StandardMerkleTree) All() iter.Seq2[int, []any] {
 	}
 }
 
+// Entries returns an iterator over all values with their leaf hashes.
+func (t *StandardMerkleTree) Entries() iter.Seq[StandardEntry] {
+	return func(yield func(StandardEntry) bool) {
+		for i, v := range t.values {
+			if !yield(StandardEntry{Index: i, Value: v.Value, LeafHash: t.tree[v.TreeIndex]}) {
+				return
+			}
+		}
+	}
+}
+
+// LeafPreimage returns the bytes hashed into the leaf of the value at index,
+// as produced by the tree's leaf encoding before hashing. For trees built with
+// WithEIP712Leaves it is the input of the value's hashStruct, or of its typed
+// data digest if the type has a domain separator, and the leaf is its
+// keccak256.
+func (t *StandardMerkleTree) LeafPreimage(index int) ([]byte, error) {
+	if index < 0 || index >= len(t.values) {
+		return nil, ErrIndexOutOfBounds
+	}
+	if !t.HasValues() {
+		return nil, ErrValuesNotRetained
+	}
+	return t.preimage(t.values[index].Value)
+}
+
+// preimage returns the bytes hashed into the leaf of value.
+func (t *StandardMerkleTree) preimage(value []any) ([]byte, error) {
+	if t.opts.eip712 != nil {
+		return t.opts.eip712.leafPreimage(value)
+	}
+	return t.opts.codec().encodePacked(t.leafEncoding, value)
+}
+
 // Validate checks tree integrity.
 func (t *StandardMerkleTree) Validate() error {
 	for _, v := range t.values {
-		h, err := encodeAndHash(t.leafEncoding, v.Value)
+		if !isLeafNode(len(t.tree), v.TreeIndex) {
+			return ErrInvariant
+		}
+		if !t.HasValues() {
+			continue
+		}
+		h, err := t.opts.codec().encodeAndHash(t.leafEncoding, v.Value)
 		if err != nil {
 			return err
 		}
-		if t.tree[v.TreeIndex] != h.Hex() {
+		if t.tree[v.TreeIndex] != h {
 			return ErrInvariant
 		}
 	}
-	if !IsValidTree(t.tree) {
+	if !isValidNodes(t.opts.hash(), t.tree) {
 		return ErrInvariant
 	}
 	return nil
 }
 
+// RecomputeRoot rebuilds the tree from its values, leaf encoding and sort
+// setting and returns the resulting root, leaving t unchanged. A result that
+// differs from Root means the stored tree does not match its values.
+func (t *StandardMerkleTree) RecomputeRoot() (string, error) {
+	if !t.HasValues() {
+		return "", ErrValuesNotRetained
+	}
+	// Unsorted trees are rebuilt with each value at its current position, which
+	// UpdateLeafByIndex may have taken out of hash order.
+	values := make([][]any, len(t.values))
+	for i, v := range t.values {
+		if !t.sortLeaves {
+			i = len(t.tree) - 1 - v.TreeIndex
+		}
+		values[i] = v.Value
+	}
+	fresh := &StandardMerkleTree{leafEncoding: t.leafEncoding, sortLeaves: t.sortLeaves, opts: t.opts}
+	if err := fresh.build(values); err != nil {
+		return "", err
+	}
+	return fresh.Root(), nil
+}
+
+// SelfTest generates and verifies the proof of every value against the root,
+// returning an error naming the first index that fails. Proofs are checked
+// with their direction bits, so positionally hashed trees pass too.
+func (t *StandardMerkleTree) SelfTest() error {
+	for i, v := range t.values {
+		proof, path, err := t.GetProofWithPath(i)
+		if err != nil {
+			return fmt.Errorf("index %d: %w", i, err)
+		}
+		leaf := t.tree[v.TreeIndex]
+		if t.HasValues() {
+			if leaf, err = t.opts.codec().encodeAndHash(t.leafEncoding, v.Value); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		root, err := processProofWithPath(t.opts.hash(), leaf, proof, path)
+		if err != nil {
+			return fmt.Errorf("index %d: %w", i, err)
+		}
+		if root != t.Root() {
+			return fmt.Errorf("index %d: %w", i, ErrInvariant)
+		}
+	}
+	return nil
+}
+
+// LeafHash returns the leaf hash of value under the tree's encoding.
+func (t *StandardMerkleTree) LeafHash(value []any) (Bytes32, error) {
+	return t.opts.codec().encodeAndHash(t.leafEncoding, value)
+}
+
+// WouldCollide reports whether value hashes to an existing leaf, in which
+// case adding it would create a leaf indistinguishable from that one.
+func (t *StandardMerkleTree) WouldCollide(value []any) (bool, error) {
+	h, err := t.LeafHash(value)
+	if err != nil {
+		return false, err
+	}
+	return t.hashIndex(h) >= 0, nil
+}
+
+// Append adds values to the tree, giving them value indices from Len() on.
+// Only the new values are encoded and hashed, but every internal node is
+// recomputed, since adding leaves moves all of them in the tree's layout. In a
+// sorted tree the new leaves are merged into hash order, so existing values
+// may change tree index; otherwise they follow the existing leaves. With
+// WithDeduplication, values already in the tree are skipped; with
+// DuplicatesReject they fail the call. Proofs obtained before the call no
+// longer verify. On error the tree is left unchanged.
+func (t *StandardMerkleTree) Append(values ...[]any) error {
+	type hashed struct {
+		value []any
+		hash  Bytes32
+	}
+	items := make([]hashed, 0, len(values))
+	seen := make(map[Bytes32]int)
+	codec := t.opts.codec()
+	for i, v := range values {
+		h, err := codec.encodeAndHash(t.leafEncoding, v)
+		if err != nil {
+			return withValueIndex(err, i)
+		}
+		if t.opts.dedup || t.opts.rejectDuplicates {
+			j, ok := seen[h]
+			if !ok {
+				j = t.hashIndex(h)
+			}
+			if j >= 0 {
+				if t.opts.rejectDuplicates {
+					return &DuplicateError{Leaf: h, Indices: []int{j, len(t.values) + len(items)}}
+				}
+				continue
+			}
+			seen[h] = len(t.values) + len(items)
+		}
+		items = append(items, hashed{v, h})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	added := make([]Bytes32, len(items))
+	for k, it := range items {
+		added[k] = it.hash
+	}
+	n := len(t.values)
+	leaves, owners := mergeLeaves(t.tree, n, t.treeIndex, added, t.sortLeaves)
+	t.tree = makeNodes(t.opts.hash(), t.tree, leaves, t.opts.parallelism)
+	for _, it := range items {
+		v := StandardValue{}
+		if !t.opts.dropValues {
+			v.Value = it.value
+		}
+		t.values = append(t.values, v)
+	}
+	for pos, i := range owners {
+		t.values[i].TreeIndex = len(t.tree) - 1 - pos
+	}
+	t.indexLeaves()
+	return nil
+}
+
+// UpdateLeafByIndex replaces the value at index i with value, keeping its
+// position, and recomputes only the nodes on its path. It returns the new
+// root. Proofs obtained before the update may no longer verify. If the new
+// leaf breaks the tree's hash order, the tree counts as unsorted from then
+// on.
+func (t *StandardMerkleTree) UpdateLeafByIndex(i int, value []any) (string, error) {
+	if i < 0 || i >= len(t.values) {
+		return "", ErrIndexOutOfBounds
+	}
+	h, err := t.opts.codec().encodeAndHash(t.leafEncoding, value)
+	if err != nil {
+		return "", err
+	}
+	if j := t.hashIndex(h); t.opts.rejectDuplicates && j >= 0 && j != i {
+		return "", &DuplicateError{Leaf: h, Indices: []int{min(i, j), max(i, j)}}
+	}
+	ti := t.values[i].TreeIndex
+	old := t.tree[ti]
+	updateLeaf(t.opts.hash(), t.tree, ti, h)
+	if !t.opts.dropValues {
+		t.values[i].Value = value
+	}
+	t.sortLeaves = t.sortLeaves && leafInOrder(t.tree, ti)
+	reindexLeaf(t.index, t.tree, len(t.values), t.treeIndex, i, old)
+	return t.Root(), nil
+}
+
+// IndexOf returns the index of the first value equal to value under the
+// tree's encoding, in constant time.
+func (t *StandardMerkleTree) IndexOf(value []any) (int, error) { return t.leafIndex(value) }
+
+// IndicesOf returns the indices of all values equal to value under the
+// tree's encoding, in ascending order. It is as fast as IndexOf unless the
+// tree holds duplicate leaves.
+func (t *StandardMerkleTree) IndicesOf(value []any) ([]int, error) {
+	h, err := t.LeafHash(value)
+	if err != nil {
+		return nil, err
+	}
+	if out := leafIndices(t.index, t.tree, len(t.values), t.treeIndex, h); out != nil {
+		return out, nil
+	}
+	return nil, ErrLeafNotInTree
+}
+
 func (t *StandardMerkleTree) leafIndex(leaf []any) (int, error) {
-	h, err := encodeAndHash(t.leafEncoding, leaf)
+	h, err := t.LeafHash(leaf)
 	if err != nil {
 		return -1, err
 	}
-	for i, v := range t.values {
-		if t.tree[v.TreeIndex] == h.Hex() {
-			return i, nil
-		}
+	if i := t.hashIndex(h); i >= 0 {
+		return i, nil
 	}
 	return -1, ErrLeafNotInTree
 }
 
+// hashIndex returns the index of the first value with leaf hash h, or -1.
+func (t *StandardMerkleTree) hashIndex(h Bytes32) int {
+	if i, ok := t.index[h]; ok {
+		return i
+	}
+	return -1
+}
+
 // GetProof returns a proof for the given leaf.
 func (t *StandardMerkleTree) GetProof(leaf []any) ([]string, error) {
 	i, err := t.leafIndex(leaf)
@@ -150,24 +663,172 @@ func (t *StandardMerkleTree) GetProofByIndex(i int) ([]string, error) {
 	if i < 0 || i >= len(t.values) {
 		return nil, ErrIndexOutOfBounds
 	}
-	return GetProof(t.tree, t.values[i].TreeIndex)
+	return getProof(t.tree, t.values[i].TreeIndex)
 }
 
-// Verify checks if a leaf is in the tree using the given proof.
+// GetProofsByIndices returns the proofs of the values at indices, keyed by
+// index. It formats nodes shared by several proofs only once, so it is
+// faster than calling GetProofByIndex for each index.
+func (t *StandardMerkleTree) GetProofsByIndices(indices []int) (map[int][]string, error) {
+	return proofsByIndices(t.tree, len(t.values), t.treeIndex, indices)
+}
+
+// Verify checks if a leaf is in the tree using the given proof. With
+// positional hashing the direction bits are taken from the leaf's position.
 func (t *StandardMerkleTree) Verify(leaf []any, proof []string) (bool, error) {
-	h, err := encodeAndHash(t.leafEncoding, leaf)
+	h, err := t.opts.codec().encodeAndHash(t.leafEncoding, leaf)
 	if err != nil {
 		return false, err
 	}
-	root, err := ProcessProof(h, proof)
+	if !commutative(t.opts.hash()) {
+		nodes, err := parseNodes(proof)
+		if err != nil {
+			return false, err
+		}
+		return t.verifyPositional(h, nodes), nil
+	}
+	return verify(t.opts.hash(), t.Root(), h, proof)
+}
+
+func (t *StandardMerkleTree) verifyPositional(h Bytes32, proof []Bytes32) bool {
+	positions := leafPositions(t.index, t.tree, len(t.values), t.treeIndex, h)
+	return verifyPositional(t.opts.hash(), t.tree, positions, h, proof)
+}
+
+// GetProofWithPath returns the proof for the value at index with per-level
+// direction bits, for trees built with positional hashing. path[i] is true
+// when proof[i] is the left sibling.
+func (t *StandardMerkleTree) GetProofWithPath(i int) ([]string, []bool, error) {
+	if i < 0 || i >= len(t.values) {
+		return nil, nil, ErrIndexOutOfBounds
+	}
+	return getProofWithPath(t.tree, t.values[i].TreeIndex)
+}
+
+// Proofs returns an iterator over the proof of every value, by value index.
+// It is faster than calling GetProofByIndex for each value.
+func (t *StandardMerkleTree) Proofs() iter.Seq2[int, []string] {
+	return proofsSeq(t.tree, len(t.values), t.treeIndex)
+}
+
+// AllProofs returns the proof of every value, by value index, building them
+// on up to workers goroutines.
+func (t *StandardMerkleTree) AllProofs(workers int) [][]string {
+	return allProofs(t.tree, len(t.values), t.treeIndex, workers)
+}
+
+func (t *StandardMerkleTree) treeIndex(i int) int { return t.values[i].TreeIndex }
+
+// GetProofBytes returns the proof for the leaf at index as parsed nodes,
+// ready for VerifyParsed.
+func (t *StandardMerkleTree) GetProofBytes(i int) ([]Bytes32, error) {
+	if i < 0 || i >= len(t.values) {
+		return nil, ErrIndexOutOfBounds
+	}
+	return getProofBytes(t.tree, t.values[i].TreeIndex)
+}
+
+// VerifyParsed is like Verify but takes already-parsed proof nodes, skipping
+// hex decoding. Prefer it when proofs are kept as Bytes32 in process, such as
+// from GetProofBytes or a binary codec; use Verify for proofs received as hex.
+func (t *StandardMerkleTree) VerifyParsed(leaf []any, proof []Bytes32) (bool, error) {
+	h, err := t.opts.codec().encodeAndHash(t.leafEncoding, leaf)
 	if err != nil {
 		return false, err
 	}
-	return root == t.Root(), nil
+	if !commutative(t.opts.hash()) {
+		return t.verifyPositional(h, proof), nil
+	}
+	return processProofBytes(t.opts.hash(), h, proof) == t.RootBytes(), nil
+}
+
+// VerifyCompleteness reports whether values are exactly the tree's leaves,
+// with the same count and the same set of leaf hashes, in any order.
+func (t *StandardMerkleTree) VerifyCompleteness(values [][]any) (bool, error) {
+	if len(values) != t.Len() {
+		return false, nil
+	}
+	counts := make(map[Bytes32]int, len(t.values))
+	for _, v := range t.values {
+		counts[t.tree[v.TreeIndex]]++
+	}
+	for _, v := range values {
+		h, err := t.opts.codec().encodeAndHash(t.leafEncoding, v)
+		if err != nil {
+			return false, err
+		}
+		if counts[h] == 0 {
+			return false, nil
+		}
+		counts[h]--
+	}
+	return true, nil
+}
+
+// StandardMultiProof is a multiproof for a StandardMerkleTree that carries
+// leaf values instead of leaf hashes, as OpenZeppelin's JS library returns.
+// Leaves are in the order the proof consumes them.
+type StandardMultiProof struct {
+	Leaves     [][]any  `json:"leaves"`
+	Proof      []string `json:"proof"`
+	ProofFlags []bool   `json:"proofFlags"`
 }
 
-// GetMultiProofByIndices returns a proof for leaves at the given indices.
+// GetMultiProof returns a proof for multiple values, which are reordered to
+// match the proof. Multiproofs carry no direction bits, so positionally hashed
+// trees return ErrPositionalProof.
+func (t *StandardMerkleTree) GetMultiProof(leaves [][]any) (*StandardMultiProof, error) {
+	if !commutative(t.opts.hash()) {
+		return nil, ErrPositionalProof
+	}
+	treeIndices := make([]int, len(leaves))
+	for i, v := range leaves {
+		h, err := t.opts.codec().encodeAndHash(t.leafEncoding, v)
+		if err != nil {
+			return nil, withValueIndex(err, i)
+		}
+		j := t.hashIndex(h)
+		if j < 0 {
+			return nil, ErrLeafNotInTree
+		}
+		treeIndices[i] = t.values[j].TreeIndex
+	}
+	mp, err := getMultiProof(t.tree, treeIndices)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, len(leaves))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int { return treeIndices[b] - treeIndices[a] })
+	values := make([][]any, len(order))
+	for i, j := range order {
+		values[i] = leaves[j]
+	}
+	return &StandardMultiProof{Leaves: values, Proof: mp.Proof, ProofFlags: mp.ProofFlags}, nil
+}
+
+// VerifyMultiProofValues checks a multiproof carrying leaf values, encoding
+// them with the tree's leaf encoding.
+func (t *StandardMerkleTree) VerifyMultiProofValues(mp *StandardMultiProof) (bool, error) {
+	hashed := make([]string, len(mp.Leaves))
+	for i, v := range mp.Leaves {
+		h, err := t.opts.codec().encodeAndHash(t.leafEncoding, v)
+		if err != nil {
+			return false, withValueIndex(err, i)
+		}
+		hashed[i] = h.Hex()
+	}
+	return t.VerifyMultiProof(&MultiProof{Leaves: hashed, Proof: mp.Proof, ProofFlags: mp.ProofFlags})
+}
+
+// GetMultiProofByIndices returns a proof for leaves at the given indices. It
+// returns ErrPositionalProof for positionally hashed trees.
 func (t *StandardMerkleTree) GetMultiProofByIndices(indices []int) (*MultiProof, error) {
+	if !commutative(t.opts.hash()) {
+		return nil, ErrPositionalProof
+	}
 	for _, i := range indices {
 		if i < 0 || i >= len(t.values) {
 			return nil, ErrIndexOutOfBounds
@@ -177,73 +838,353 @@ func (t *StandardMerkleTree) GetMultiProofByIndices(indices []int) (*MultiProof,
 	for i, idx := range indices {
 		treeIndices[i] = t.values[idx].TreeIndex
 	}
-	return GetMultiProof(t.tree, treeIndices)
+	return getMultiProof(t.tree, treeIndices)
 }
 
 // VerifyMultiProof checks a multi-proof.
 func (t *StandardMerkleTree) VerifyMultiProof(mp *MultiProof) (bool, error) {
-	root, err := ProcessMultiProof(mp)
+	root, err := processMultiProof(t.opts.hash(), mp, nil)
 	if err != nil {
 		return false, err
 	}
 	return root == t.Root(), nil
 }
 
-// Dump serializes the tree.
+// AssertRootMatches fetches a deployed root through onchain and returns an
+// error wrapping ErrRootMismatch if it differs from the tree's root.
+func (t *StandardMerkleTree) AssertRootMatches(onchain func() (string, error)) error {
+	root, err := onchain()
+	if err != nil {
+		return fmt.Errorf("fetch onchain root: %w", err)
+	}
+	if !RootsEqual(root, t.Root()) {
+		return fmt.Errorf("%w: local %s, onchain %s", ErrRootMismatch, t.Root(), root)
+	}
+	return nil
+}
+
+// EstimatedMemoryBytes approximates the heap used by the tree: 32 bytes per
+// node, value slices with their string, byte and big.Int payloads, the leaf
+// encoding and the leaf lookup index. It excludes allocator and map bucket
+// overhead and unused slice capacity, and counts shared payloads once per
+// reference.
+func (t *StandardMerkleTree) EstimatedMemoryBytes() int {
+	n := int(unsafe.Sizeof(*t))
+	n += len(t.tree)*int(unsafe.Sizeof(Bytes32{})) + stringsMemory(t.leafEncoding)
+	n += len(t.index) * int(unsafe.Sizeof(Bytes32{})+unsafe.Sizeof(0))
+	n += len(t.values) * int(unsafe.Sizeof(StandardValue{}))
+	for _, v := range t.values {
+		for _, x := range v.Value {
+			n += valueMemory(x)
+		}
+	}
+	return n
+}
+
+func stringsMemory(ss []string) int {
+	n := len(ss) * int(unsafe.Sizeof(""))
+	for _, s := range ss {
+		n += len(s)
+	}
+	return n
+}
+
+func valueMemory(x any) int {
+	var iface any
+	n := int(unsafe.Sizeof(iface))
+	switch v := x.(type) {
+	case string:
+		n += int(unsafe.Sizeof(v)) + len(v)
+	case []byte:
+		n += int(unsafe.Sizeof(v)) + len(v)
+	case *big.Int:
+		n += int(unsafe.Sizeof(*v)) + len(v.Bits())*int(unsafe.Sizeof(big.Word(0)))
+	case []any:
+		n += int(unsafe.Sizeof(v))
+		for _, e := range v {
+			n += valueMemory(e)
+		}
+	case int, int64, uint64, float64:
+		n += 8
+	}
+	return n
+}
+
+// Dump serializes the tree. Integer values that JSON readers would round,
+// such as *big.Int amounts, are written as decimal strings.
 func (t *StandardMerkleTree) Dump() StandardTreeData {
-	return StandardTreeData{
-		Format:       "standard-v1",
+	data := StandardTreeData{
+		Format:       FormatStandardV1,
 		LeafEncoding: t.leafEncoding,
-		Tree:         t.tree,
+		Tree:         hexNodes(t.tree),
 		Values:       t.values,
 	}
+	cloned := false
+	for i, v := range t.values {
+		out, changed := canonicalFields(t.leafEncoding, v.Value)
+		if !changed {
+			continue
+		}
+		if !cloned {
+			data.Values, cloned = slices.Clone(t.values), true
+		}
+		data.Values[i].Value = out
+	}
+	data.NumberEncoding, data.Encoding = t.opts.encodingSettings()
+	data.Hasher = dumpHasherID(t.opts.hash())
+	data.EIP712 = t.opts.eip712
+	data.Unsorted = t.opts.unsorted
+	return data
+}
+
+// maxSafeJSONInt is the largest integer every JSON reader decodes exactly.
+const maxSafeJSONInt = 1 << 53
+
+// canonicalFields applies canonicalValue to each field of a value, reporting
+// whether any changed.
+func canonicalFields(types []string, vals []any) ([]any, bool) {
+	if len(types) != len(vals) {
+		return vals, false
+	}
+	var out []any
+	for i, typ := range types {
+		v, changed := canonicalValue(typ, vals[i])
+		if changed && out == nil {
+			out = slices.Clone(vals)
+		}
+		if out != nil {
+			out[i] = v
+		}
+	}
+	if out == nil {
+		return vals, false
+	}
+	return out, true
+}
+
+// canonicalValue returns an integer of type typ as a decimal string if it is
+// a *big.Int or beyond maxSafeJSONInt, and an address or bytes value given as
+// bytes as hex, recursing into arrays and tuples.
+func canonicalValue(typ string, v any) (any, bool) {
+	switch {
+	case typ == "address", strings.HasPrefix(typ, "bytes") && !strings.HasSuffix(typ, "]"):
+		if _, ok := v.(string); ok {
+			return v, false
+		}
+		if b, ok := byteArray(v); ok {
+			return "0x" + hex.EncodeToString(b), true
+		}
+	case strings.HasSuffix(typ, "]"), strings.HasSuffix(typ, ")"):
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return v, false
+		}
+		types, err := tupleTypes(typ)
+		if strings.HasSuffix(typ, "]") {
+			types, err = slices.Repeat([]string{typ[:strings.LastIndexByte(typ, '[')]}, rv.Len()), nil
+		}
+		if err != nil {
+			return v, false
+		}
+		elems := make([]any, rv.Len())
+		for i := range elems {
+			elems[i] = rv.Index(i).Interface()
+		}
+		return canonicalFields(types, elems)
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		switch n := v.(type) {
+		case *big.Int:
+			return n.String(), true
+		case int:
+			if int64(n) > maxSafeJSONInt || int64(n) < -maxSafeJSONInt {
+				return strconv.Itoa(n), true
+			}
+		case int64:
+			if n > maxSafeJSONInt || n < -maxSafeJSONInt {
+				return strconv.FormatInt(n, 10), true
+			}
+		case uint64:
+			if n > maxSafeJSONInt {
+				return strconv.FormatUint(n, 10), true
+			}
+		}
+	}
+	return v, false
+}
+
+// StandardProofsData is the proof set of a StandardMerkleTree keyed by one of
+// its fields, as produced by DumpProofs for web clients.
+type StandardProofsData struct {
+	Root         string               `json:"root"`
+	LeafEncoding []string             `json:"leafEncoding"`
+	Proofs       map[string]ProofItem `json:"proofs"`
+}
+
+// DumpProofs serializes the root, leaf encoding and the proof of every value
+// as compact JSON, keyed by the value's keyField field. The field must be an
+// address, string or bytes32, and its values unique; address and bytes32
+// keys are lowercased.
+func (t *StandardMerkleTree) DumpProofs(keyField int) ([]byte, error) {
+	if keyField < 0 || keyField >= len(t.leafEncoding) {
+		return nil, ErrIndexOutOfBounds
+	}
+	typ := t.leafEncoding[keyField]
+	if typ != "address" && typ != "string" && typ != "bytes32" {
+		return nil, fmt.Errorf("%w: key field type %s", ErrUnsupportedType, typ)
+	}
+	if !t.HasValues() {
+		return nil, ErrValuesNotRetained
+	}
+
+	data := StandardProofsData{Root: t.Root(), LeafEncoding: t.leafEncoding, Proofs: make(map[string]ProofItem, len(t.values))}
+	proofs := t.AllProofs(1)
+	for i, v := range t.values {
+		key, ok := v.Value[keyField].(string)
+		if !ok {
+			return nil, &EncodeError{Index: i, Field: keyField, Type: typ, Err: ErrAbiEncode}
+		}
+		if typ != "string" {
+			key = strings.ToLower(key)
+		}
+		if _, dup := data.Proofs[key]; dup {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatedID, key)
+		}
+		data.Proofs[key] = ProofItem{Value: v.Value, Proof: proofs[i]}
+	}
+	return json.Marshal(data)
 }
 
 // Render returns a string representation.
-func (t *StandardMerkleTree) Render() (string, error) { return RenderTree(t.tree) }
+func (t *StandardMerkleTree) Render() (string, error) { return RenderTree(hexNodes(t.tree)) }
 
 // VerifyStandard is a static verification function.
 func VerifyStandard(root string, leafEncoding []string, leaf []any, proof []string) (bool, error) {
-	h, err := encodeAndHash(leafEncoding, leaf)
+	h, err := leafCodec{}.encodeAndHash(leafEncoding, leaf)
 	if err != nil {
 		return false, err
 	}
-	r, err := ProcessProof(h, proof)
+	return Verify(root, h, proof)
+}
+
+// VerifyStandardDetailed is like VerifyStandard but also returns the leaf hash
+// and the root computed from the proof, for diagnosing failed verifications.
+func VerifyStandardDetailed(root string, leafEncoding []string, leaf []any, proof []string) (leafHash Bytes32, computedRoot string, ok bool, err error) {
+	leafHash, err = leafCodec{}.encodeAndHash(leafEncoding, leaf)
 	if err != nil {
-		return false, err
+		return leafHash, "", false, err
 	}
-	return r == root, nil
+	computedRoot, err = ProcessProof(leafHash, proof)
+	if err != nil {
+		return leafHash, "", false, err
+	}
+	return leafHash, computedRoot, computedRoot == root, nil
 }
 
 // ABI encoding helpers
 
-func encodeAndHash(types []string, values []any) (Bytes32, error) {
+// ABIEncodePacked returns the concatenated encoding of values that standard
+// leaves are hashed from: the abi.encode encoding of values, with string and
+// bytes values replaced by their Keccak256 hash. Dynamic arrays are encoded
+// with offsets and length words.
+func ABIEncodePacked(types []string, values []any) ([]byte, error) {
+	return leafCodec{}.encodePacked(types, values)
+}
+
+// leafCodec encodes leaf values, with settings that depart from the EVM
+// conventions for other ecosystems.
+type leafCodec struct {
+	littleEndian bool
+	packed       bool
+	hasher       Hasher
+	eip712       *EIP712Type
+
+	// calldata encodes strings and bytes in the tail as abi.encode does,
+	// rather than as their hash, for calldata and WithFullABIEncoding.
+	calldata bool
+}
+
+func (o options) codec() leafCodec {
+	return leafCodec{littleEndian: o.littleEndian, packed: o.packed, hasher: o.hash(), eip712: o.eip712, calldata: o.fullABI}
+}
+
+func (c leafCodec) encodePacked(types []string, values []any) ([]byte, error) {
 	if len(types) != len(values) {
-		return Bytes32{}, ErrMismatchedCount
+		return nil, &EncodeError{Index: -1, Field: -1, Err: ErrMismatchedCount}
+	}
+	encode := c.encodeValue
+	if c.packed {
+		encode = c.encodeTight
 	}
-	var buf []byte
+	encs := make([][]byte, len(types))
 	for i, typ := range types {
-		b, err := encodeValue(typ, values[i])
+		b, err := encode(typ, values[i])
 		if err != nil {
-			return Bytes32{}, err
+			return nil, &EncodeError{Index: -1, Field: i, Type: typ, Err: err}
 		}
-		buf = append(buf, b...)
+		encs[i] = b
+	}
+	if c.packed {
+		return slices.Concat(encs...), nil
+	}
+	return c.headTail(types, encs), nil
+}
+
+// withValueIndex records the index of the failing value in an EncodeError.
+func withValueIndex(err error, i int) error {
+	var e *EncodeError
+	if errors.As(err, &e) {
+		e.Index = i
 	}
-	return HashLeaf(buf), nil
+	return err
 }
 
-func encodeValue(typ string, val any) ([]byte, error) {
+func (c leafCodec) encodeAndHash(types []string, values []any) (Bytes32, error) {
+	if c.calldata && (c.packed || c.eip712 != nil) {
+		return Bytes32{}, fmt.Errorf("%w: full ABI encoding of packed or EIP-712 leaves", ErrUnsupportedType)
+	}
+	if c.eip712 != nil {
+		return c.eip712.leafHash(values)
+	}
+	buf, err := c.encodePacked(types, values)
+	if err != nil {
+		return Bytes32{}, err
+	}
+	if c.packed {
+		// Packed leaves are hashed once with keccak256 whatever the hasher,
+		// so only the keccak hashers describe them.
+		if c.hasher != nil && c.hasher.ID() != HasherKeccak256 && c.hasher.ID() != HasherKeccak256Positional {
+			return Bytes32{}, fmt.Errorf("%w: packed leaves with the %s hasher", ErrUnsupportedType, c.hasher.ID())
+		}
+		return Keccak256(buf), nil
+	}
+	if c.hasher == nil {
+		return HashLeaf(buf), nil
+	}
+	return c.hasher.LeafHash(buf), nil
+}
+
+func (c leafCodec) encodeValue(typ string, val any) ([]byte, error) {
 	out := make([]byte, 32)
 
 	switch {
+	case strings.HasSuffix(typ, "]") && arrayBase(typ) == "":
+		return nil, ErrUnsupportedType
+	case strings.HasSuffix(typ, "[]"):
+		return c.encodeDynamicArray(typ, val)
+	case strings.HasSuffix(typ, "]"):
+		return c.encodeFixedArray(typ, val)
+	case strings.HasSuffix(typ, ")"):
+		return c.encodeTuple(typ, val)
 	case typ == "address":
 		return encodeAddress(val)
 	case typ == "bytes32":
 		return encodeBytes32(val)
+	case strings.HasPrefix(typ, "bytes") && typ != "bytes":
+		return encodeFixedBytes(typ, val)
 	case strings.HasPrefix(typ, "uint"):
-		return encodeUint(val)
+		return c.numberOrder(encodeUint(typ, val))
 	case strings.HasPrefix(typ, "int"):
-		return encodeInt(val)
+		return c.numberOrder(encodeInt(typ, val))
 	case typ == "bool":
 		if b, ok := val.(bool); ok {
 			if b {
@@ -252,27 +1193,306 @@ func encodeValue(typ string, val any) ([]byte, error) {
 			return out, nil
 		}
 		return nil, ErrAbiEncode
+	case typ == "string":
+		s, ok := val.(string)
+		if !ok {
+			return nil, ErrAbiEncode
+		}
+		if c.calldata {
+			return encodeDynamicBytes([]byte(s)), nil
+		}
+		h := Keccak256([]byte(s))
+		return h[:], nil
+	case typ == "bytes":
+		if c.calldata {
+			data, err := bytesValue(val)
+			if err != nil {
+				return nil, err
+			}
+			return encodeDynamicBytes(data), nil
+		}
+		return encodeBytes(val)
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+// encodeDynamicBytes encodes data as abi.encode does for string and bytes:
+// a length word followed by the data padded to a multiple of 32 bytes.
+func encodeDynamicBytes(data []byte) []byte {
+	out := abiWord(len(data))
+	out = append(out, data...)
+	return append(out, make([]byte, (32-len(data)%32)%32)...)
+}
+
+// encodeTight encodes a value as abi.encodePacked does: numbers, addresses,
+// bools and bytesN in their own width, strings and bytes raw, and array
+// elements padded to 32 bytes without a length.
+func (c leafCodec) encodeTight(typ string, val any) ([]byte, error) {
+	switch {
+	case strings.HasSuffix(typ, "]"):
+		open := strings.LastIndexByte(typ, '[')
+		elem := typ[:open]
+		if arrayBase(elem) == "" || c.isDynamic(elem) || strings.HasSuffix(elem, ")") || elem == "string" || elem == "bytes" {
+			return nil, ErrUnsupportedType
+		}
+		// abi.encodePacked pads elements to words but omits a dynamic
+		// array's length, so both array kinds are their elements' words.
+		rv := reflect.ValueOf(val)
+		if typ[open+1:] == "]" {
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return nil, ErrAbiEncode
+			}
+			if rv.Len() == 0 {
+				return []byte{}, nil
+			}
+			return leafCodec{}.encodeFixedArray(fmt.Sprintf("%s[%d]", elem, rv.Len()), val)
+		}
+		return leafCodec{}.encodeValue(typ, val)
+	case strings.HasSuffix(typ, ")"):
+		return nil, ErrUnsupportedType
+	case typ == "address":
+		b, err := encodeAddress(val)
+		if err != nil {
+			return nil, err
+		}
+		return b[12:], nil
+	case typ == "bool":
+		b, err := c.encodeValue(typ, val)
+		if err != nil {
+			return nil, err
+		}
+		return b[31:], nil
 	case typ == "string":
 		if s, ok := val.(string); ok {
-			h := Keccak256([]byte(s))
-			return h[:], nil
+			return []byte(s), nil
 		}
 		return nil, ErrAbiEncode
 	case typ == "bytes":
-		return encodeBytes(val)
+		return bytesValue(val)
+	case strings.HasPrefix(typ, "bytes"):
+		b, err := encodeFixedBytes(typ, val)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := typeWidth(typ, "bytes")
+		return b[:n], nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		prefix, encode := "int", encodeInt
+		if strings.HasPrefix(typ, "uint") {
+			prefix, encode = "uint", encodeUint
+		}
+		b, err := encode(typ, val)
+		if err != nil {
+			return nil, err
+		}
+		width, _ := intWidth(typ, prefix)
+		return b[32-width/8:], nil
 	default:
-		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, typ)
+		return nil, ErrUnsupportedType
 	}
 }
 
+// arrayBase returns typ without its array suffixes, as uint8 for uint8[2][],
+// or the empty string if an array type has no element type.
+func arrayBase(typ string) string {
+	for strings.HasSuffix(typ, "]") {
+		open := strings.LastIndexByte(typ, '[')
+		if open < 0 {
+			return ""
+		}
+		typ = typ[:open]
+	}
+	return typ
+}
+
+// encodeFixedArray encodes a T[N] value, given as a slice or array of
+// exactly N elements, as abi.encode encodes N values of type T.
+func (c leafCodec) encodeFixedArray(typ string, val any) ([]byte, error) {
+	open := strings.LastIndexByte(typ, '[')
+	if open <= 0 {
+		return nil, ErrUnsupportedType
+	}
+	n, err := strconv.Atoi(typ[open+1 : len(typ)-1])
+	if err != nil || n <= 0 {
+		return nil, ErrUnsupportedType
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, ErrAbiEncode
+	}
+	if rv.Len() != n {
+		return nil, ErrAbiEncode
+	}
+	return c.encodeElements(slices.Repeat([]string{typ[:open]}, n), rv)
+}
+
+// encodeDynamicArray encodes a T[] value, given as a slice or array, as its
+// length word followed by the abi.encode encoding of its elements.
+func (c leafCodec) encodeDynamicArray(typ string, val any) ([]byte, error) {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, ErrAbiEncode
+	}
+	n := rv.Len()
+	out, err := c.encodeElements(slices.Repeat([]string{typ[:len(typ)-2]}, n), rv)
+	if err != nil {
+		return nil, err
+	}
+	return append(abiWord(n), out...), nil
+}
+
+// encodeElements encodes the elements of rv, a slice or array, with the
+// matching types using the abi.encode head and tail layout.
+func (c leafCodec) encodeElements(types []string, rv reflect.Value) ([]byte, error) {
+	encs := make([][]byte, len(types))
+	for i, t := range types {
+		b, err := c.encodeValue(t, rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		encs[i] = b
+	}
+	return c.headTail(types, encs), nil
+}
+
+// headTail lays out encoded values as abi.encode does: static values in
+// place, and dynamic values as an offset into a tail that follows the heads.
+// Without dynamic values this is plain concatenation.
+func (c leafCodec) headTail(types []string, encs [][]byte) []byte {
+	dynamic := make([]bool, len(types))
+	size, tailSize := 0, 0
+	for i, t := range types {
+		if dynamic[i] = c.isDynamic(t); dynamic[i] {
+			size += 32
+			tailSize += len(encs[i])
+		} else {
+			size += len(encs[i])
+		}
+	}
+	out := make([]byte, 0, size+tailSize)
+	offset := size
+	for i, enc := range encs {
+		if dynamic[i] {
+			out = append(out, abiWord(offset)...)
+			offset += len(enc)
+		} else {
+			out = append(out, enc...)
+		}
+	}
+	for i, enc := range encs {
+		if dynamic[i] {
+			out = append(out, enc...)
+		}
+	}
+	return out
+}
+
+// isDynamic reports whether abi.encode places values of typ in the tail.
+// Strings and bytes are static in leaves, which hash them to a single word.
+func (c leafCodec) isDynamic(typ string) bool {
+	switch {
+	case strings.HasSuffix(typ, "[]"):
+		return true
+	case strings.HasSuffix(typ, "]"):
+		return c.isDynamic(typ[:strings.LastIndexByte(typ, '[')])
+	case strings.HasSuffix(typ, ")"):
+		types, _ := tupleTypes(typ)
+		return slices.ContainsFunc(types, c.isDynamic)
+	case typ == "string", typ == "bytes":
+		return c.calldata
+	default:
+		return false
+	}
+}
+
+// abiWord encodes n as a big-endian 32-byte word, as used for offsets and
+// lengths.
+func abiWord(n int) []byte {
+	out := make([]byte, 32)
+	binary.BigEndian.PutUint64(out[24:], uint64(n))
+	return out
+}
+
+// encodeTuple encodes a (T1,...,Tn) value, given as a slice or array of n
+// components, as abi.encode does. A leading "tuple" keyword is accepted.
+func (c leafCodec) encodeTuple(typ string, val any) ([]byte, error) {
+	types, err := tupleTypes(typ)
+	if err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, ErrAbiEncode
+	}
+	if rv.Len() != len(types) {
+		return nil, ErrAbiEncode
+	}
+	return c.encodeElements(types, rv)
+}
+
+// tupleTypes splits a tuple type into its component types, keeping nested
+// tuples intact.
+func tupleTypes(typ string) ([]string, error) {
+	typ = strings.TrimPrefix(typ, "tuple")
+	if len(typ) < 2 || typ[0] != '(' || typ[len(typ)-1] != ')' {
+		return nil, ErrUnsupportedType
+	}
+	inner := typ[1 : len(typ)-1]
+	if inner == "" {
+		return nil, ErrUnsupportedType
+	}
+	var types []string
+	depth, start := 0, 0
+	for i, r := range inner {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, ErrUnsupportedType
+			}
+		case ',':
+			if depth == 0 {
+				types = append(types, inner[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, ErrUnsupportedType
+	}
+	types = append(types, inner[start:])
+	for _, t := range types {
+		if t == "" {
+			return nil, ErrUnsupportedType
+		}
+	}
+	return types, nil
+}
+
+// numberOrder converts a big-endian numeric word to the codec's byte order.
+func (c leafCodec) numberOrder(word []byte, err error) ([]byte, error) {
+	if err == nil && c.littleEndian {
+		slices.Reverse(word)
+	}
+	return word, err
+}
+
+// encodeAddress encodes an address given as hex or as 20 bytes, such as a
+// go-ethereum common.Address.
 func encodeAddress(val any) ([]byte, error) {
-	s, ok := val.(string)
-	if !ok {
+	var data []byte
+	if s, ok := val.(string); ok {
+		var err error
+		if data, err = hex.DecodeString(strings.TrimPrefix(s, "0x")); err != nil {
+			return nil, ErrAbiEncode
+		}
+	} else if data, ok = byteArray(val); !ok {
 		return nil, ErrAbiEncode
 	}
-	s = strings.TrimPrefix(s, "0x")
-	data, err := hex.DecodeString(s)
-	if err != nil || len(data) != 20 {
+	if len(data) != 20 {
 		return nil, ErrAbiEncode
 	}
 	out := make([]byte, 32)
@@ -285,69 +1505,158 @@ func encodeBytes32(val any) ([]byte, error) {
 	case string:
 		b, err := HexToBytes32(v)
 		return b[:], err
-	case []byte:
-		if len(v) != 32 {
+	default:
+		// Byte slices and arrays, such as Bytes32 and go-ethereum's
+		// common.Hash.
+		data, ok := byteArray(val)
+		if !ok {
+			return nil, ErrAbiEncode
+		}
+		if len(data) != 32 {
 			return nil, ErrInvalidNodeLength
 		}
-		return v, nil
+		return data, nil
+	}
+}
+
+// byteArray returns the contents of a byte slice or array of any named type.
+func byteArray(val any) ([]byte, bool) {
+	if b, ok := val.([]byte); ok {
+		return b, true
+	}
+	rv := reflect.ValueOf(val)
+	if (rv.Kind() != reflect.Array && rv.Kind() != reflect.Slice) || rv.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	data := make([]byte, rv.Len())
+	reflect.Copy(reflect.ValueOf(data), rv)
+	return data, true
+}
+
+// encodeFixedBytes encodes a bytesN value, given as hex or as a byte slice or
+// array of exactly N bytes, left-aligned in a 32-byte word.
+func encodeFixedBytes(typ string, val any) ([]byte, error) {
+	n, ok := typeWidth(typ, "bytes")
+	if !ok || n < 1 || n > 32 {
+		return nil, ErrUnsupportedType
+	}
+	var (
+		data []byte
+		err  error
+	)
+	switch v := val.(type) {
+	case string:
+		data, err = hex.DecodeString(strings.TrimPrefix(v, "0x"))
+		if err != nil {
+			return nil, ErrAbiEncode
+		}
 	default:
+		var ok bool
+		if data, ok = byteArray(val); !ok {
+			return nil, ErrAbiEncode
+		}
+	}
+	if len(data) != n {
 		return nil, ErrAbiEncode
 	}
+	out := make([]byte, 32)
+	copy(out, data)
+	return out, nil
+}
+
+// typeWidth parses the size suffix of a type such as bytes4 or uint64,
+// accepting only canonical decimal without sign or leading zeros.
+func typeWidth(typ, prefix string) (int, bool) {
+	digits := strings.TrimPrefix(typ, prefix)
+	if digits == "" || digits[0] == '0' || strings.TrimLeft(digits, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	return n, err == nil
+}
+
+// intWidth returns the bit width of a uintN or intN type, where the bare
+// prefix means 256 bits.
+func intWidth(typ, prefix string) (int, error) {
+	if typ == prefix {
+		return 256, nil
+	}
+	n, ok := typeWidth(typ, prefix)
+	if !ok || n%8 != 0 || n > 256 {
+		return 0, ErrUnsupportedType
+	}
+	return n, nil
 }
 
-func encodeUint(val any) ([]byte, error) {
+func encodeUint(typ string, val any) ([]byte, error) {
+	width, err := intWidth(typ, "uint")
+	if err != nil {
+		return nil, err
+	}
 	n, err := toBigInt(val)
 	if err != nil {
 		return nil, err
 	}
-	if n.Sign() < 0 {
-		return nil, ErrAbiEncode
+	if n.Sign() < 0 || n.BitLen() > width {
+		return nil, fmt.Errorf("%w: %s out of range for %s", ErrAbiEncode, n, typ)
 	}
 	out := make([]byte, 32)
-	b := n.Bytes()
-	if len(b) > 32 {
-		return nil, ErrAbiEncode
-	}
-	copy(out[32-len(b):], b)
+	n.FillBytes(out)
 	return out, nil
 }
 
-func encodeInt(val any) ([]byte, error) {
+func encodeInt(typ string, val any) ([]byte, error) {
+	width, err := intWidth(typ, "int")
+	if err != nil {
+		return nil, err
+	}
 	n, err := toBigInt(val)
 	if err != nil {
 		return nil, err
 	}
+	// Two's complement: -n-1 of a negative n needs the same bits as n.
+	mag := n
+	if n.Sign() < 0 {
+		mag = new(big.Int).Not(n)
+	}
+	if mag.BitLen() > width-1 {
+		return nil, fmt.Errorf("%w: %s out of range for %s", ErrAbiEncode, n, typ)
+	}
 	out := make([]byte, 32)
 	if n.Sign() >= 0 {
-		b := n.Bytes()
-		copy(out[32-len(b):], b)
+		n.FillBytes(out)
 	} else {
-		tc := new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
-		b := tc.Bytes()
+		mag.FillBytes(out)
 		for i := range out {
-			out[i] = 0xff
+			out[i] = ^out[i]
 		}
-		copy(out[32-len(b):], b)
 	}
 	return out, nil
 }
 
 func encodeBytes(val any) ([]byte, error) {
-	var data []byte
+	data, err := bytesValue(val)
+	if err != nil {
+		return nil, err
+	}
+	h := Keccak256(data)
+	return h[:], nil
+}
+
+// bytesValue returns the contents of a bytes value given as hex or a slice.
+func bytesValue(val any) ([]byte, error) {
 	switch v := val.(type) {
 	case string:
-		var err error
-		data, err = hex.DecodeString(strings.TrimPrefix(v, "0x"))
+		data, err := hex.DecodeString(strings.TrimPrefix(v, "0x"))
 		if err != nil {
 			return nil, ErrAbiEncode
 		}
+		return data, nil
 	case []byte:
-		data = v
+		return v, nil
 	default:
 		return nil, ErrAbiEncode
 	}
-	h := Keccak256(data)
-	return h[:], nil
 }
 
 func toBigInt(val any) (*big.Int, error) {
@@ -360,7 +1669,19 @@ func toBigInt(val any) (*big.Int, error) {
 	case uint64:
 		n.SetUint64(v)
 	case float64:
+		// A float64 from JSON has already lost precision beyond 2^53.
+		if v != math.Trunc(v) || math.Abs(v) > maxSafeJSONInt {
+			return nil, ErrAbiEncode
+		}
 		n.SetInt64(int64(v))
+	case json.Number:
+		// JSON numbers may use exponent notation, as JavaScript writes
+		// amounts of 1e21 and above.
+		r, ok := new(big.Rat).SetString(v.String())
+		if !ok || !r.IsInt() {
+			return nil, ErrAbiEncode
+		}
+		n.Set(r.Num())
 	case string:
 		s := strings.TrimPrefix(v, "0x")
 		base := 10
@@ -1,9 +1,16 @@
 package gomerk_test
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
 	"slices"
+	"strings"
 	"testing"
+	"unsafe"
 
 	"github.com/pyroth/gomerk"
 )
@@ -54,6 +61,50 @@ func TestStandardMerkleTreeSingle(t *testing.T) {
 	}
 }
 
+func TestStandardMerkleTreeTwoLeaves(t *testing.T) {
+	vals := airdropData(2)
+	tree, err := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := tree.Dump()
+	l, r := gomerk.MustHexToBytes32(data.Tree[1]), gomerk.MustHexToBytes32(data.Tree[2])
+	if tree.Root() != gomerk.HashNode(l, r).Hex() {
+		t.Error("root should be the hash of the two leaves")
+	}
+
+	for i, v := range vals {
+		proof, err := tree.GetProofByIndex(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sib := data.Tree[3-data.Values[i].TreeIndex]
+		if len(proof) != 1 || proof[0] != sib {
+			t.Errorf("index %d: proof = %v, want [%s]", i, proof, sib)
+		}
+		if ok, _ := tree.Verify(v, proof); !ok {
+			t.Errorf("index %d: verify failed", i)
+		}
+	}
+
+	mp, err := tree.GetMultiProofByIndices([]int{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mp.Leaves) != 2 || len(mp.Proof) != 0 || !slices.Equal(mp.ProofFlags, []bool{true}) {
+		t.Errorf("unexpected multiproof %+v", mp)
+	}
+	if ok, _ := tree.VerifyMultiProof(mp); !ok {
+		t.Error("multiproof verify failed")
+	}
+
+	s, _ := tree.Render()
+	want := "0) " + data.Tree[0] + "\n├─ 1) " + data.Tree[1] + "\n└─ 2) " + data.Tree[2]
+	if s != want {
+		t.Errorf("render:\n%s\nwant:\n%s", s, want)
+	}
+}
+
 func TestStandardMerkleTreeLeafEncoding(t *testing.T) {
 	enc := []string{"address", "uint256"}
 	tree, _ := gomerk.NewStandardMerkleTree(airdropData(4), enc, true)
@@ -200,6 +251,47 @@ func TestStandardMerkleTreeMultiProof(t *testing.T) {
 	}
 }
 
+func TestStandardMerkleTreeMultiProofByValues(t *testing.T) {
+	vals := airdropData(8)
+	tree, _ := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
+
+	req := [][]any{vals[6], vals[1], vals[3]}
+	mp, err := tree.GetMultiProof(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mp.Leaves) != len(req) {
+		t.Fatalf("got %d leaves, want %d", len(mp.Leaves), len(req))
+	}
+	for _, leaf := range mp.Leaves {
+		if !slices.ContainsFunc(req, func(v []any) bool { return v[0] == leaf[0] }) {
+			t.Errorf("unexpected leaf %v", leaf)
+		}
+	}
+	if ok, err := tree.VerifyMultiProofValues(mp); err != nil || !ok {
+		t.Errorf("got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	byIndex, _ := tree.GetMultiProofByIndices([]int{6, 1, 3})
+	if !slices.Equal(mp.Proof, byIndex.Proof) || !slices.Equal(mp.ProofFlags, byIndex.ProofFlags) {
+		t.Error("proof should match GetMultiProofByIndices")
+	}
+
+	swapped := *mp
+	swapped.Leaves = slices.Clone(mp.Leaves)
+	slices.Reverse(swapped.Leaves)
+	if ok, _ := tree.VerifyMultiProofValues(&swapped); ok {
+		t.Error("reordered leaves should not verify")
+	}
+
+	if _, err := tree.GetMultiProof([][]any{{"0x" + padAddr(99), 1}}); err != gomerk.ErrLeafNotInTree {
+		t.Errorf("got %v, want ErrLeafNotInTree", err)
+	}
+	if _, err := tree.GetMultiProof([][]any{vals[2], vals[2]}); err != gomerk.ErrDuplicatedIndex {
+		t.Errorf("got %v, want ErrDuplicatedIndex", err)
+	}
+}
+
 func TestStandardMerkleTreeDump(t *testing.T) {
 	vals := airdropData(4)
 	tree, _ := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
@@ -427,3 +519,1409 @@ func TestMultiProofJSON(t *testing.T) {
 		t.Error("JSON roundtrip failed")
 	}
 }
+
+func TestStandardMerkleTreeVerifyCompleteness(t *testing.T) {
+	vals := airdropData(6)
+	tree, _ := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
+
+	shuffled := slices.Clone(vals)
+	slices.Reverse(shuffled)
+	ok, err := tree.VerifyCompleteness(shuffled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("full value set should be complete")
+	}
+
+	if ok, _ := tree.VerifyCompleteness(vals[:5]); ok {
+		t.Error("truncated value set should not be complete")
+	}
+
+	dup := append(slices.Clone(vals[:5]), vals[0])
+	if ok, _ := tree.VerifyCompleteness(dup); ok {
+		t.Error("value set with a duplicate should not be complete")
+	}
+
+	if _, err := tree.VerifyCompleteness(append(vals[:5:5], []any{"bad"})); err == nil {
+		t.Error("expected encoding error")
+	}
+}
+
+func TestVerifyStandardDetailed(t *testing.T) {
+	vals := airdropData(4)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+
+	proof, _ := tree.GetProofByIndex(2)
+	leafHash, root, ok, err := gomerk.VerifyStandardDetailed(tree.Root(), enc, vals[2], proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || root != tree.Root() {
+		t.Error("detailed verify failed")
+	}
+	if !slices.Contains(tree.Dump().Tree, leafHash.Hex()) {
+		t.Error("leaf hash should be a tree node")
+	}
+
+	leafHash, root, ok, _ = gomerk.VerifyStandardDetailed(tree.Root(), enc, vals[1], proof)
+	if ok || root == tree.Root() || leafHash.IsZero() {
+		t.Error("wrong leaf should report its hash and a different root")
+	}
+}
+
+func TestStandardMerkleTreeRebuild(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	for _, sortLeaves := range []bool{true, false} {
+		tree, _ := gomerk.NewStandardMerkleTree(airdropData(8), enc, sortLeaves)
+
+		next := airdropData(5)
+		next[0][1] = 42
+		if err := tree.Rebuild(next); err != nil {
+			t.Fatal(err)
+		}
+		fresh, _ := gomerk.NewStandardMerkleTree(next, enc, sortLeaves)
+		if tree.Root() != fresh.Root() || tree.Len() != fresh.Len() {
+			t.Errorf("sort=%v: rebuilt tree differs from fresh tree", sortLeaves)
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatal(err)
+		}
+
+		root := tree.Root()
+		if err := tree.Rebuild([][]any{{"bad"}}); err == nil {
+			t.Error("expected encoding error")
+		}
+		if tree.Root() != root {
+			t.Error("failed rebuild should leave tree unchanged")
+		}
+	}
+}
+
+func TestABIEncodePacked(t *testing.T) {
+	word := func(b ...byte) []byte { return append(make([]byte, 32-len(b)), b...) }
+	ones := make([]byte, 32)
+	for i := range ones {
+		ones[i] = 0xff
+	}
+	addr := append(make([]byte, 12), slices.Repeat([]byte{0x11}, 20)...)
+	b32 := slices.Repeat([]byte{0x22}, 32)
+	strHash := gomerk.Keccak256([]byte("hello"))
+	bytesHash := gomerk.Keccak256([]byte{0x12, 0x34})
+
+	tests := []struct {
+		typ  string
+		val  any
+		want []byte
+	}{
+		{"address", "0x1111111111111111111111111111111111111111", addr},
+		{"bytes32", "0x" + strings.Repeat("22", 32), b32},
+		{"uint256", 258, word(1, 2)},
+		{"uint8", "0x10", word(0x10)},
+		{"int256", -1, ones},
+		{"int64", 5, word(5)},
+		{"bool", true, word(1)},
+		{"bool", false, word()},
+		{"string", "hello", strHash[:]},
+		{"bytes", "0x1234", bytesHash[:]},
+	}
+	for _, tc := range tests {
+		got, err := gomerk.ABIEncodePacked([]string{tc.typ}, []any{tc.val})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.typ, err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s(%v): got %x, want %x", tc.typ, tc.val, got, tc.want)
+		}
+	}
+
+	got, _ := gomerk.ABIEncodePacked([]string{"uint256", "bool"}, []any{1, true})
+	if !slices.Equal(got, append(word(1), word(1)...)) {
+		t.Errorf("concatenation: got %x", got)
+	}
+	if _, err := gomerk.ABIEncodePacked([]string{"uint256"}, nil); !errors.Is(err, gomerk.ErrMismatchedCount) {
+		t.Errorf("got %v, want ErrMismatchedCount", err)
+	}
+}
+
+func TestABIEncodeNumericBoundaries(t *testing.T) {
+	pow2 := func(n uint) *big.Int { return new(big.Int).Lsh(big.NewInt(1), n) }
+	dec := func(n *big.Int, d int64) *big.Int { return new(big.Int).Sub(n, big.NewInt(d)) }
+	hexWord := func(first byte, rest byte) []byte {
+		w := slices.Repeat([]byte{rest}, 32)
+		w[0] = first
+		return w
+	}
+
+	tests := []struct {
+		typ  string
+		val  *big.Int
+		want []byte
+	}{
+		{"uint256", dec(pow2(256), 1), hexWord(0xff, 0xff)},
+		{"uint256", big.NewInt(0), hexWord(0, 0)},
+		{"int256", new(big.Int).Neg(pow2(255)), hexWord(0x80, 0)},
+		{"int256", dec(pow2(255), 1), hexWord(0x7f, 0xff)},
+		{"int256", big.NewInt(-1), hexWord(0xff, 0xff)},
+	}
+	for _, tc := range tests {
+		got, err := gomerk.ABIEncodePacked([]string{tc.typ}, []any{tc.val})
+		if err != nil {
+			t.Fatalf("%s(%s): %v", tc.typ, tc.val, err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s(%s): got %x, want %x", tc.typ, tc.val, got, tc.want)
+		}
+	}
+
+	rejects := []struct {
+		typ string
+		val *big.Int
+	}{
+		{"uint256", pow2(256)},
+		{"uint256", big.NewInt(-1)},
+		{"int256", pow2(255)},
+		{"int256", dec(new(big.Int).Neg(pow2(255)), 1)},
+	}
+	for _, tc := range rejects {
+		if _, err := gomerk.ABIEncodePacked([]string{tc.typ}, []any{tc.val}); err == nil {
+			t.Errorf("%s(%s): expected error", tc.typ, tc.val)
+		}
+	}
+}
+
+func TestStandardMerkleTreeAssertRootMatches(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(4), []string{"address", "uint256"}, true)
+
+	onchain := func() (string, error) { return strings.ToUpper(tree.Root()[2:]), nil }
+	if err := tree.AssertRootMatches(onchain); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	stale := func() (string, error) { return tree.Dump().Tree[1], nil }
+	if err := tree.AssertRootMatches(stale); !errors.Is(err, gomerk.ErrRootMismatch) {
+		t.Errorf("got %v, want ErrRootMismatch", err)
+	}
+
+	rpcErr := errors.New("rpc unavailable")
+	failing := func() (string, error) { return "", rpcErr }
+	if err := tree.AssertRootMatches(failing); !errors.Is(err, rpcErr) {
+		t.Errorf("got %v, want wrapped rpc error", err)
+	}
+}
+
+func TestStandardMerkleTreeEntries(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(5), enc, true)
+
+	count := 0
+	for e := range tree.Entries() {
+		v, _ := tree.At(e.Index)
+		if !slices.Equal(e.Value, v) {
+			t.Errorf("entry %d: value mismatch", e.Index)
+		}
+		leafHash, _, _, _ := gomerk.VerifyStandardDetailed(tree.Root(), enc, e.Value, nil)
+		if leafHash != e.LeafHash {
+			t.Errorf("entry %d: leaf hash mismatch", e.Index)
+		}
+		count++
+	}
+	if count != 5 {
+		t.Errorf("got %d entries, want 5", count)
+	}
+}
+
+func TestStandardMerkleTreeSelfTest(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(7), []string{"address", "uint256"}, true)
+	if err := tree.SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Dump shares storage with the tree, so swapping two values here leaves
+	// each pointing at the other's leaf.
+	data := tree.Dump()
+	data.Values[2].Value, data.Values[5].Value = data.Values[5].Value, data.Values[2].Value
+	if err := tree.SelfTest(); !errors.Is(err, gomerk.ErrInvariant) || !strings.Contains(err.Error(), "index 2") {
+		t.Errorf("got %v, want ErrInvariant", err)
+	}
+}
+
+func TestStandardMerkleTreeWithoutValues(t *testing.T) {
+	vals := airdropData(6)
+	enc := []string{"address", "uint256"}
+	full, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithoutValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Root() != full.Root() || tree.Len() != full.Len() {
+		t.Error("tree without values should match full tree")
+	}
+
+	if _, ok := tree.At(0); ok {
+		t.Error("At should report false without values")
+	}
+	for range tree.All() {
+		t.Error("All should yield nothing without values")
+	}
+
+	for i, v := range vals {
+		proof, err := tree.GetProofByIndex(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, _ := tree.Verify(v, proof)
+		if !ok {
+			t.Errorf("index %d: verify failed", i)
+		}
+		byValue, _ := tree.GetProof(v)
+		if !slices.Equal(byValue, proof) {
+			t.Errorf("index %d: proof by value differs", i)
+		}
+	}
+	if err := tree.SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	js, _ := json.Marshal(tree.Dump())
+	var data gomerk.StandardTreeData
+	json.Unmarshal(js, &data)
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.At(0); ok || loaded.Root() != full.Root() {
+		t.Error("loaded tree should keep root and drop values")
+	}
+	if _, err := gomerk.IDMerkleTreeFrom(loaded, 1); err != gomerk.ErrValuesNotRetained {
+		t.Errorf("got %v, want ErrValuesNotRetained", err)
+	}
+}
+
+func TestABIEncodeIntegerWidths(t *testing.T) {
+	accepts := []struct {
+		typ string
+		val any
+	}{
+		{"uint8", 255},
+		{"uint", "0x" + strings.Repeat("ff", 32)},
+		{"uint64", uint64(1<<64 - 1)},
+		{"int8", -128},
+		{"int8", 127},
+		{"int16", -32768},
+		{"int", -1},
+	}
+	for _, tc := range accepts {
+		if _, err := gomerk.ABIEncodePacked([]string{tc.typ}, []any{tc.val}); err != nil {
+			t.Errorf("%s(%v): %v", tc.typ, tc.val, err)
+		}
+	}
+
+	rejects := []struct {
+		typ string
+		val any
+	}{
+		{"uint8", 256},
+		{"uint8", "1000000000000000000000000000000"},
+		{"uint32", int64(1 << 32)},
+		{"int8", 128},
+		{"int8", -129},
+		{"int64", "0x8000000000000000"},
+	}
+	for _, tc := range rejects {
+		if _, err := gomerk.ABIEncodePacked([]string{tc.typ}, []any{tc.val}); !errors.Is(err, gomerk.ErrAbiEncode) {
+			t.Errorf("%s(%v): got %v, want ErrAbiEncode", tc.typ, tc.val, err)
+		}
+	}
+
+	for _, typ := range []string{"uint7", "uint0", "uint264", "int12", "uint08", "integer"} {
+		if _, err := gomerk.ABIEncodePacked([]string{typ}, []any{1}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+			t.Errorf("%s: got %v, want ErrUnsupportedType", typ, err)
+		}
+	}
+	for _, typ := range []string{"[]", "[2]", "[][]", "[]["} {
+		if _, err := gomerk.ABIEncodePacked([]string{typ}, []any{[]any{}}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+			t.Errorf("%s: got %v, want ErrUnsupportedType", typ, err)
+		}
+		if _, err := gomerk.NewStandardMerkleTree([][]any{{[]any{}}}, []string{typ}, true, gomerk.WithPackedLeaves()); !errors.Is(err, gomerk.ErrUnsupportedType) {
+			t.Errorf("packed %s: got %v, want ErrUnsupportedType", typ, err)
+		}
+	}
+
+	vals := [][]any{{"0x" + padAddr(1), 1}, {"0x" + padAddr(2), 300}}
+	_, err := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint8"}, true)
+	if err == nil || err.Error() != "value 1: field 1 (uint8): abi encoding error: 300 out of range for uint8" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestStandardMerkleTreeEncodeError(t *testing.T) {
+	vals := airdropData(4)
+	vals[2] = []any{vals[2][0], "not a number"}
+	_, err := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
+
+	var encErr *gomerk.EncodeError
+	if !errors.As(err, &encErr) {
+		t.Fatalf("got %v, want EncodeError", err)
+	}
+	if encErr.Index != 2 || encErr.Field != 1 || encErr.Type != "uint256" {
+		t.Errorf("got index %d field %d type %q, want 2, 1, uint256", encErr.Index, encErr.Field, encErr.Type)
+	}
+	if !errors.Is(err, gomerk.ErrAbiEncode) {
+		t.Error("EncodeError should unwrap to ErrAbiEncode")
+	}
+	if err.Error() != "value 2: field 1 (uint256): abi encoding error" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	_, err = gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256", "fixed"}, true)
+	if !errors.As(err, &encErr) || encErr.Field != -1 || !errors.Is(err, gomerk.ErrMismatchedCount) {
+		t.Errorf("got %v, want EncodeError wrapping ErrMismatchedCount", err)
+	}
+}
+
+func TestStandardMerkleTreeEstimatedMemoryBytes(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	small, _ := gomerk.NewStandardMerkleTree(airdropData(4), enc, true)
+	large, _ := gomerk.NewStandardMerkleTree(airdropData(400), enc, true)
+
+	// Each leaf contributes at least its two 32-byte nodes and its
+	// 42-character address.
+	if got := large.EstimatedMemoryBytes(); got < 400*(2*32+42) {
+		t.Errorf("estimate %d is below the raw payload", got)
+	}
+	if small.EstimatedMemoryBytes() >= large.EstimatedMemoryBytes() {
+		t.Error("estimate should grow with the number of leaves")
+	}
+
+	dropped, _ := gomerk.NewStandardMerkleTree(airdropData(400), enc, true, gomerk.WithoutValues())
+	if dropped.EstimatedMemoryBytes() >= large.EstimatedMemoryBytes() {
+		t.Error("dropping values should reduce the estimate")
+	}
+
+	// Without values, a leaf adds two nodes, an index entry and a position.
+	more, _ := gomerk.NewStandardMerkleTree(airdropData(401), enc, true, gomerk.WithoutValues())
+	want := 2*32 + 32 + int(unsafe.Sizeof(0)) + int(unsafe.Sizeof(gomerk.StandardValue{}))
+	if got := more.EstimatedMemoryBytes() - dropped.EstimatedMemoryBytes(); got != want {
+		t.Errorf("estimate grows by %d bytes per leaf, want %d", got, want)
+	}
+}
+
+func TestStandardMerkleTreeFixedArray(t *testing.T) {
+	enc := []string{"address", "uint256[3]"}
+	vals := [][]any{
+		{"0x1111111111111111111111111111111111111111", []any{1, 2, 3}},
+		{"0x2222222222222222222222222222222222222222", []int{4, 5, 6}},
+		{"0x3333333333333333333333333333333333333333", [3]string{"7", "8", "9"}},
+	}
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vals {
+		proof, _ := tree.GetProof(v)
+		ok, _ := gomerk.VerifyStandard(tree.Root(), enc, v, proof)
+		if !ok {
+			t.Errorf("%v: verify failed", v)
+		}
+	}
+
+	got, _ := gomerk.ABIEncodePacked([]string{"uint256[3]"}, []any{[]any{1, 2, 3}})
+	want, _ := gomerk.ABIEncodePacked([]string{"uint256", "uint256", "uint256"}, []any{1, 2, 3})
+	if !slices.Equal(got, want) {
+		t.Error("fixed array should encode as consecutive words")
+	}
+
+	nested, _ := gomerk.ABIEncodePacked([]string{"uint8[2][2]"}, []any{[][]int{{1, 2}, {3, 4}}})
+	flat, _ := gomerk.ABIEncodePacked([]string{"uint8[4]"}, []any{[]int{1, 2, 3, 4}})
+	if !slices.Equal(nested, flat) {
+		t.Error("nested fixed arrays should flatten in order")
+	}
+
+	for _, bad := range []any{[]any{1, 2}, []any{1, 2, 3, 4}, 5} {
+		_, err := gomerk.ABIEncodePacked([]string{"uint256[3]"}, []any{bad})
+		if !errors.Is(err, gomerk.ErrAbiEncode) {
+			t.Errorf("%v: got %v, want ErrAbiEncode", bad, err)
+		}
+	}
+	if _, err := gomerk.ABIEncodePacked([]string{"uint256[0]"}, []any{[]any{}}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("got %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestStandardMerkleTreeTuple(t *testing.T) {
+	enc := []string{"(address,uint256,uint256)", "uint8"}
+	vals := [][]any{
+		{[]any{"0x1111111111111111111111111111111111111111", 100, 1700000000}, 1},
+		{[]any{"0x2222222222222222222222222222222222222222", "250", 1800000000}, 2},
+	}
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vals {
+		proof, _ := tree.GetProof(v)
+		if ok, _ := gomerk.VerifyStandard(tree.Root(), enc, v, proof); !ok {
+			t.Errorf("%v: verify failed", v)
+		}
+	}
+
+	got, _ := gomerk.ABIEncodePacked(enc, vals[0])
+	want, _ := gomerk.ABIEncodePacked(
+		[]string{"address", "uint256", "uint256", "uint8"},
+		[]any{"0x1111111111111111111111111111111111111111", 100, 1700000000, 1},
+	)
+	if !slices.Equal(got, want) {
+		t.Error("static tuple should encode as its components in order")
+	}
+
+	nested, err := gomerk.ABIEncodePacked(
+		[]string{"tuple(uint8,(bool,uint16[2]))[2]"},
+		[]any{[]any{[]any{1, []any{true, []int{2, 3}}}, []any{4, []any{false, []int{5, 6}}}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flat, _ := gomerk.ABIEncodePacked(
+		[]string{"uint8", "bool", "uint16", "uint16", "uint8", "bool", "uint16", "uint16"},
+		[]any{1, true, 2, 3, 4, false, 5, 6},
+	)
+	if !slices.Equal(nested, flat) {
+		t.Error("nested tuples and tuple arrays should flatten in order")
+	}
+
+	if _, err := gomerk.ABIEncodePacked([]string{"(uint8,bool)"}, []any{[]any{1}}); !errors.Is(err, gomerk.ErrAbiEncode) {
+		t.Errorf("got %v, want ErrAbiEncode", err)
+	}
+	for _, bad := range []string{"()", "(uint8,)", "(uint8))", "((uint8)"} {
+		if _, err := gomerk.ABIEncodePacked([]string{bad}, []any{[]any{1}}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+			t.Errorf("%s: got %v, want ErrUnsupportedType", bad, err)
+		}
+	}
+}
+
+func TestStandardMerkleTreeDynamicArray(t *testing.T) {
+	word := func(n byte) []byte { return append(make([]byte, 31), n) }
+	concat := func(words ...[]byte) []byte { return slices.Concat(words...) }
+	addr := append(make([]byte, 12), slices.Repeat([]byte{0x11}, 20)...)
+
+	enc := []string{"address", "uint256[]"}
+	vals := [][]any{
+		{"0x1111111111111111111111111111111111111111", []int{1, 2}},
+		{"0x2222222222222222222222222222222222222222", []any{}},
+		{"0x3333333333333333333333333333333333333333", []string{"7", "8", "9"}},
+	}
+	got, err := gomerk.ABIEncodePacked(enc, vals[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := concat(addr, word(0x40), word(2), word(1), word(2)); !slices.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vals {
+		proof, _ := tree.GetProof(v)
+		if ok, _ := gomerk.VerifyStandard(tree.Root(), enc, v, proof); !ok {
+			t.Errorf("%v: verify failed", v)
+		}
+	}
+
+	nested, _ := gomerk.ABIEncodePacked([]string{"uint256[][]"}, []any{[][]int{{1}, {2, 3}}})
+	want := concat(word(0x20), word(2), word(0x40), word(0x80), word(1), word(1), word(2), word(2), word(3))
+	if !slices.Equal(nested, want) {
+		t.Errorf("nested: got %x, want %x", nested, want)
+	}
+
+	tuple, _ := gomerk.ABIEncodePacked([]string{"(uint8,bytes32[])"}, []any{[]any{5, []string{"0x" + strings.Repeat("22", 32)}}})
+	want = concat(word(0x20), word(5), word(0x40), word(1), slices.Repeat([]byte{0x22}, 32))
+	if !slices.Equal(tuple, want) {
+		t.Errorf("dynamic tuple: got %x, want %x", tuple, want)
+	}
+
+	if _, err := gomerk.ABIEncodePacked([]string{"uint256[]"}, []any{5}); !errors.Is(err, gomerk.ErrAbiEncode) {
+		t.Errorf("got %v, want ErrAbiEncode", err)
+	}
+}
+
+func TestLittleEndianNumbers(t *testing.T) {
+	enc := []string{"uint256", "int64"}
+	vals := [][]any{{1, -2}, {0x0102, 3}, {big.NewInt(7), 0}}
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithLittleEndianNumbers())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	word := func(b ...byte) []byte { return append(b, make([]byte, 32-len(b))...) }
+	neg := bytes.Repeat([]byte{0xff}, 32)
+	neg[0] = 0xfe
+	want := gomerk.HashLeaf(slices.Concat(word(0x02, 0x01), word(3)))
+	if h := gomerk.HashLeaf(slices.Concat(word(1), neg)); tree.Dump().Tree[tree.Dump().Values[0].TreeIndex] != h.Hex() {
+		t.Errorf("value 0 leaf does not match little-endian encoding")
+	}
+	if tree.Dump().Tree[tree.Dump().Values[1].TreeIndex] != want.Hex() {
+		t.Errorf("value 1 leaf does not match little-endian encoding")
+	}
+
+	be, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	if be.Root() == tree.Root() {
+		t.Error("little-endian root should differ from big-endian root")
+	}
+
+	for i, v := range vals {
+		proof, _ := tree.GetProofByIndex(i)
+		if ok, err := tree.Verify(v, proof); err != nil || !ok {
+			t.Errorf("value %d: got (%v, %v), want (true, nil)", i, ok, err)
+		}
+		v2 := gomerk.NewVerifier(gomerk.WithLittleEndianNumbers())
+		if ok, _ := v2.VerifyStandard(tree.Root(), enc, v, proof); !ok {
+			t.Errorf("value %d: little-endian verifier rejected proof", i)
+		}
+		if ok, _ := gomerk.VerifyStandard(tree.Root(), enc, v, proof); ok {
+			t.Errorf("value %d: big-endian verification should fail", i)
+		}
+	}
+
+	data := tree.Dump()
+	if data.NumberEncoding != gomerk.NumberEncodingLittleEndian {
+		t.Fatalf("NumberEncoding = %q", data.NumberEncoding)
+	}
+	raw, _ := json.Marshal(data)
+	var decoded gomerk.StandardTreeData
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := gomerk.LoadStandardMerkleTree(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, _ := loaded.GetProof(vals[2])
+	if ok, _ := loaded.Verify(vals[2], proof); !ok {
+		t.Error("loaded tree rejected its own proof")
+	}
+
+	if raw, _ := json.Marshal(be.Dump()); strings.Contains(string(raw), "numberEncoding") {
+		t.Error("big-endian dump should omit numberEncoding")
+	}
+	data.NumberEncoding = "middle-endian"
+	if _, err := gomerk.LoadStandardMerkleTree(data); err != gomerk.ErrInvalidFormat {
+		t.Errorf("got %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestPackedLeaves(t *testing.T) {
+	enc := []string{"uint256", "address", "uint96"}
+	vals := [][]any{{0, "0x" + padAddr(1), 100}, {1, "0x" + padAddr(2), 200}, {2, "0x" + padAddr(3), 300}}
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithPackedLeaves())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// MerkleDistributor: keccak256(abi.encodePacked(index, account, amount)).
+	addr, _ := hex.DecodeString(padAddr(2))
+	packed := slices.Concat(append(make([]byte, 31), 1), addr, append(make([]byte, 11), 200))
+	want := gomerk.Keccak256(packed)
+	if got, _ := tree.LeafHash(vals[1]); got != want {
+		t.Errorf("leaf hash = %s, want %s", got, want)
+	}
+	if pre, _ := tree.LeafPreimage(1); !slices.Equal(pre, packed) {
+		t.Errorf("preimage = %x, want %x", pre, packed)
+	}
+
+	v := gomerk.NewVerifier(gomerk.WithPackedLeaves())
+	for _, val := range vals {
+		proof, _ := tree.GetProof(val)
+		if ok, err := v.VerifyStandard(tree.Root(), enc, val, proof); err != nil || !ok {
+			t.Errorf("%v: got (%v, %v)", val, ok, err)
+		}
+		if ok, _ := gomerk.VerifyStandard(tree.Root(), enc, val, proof); ok {
+			t.Errorf("%v: abi.encode verification should fail", val)
+		}
+	}
+
+	data := tree.Dump()
+	if data.Encoding != gomerk.EncodingPacked {
+		t.Fatalf("Encoding = %q", data.Encoding)
+	}
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, _ := loaded.GetProof(vals[0])
+	if ok, _ := loaded.Verify(vals[0], proof); !ok {
+		t.Error("loaded tree rejected its own proof")
+	}
+	data.Encoding = "rlp"
+	if _, err := gomerk.LoadStandardMerkleTree(data); err != gomerk.ErrInvalidFormat {
+		t.Errorf("got %v, want ErrInvalidFormat", err)
+	}
+
+	tight, err := gomerk.NewStandardMerkleTree([][]any{{"ab", "0x01", true, "0x1234", []int{1, 2}}},
+		[]string{"string", "bytes", "bool", "bytes2", "uint8[2]"}, false, gomerk.WithPackedLeaves())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pre, _ := tight.LeafPreimage(0)
+	if want := slices.Concat([]byte("ab"), []byte{1, 1, 0x12, 0x34}, append(make([]byte, 31), 1), append(make([]byte, 31), 2)); !slices.Equal(pre, want) {
+		t.Errorf("preimage = %x, want %x", pre, want)
+	}
+
+	// Dynamic arrays are packed without their length, as in Solidity.
+	dyn, err := gomerk.NewStandardMerkleTree([][]any{{[]int{1, 2}}, {[]int{}}}, []string{"uint256[]"}, false, gomerk.WithPackedLeaves())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pre, _ := dyn.LeafPreimage(0); !slices.Equal(pre, slices.Concat(append(make([]byte, 31), 1), append(make([]byte, 31), 2))) {
+		t.Errorf("uint256[] preimage = %x", pre)
+	}
+	if pre, _ := dyn.LeafPreimage(1); len(pre) != 0 {
+		t.Errorf("empty uint256[] preimage = %x", pre)
+	}
+
+	if _, err := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithPackedLeaves(), gomerk.WithHasher(gomerk.SHA256Hasher)); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("packed SHA-256 leaves: got %v, want ErrUnsupportedType", err)
+	}
+
+	for _, typ := range []string{"(uint8,bool)", "uint8[][]", "string[2]"} {
+		_, err := gomerk.NewStandardMerkleTree([][]any{{nil}}, []string{typ}, false, gomerk.WithPackedLeaves())
+		if !errors.Is(err, gomerk.ErrUnsupportedType) {
+			t.Errorf("%s: got %v, want ErrUnsupportedType", typ, err)
+		}
+	}
+}
+
+func TestLeafPreimage(t *testing.T) {
+	vals := airdropData(4)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	data := tree.Dump()
+	for i, v := range vals {
+		pre, err := tree.LeafPreimage(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := gomerk.ABIEncodePacked(enc, v)
+		if !bytes.Equal(pre, want) {
+			t.Errorf("index %d: preimage mismatch", i)
+		}
+		if h := gomerk.HashLeaf(pre).Hex(); h != data.Tree[data.Values[i].TreeIndex] {
+			t.Errorf("index %d: hash of preimage is not the leaf", i)
+		}
+	}
+	if _, err := tree.LeafPreimage(4); err != gomerk.ErrIndexOutOfBounds {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+
+	bare, _ := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithoutValues())
+	if _, err := bare.LeafPreimage(0); err != gomerk.ErrValuesNotRetained {
+		t.Errorf("got %v, want ErrValuesNotRetained", err)
+	}
+	for _, sep := range []string{"", "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"} {
+		typ := &gomerk.EIP712Type{
+			PrimaryType:     "Claim",
+			Types:           map[string][]gomerk.EIP712Field{"Claim": {{Name: "account", Type: "address"}, {Name: "note", Type: "string"}}},
+			DomainSeparator: sep,
+		}
+		eip, _ := gomerk.BuildStandardMerkleTree([][]any{{"0x" + padAddr(1), "hi"}, {"0x" + padAddr(2), "yo"}}, nil, gomerk.WithEIP712Leaves(typ))
+		data := eip.Dump()
+		for i := range eip.Len() {
+			pre, err := eip.LeafPreimage(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h := gomerk.Keccak256(pre).Hex(); h != data.Tree[data.Values[i].TreeIndex] {
+				t.Errorf("EIP-712 separator %q, index %d: hash of preimage is not the leaf", sep, i)
+			}
+		}
+	}
+}
+
+func TestVerifyParsed(t *testing.T) {
+	vals := airdropData(9)
+	tree, _ := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
+	for i, v := range vals {
+		proof, err := tree.GetProofBytes(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := tree.VerifyParsed(v, proof); err != nil || !ok {
+			t.Errorf("index %d: got (%v, %v), want (true, nil)", i, ok, err)
+		}
+		if ok, _ := tree.VerifyParsed(vals[(i+1)%len(vals)], proof); ok {
+			t.Errorf("index %d: proof accepted for another value", i)
+		}
+	}
+	if _, err := tree.GetProofBytes(-1); err != gomerk.ErrIndexOutOfBounds {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	vals := airdropData(1024)
+	tree, _ := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
+	proof, _ := tree.GetProofByIndex(100)
+	for b.Loop() {
+		tree.Verify(vals[100], proof)
+	}
+}
+
+func BenchmarkVerifyParsed(b *testing.B) {
+	vals := airdropData(1024)
+	tree, _ := gomerk.NewStandardMerkleTree(vals, []string{"address", "uint256"}, true)
+	proof, _ := tree.GetProofBytes(100)
+	for b.Loop() {
+		tree.VerifyParsed(vals[100], proof)
+	}
+}
+
+func TestAdoptStandardTree(t *testing.T) {
+	vals := airdropData(6)
+	vals = append(vals, vals[2])
+	enc := []string{"address", "uint256"}
+	orig, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	tree := orig.Dump().Tree
+
+	shuffled := slices.Clone(vals)
+	slices.Reverse(shuffled)
+	adopted, err := gomerk.AdoptStandardTree(tree, shuffled, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adopted.Root() != orig.Root() {
+		t.Errorf("root = %s, want %s", adopted.Root(), orig.Root())
+	}
+	if err := adopted.SelfTest(); err != nil {
+		t.Error(err)
+	}
+	seen := make(map[int]bool)
+	for _, v := range adopted.Dump().Values {
+		if seen[v.TreeIndex] {
+			t.Errorf("tree index %d assigned twice", v.TreeIndex)
+		}
+		seen[v.TreeIndex] = true
+	}
+
+	if _, err := gomerk.AdoptStandardTree(tree, shuffled[1:], enc); err != gomerk.ErrMismatchedCount {
+		t.Errorf("got %v, want ErrMismatchedCount", err)
+	}
+	wrong := slices.Clone(shuffled)
+	wrong[0] = []any{padAddr(99), 1}
+	if _, err := gomerk.AdoptStandardTree(tree, wrong, enc); !errors.Is(err, gomerk.ErrLeafNotInTree) {
+		t.Errorf("got %v, want ErrLeafNotInTree", err)
+	}
+	tampered := slices.Clone(tree)
+	tampered[0] = tampered[1]
+	if _, err := gomerk.AdoptStandardTree(tampered, shuffled, enc); err != gomerk.ErrInvariant {
+		t.Errorf("got %v, want ErrInvariant", err)
+	}
+}
+
+func TestStandardMerkleTreeWouldCollide(t *testing.T) {
+	vals := airdropData(4)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+
+	h, err := tree.LeafHash(vals[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := slices.Collect(tree.Entries())[1]; entry.LeafHash != h {
+		t.Errorf("LeafHash = %s, want %s", h, entry.LeafHash)
+	}
+
+	same := []any{strings.ToUpper(vals[1][0].(string)[2:]), big.NewInt(200)}
+	if ok, err := tree.WouldCollide(same); err != nil || !ok {
+		t.Errorf("equivalent value: got (%v, %v), want (true, nil)", ok, err)
+	}
+	if ok, _ := tree.WouldCollide([]any{vals[1][0], 201}); ok {
+		t.Error("distinct value reported as colliding")
+	}
+	if _, err := tree.WouldCollide([]any{"0x12", 1}); err == nil {
+		t.Error("expected encoding error")
+	}
+}
+
+func TestStandardMerkleTreeDumpProofs(t *testing.T) {
+	vals := airdropData(4)
+	vals[2][0] = "0x" + strings.ToUpper(vals[2][0].(string)[2:])
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+
+	raw, err := tree.DumpProofs(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data gomerk.StandardProofsData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Root != tree.Root() || !slices.Equal(data.LeafEncoding, enc) || len(data.Proofs) != 4 {
+		t.Fatalf("unexpected data %+v", data)
+	}
+	for _, v := range vals {
+		item, ok := data.Proofs[strings.ToLower(v[0].(string))]
+		if !ok {
+			t.Fatalf("missing key for %v", v[0])
+		}
+		if ok, err := gomerk.VerifyStandard(data.Root, data.LeafEncoding, item.Value, item.Proof); err != nil || !ok {
+			t.Errorf("%v: got (%v, %v), want (true, nil)", v[0], ok, err)
+		}
+	}
+
+	if _, err := tree.DumpProofs(1); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("uint key: got %v, want ErrUnsupportedType", err)
+	}
+	if _, err := tree.DumpProofs(2); err != gomerk.ErrIndexOutOfBounds {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+	dup, _ := gomerk.NewStandardMerkleTree([][]any{{vals[0][0], 1}, {vals[0][0], 2}}, enc, true)
+	if _, err := dup.DumpProofs(0); !errors.Is(err, gomerk.ErrDuplicatedID) {
+		t.Errorf("got %v, want ErrDuplicatedID", err)
+	}
+}
+
+func TestStandardMerkleTreeRecomputeRoot(t *testing.T) {
+	vals := airdropData(5)
+	enc := []string{"address", "uint256"}
+	for _, sorted := range []bool{true, false} {
+		tree, _ := gomerk.NewStandardMerkleTree(vals, enc, sorted)
+		loaded, err := gomerk.LoadStandardMerkleTree(tree.Dump())
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, err := loaded.RecomputeRoot()
+		if err != nil || root != tree.Root() {
+			t.Errorf("sorted=%v: got (%s, %v), want %s", sorted, root, err, tree.Root())
+		}
+	}
+
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	data := tree.Dump()
+	data.Values[0].Value = []any{data.Values[0].Value[0], 1}
+	if root, _ := tree.RecomputeRoot(); root == tree.Root() {
+		t.Error("tampered value should change the recomputed root")
+	}
+
+	bare, _ := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithoutValues())
+	if _, err := bare.RecomputeRoot(); err != gomerk.ErrValuesNotRetained {
+		t.Errorf("got %v, want ErrValuesNotRetained", err)
+	}
+}
+
+func TestStandardMerkleTreeSelectorLeaf(t *testing.T) {
+	sel := gomerk.Selector("transfer(address,uint256)")
+	enc := []string{"bytes4", "address", "uint256"}
+	vals := [][]any{
+		{sel, "0x" + padAddr(1), 100},
+		{"0xa9059cbb", "0x" + padAddr(2), 200},
+		{sel[:], "0x" + padAddr(3), 300},
+	}
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := gomerk.ABIEncodePacked([]string{"bytes4"}, []any{sel})
+	want := append([]byte{0xa9, 0x05, 0x9c, 0xbb}, make([]byte, 28)...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("bytes4 word = %x, want %x", got, want)
+	}
+	for _, tc := range []struct {
+		typ string
+		val any
+	}{
+		{"bytes4", "0xa9059c"},
+		{"bytes4", [3]byte{}},
+		{"bytes4", 7},
+	} {
+		if _, err := gomerk.ABIEncodePacked([]string{tc.typ}, []any{tc.val}); !errors.Is(err, gomerk.ErrAbiEncode) {
+			t.Errorf("%s %v: got %v, want ErrAbiEncode", tc.typ, tc.val, err)
+		}
+	}
+	for _, typ := range []string{"bytes33", "bytes0", "bytes04", "bytes+4"} {
+		if _, err := gomerk.ABIEncodePacked([]string{typ}, []any{"0x00"}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+			t.Errorf("%s: got %v, want ErrUnsupportedType", typ, err)
+		}
+	}
+}
+
+func TestABIEncodeFixedBytesWidths(t *testing.T) {
+	for n := 1; n <= 32; n++ {
+		data := slices.Repeat([]byte{0xab}, n)
+		got, err := gomerk.ABIEncodePacked([]string{fmt.Sprintf("bytes%d", n)}, []any{data})
+		if err != nil {
+			t.Fatalf("bytes%d: %v", n, err)
+		}
+		if want := append(data, make([]byte, 32-n)...); !bytes.Equal(got, want) {
+			t.Errorf("bytes%d: got %x, want %x", n, got, want)
+		}
+	}
+}
+
+func TestStandardMerkleTreeHasValues(t *testing.T) {
+	vals := airdropData(3)
+	enc := []string{"address", "uint256"}
+	full, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	bare, _ := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithoutValues())
+	if !full.HasValues() || bare.HasValues() {
+		t.Errorf("HasValues: full=%v bare=%v, want true/false", full.HasValues(), bare.HasValues())
+	}
+
+	raw, _ := json.Marshal(bare.Dump())
+	var data gomerk.StandardTreeData
+	json.Unmarshal(raw, &data)
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.HasValues() {
+		t.Error("tree loaded from a dump without values should report false")
+	}
+	if reloaded, _ := gomerk.LoadStandardMerkleTree(full.Dump()); !reloaded.HasValues() {
+		t.Error("tree loaded from a full dump should report true")
+	}
+}
+
+func TestStandardMerkleTreeIndexOf(t *testing.T) {
+	vals := airdropData(6)
+	vals = append(vals, vals[1])
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	loaded, _ := gomerk.LoadStandardMerkleTree(tree.Dump())
+
+	for _, tr := range []*gomerk.StandardMerkleTree{tree, loaded} {
+		for i, v := range vals[:6] {
+			if got, err := tr.IndexOf(v); err != nil || got != i {
+				t.Errorf("IndexOf(value %d) = (%d, %v)", i, got, err)
+			}
+		}
+		if got, _ := tr.IndexOf(vals[6]); got != 1 {
+			t.Errorf("duplicate value should resolve to first index, got %d", got)
+		}
+		if _, err := tr.IndexOf([]any{"0x" + padAddr(50), 1}); err != gomerk.ErrLeafNotInTree {
+			t.Errorf("got %v, want ErrLeafNotInTree", err)
+		}
+	}
+
+	tree.Rebuild(airdropData(3)[1:])
+	if got, err := tree.IndexOf(airdropData(3)[2]); err != nil || got != 1 {
+		t.Errorf("after rebuild: got (%d, %v), want (1, nil)", got, err)
+	}
+	if _, err := tree.IndexOf(vals[5]); err != gomerk.ErrLeafNotInTree {
+		t.Errorf("after rebuild: stale value found, err = %v", err)
+	}
+}
+
+func TestBuildStandardMerkleTree(t *testing.T) {
+	vals := airdropData(5)
+	enc := []string{"address", "uint256"}
+	legacy, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	built, err := gomerk.BuildStandardMerkleTree(vals, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.Root() != legacy.Root() {
+		t.Error("leaves should be sorted by default")
+	}
+	unsorted, _ := gomerk.NewStandardMerkleTree(vals, enc, false)
+	if b, _ := gomerk.BuildStandardMerkleTree(vals, enc, gomerk.WithSortLeaves(false)); b.Root() != unsorted.Root() {
+		t.Error("WithSortLeaves(false) should keep input order")
+	}
+
+	dup := append(slices.Clone(vals), vals[1], vals[3], vals[1])
+	dedup, err := gomerk.BuildStandardMerkleTree(dup, enc, gomerk.WithDeduplication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dedup.Len() != len(vals) || dedup.Root() != legacy.Root() {
+		t.Errorf("dedup: len %d root %s, want %d %s", dedup.Len(), dedup.Root(), len(vals), legacy.Root())
+	}
+	if plain, _ := gomerk.BuildStandardMerkleTree(dup, enc); plain.Len() != len(dup) {
+		t.Errorf("without deduplication len = %d, want %d", plain.Len(), len(dup))
+	}
+}
+
+func TestStandardMerkleTreeParallelism(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	for _, n := range []int{1, 7, 3000, 5001} {
+		vals := airdropData(n)
+		serial, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+		parallel, err := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithParallelism(4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(parallel.Dump().Tree, serial.Dump().Tree) {
+			t.Errorf("n=%d: parallel build differs from serial", n)
+		}
+	}
+
+	vals := airdropData(4000)
+	vals[1500] = []any{vals[1500][0], "x"}
+	vals[3500] = []any{vals[3500][0], "y"}
+	_, err := gomerk.NewStandardMerkleTree(vals, enc, true, gomerk.WithParallelism(8))
+	var encErr *gomerk.EncodeError
+	if !errors.As(err, &encErr) || encErr.Index != 1500 {
+		t.Errorf("got %v, want the first failing value", err)
+	}
+}
+
+func TestStandardMerkleTreeProofs(t *testing.T) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(2100), []string{"address", "uint256"}, true)
+	all := tree.AllProofs(4)
+	if len(all) != tree.Len() {
+		t.Fatalf("AllProofs returned %d proofs, want %d", len(all), tree.Len())
+	}
+	seen := 0
+	for i, proof := range tree.Proofs() {
+		want, _ := tree.GetProofByIndex(i)
+		if !slices.Equal(proof, want) || !slices.Equal(all[i], want) {
+			t.Fatalf("index %d: proofs differ from GetProofByIndex", i)
+		}
+		seen++
+	}
+	if seen != tree.Len() {
+		t.Errorf("Proofs yielded %d proofs, want %d", seen, tree.Len())
+	}
+	for range tree.Proofs() {
+		break
+	}
+}
+
+func TestGetProofsByIndices(t *testing.T) {
+	for _, n := range []int{1, 2, 7, 100} {
+		tree, _ := gomerk.NewStandardMerkleTree(airdropData(n), []string{"address", "uint256"}, true)
+		indices := []int{n - 1, 0, n / 2, n - 1}
+		proofs, err := tree.GetProofsByIndices(indices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(proofs) != len(slices.Compact(slices.Sorted(slices.Values(indices)))) {
+			t.Errorf("n=%d: got %d proofs", n, len(proofs))
+		}
+		for _, i := range indices {
+			want, _ := tree.GetProofByIndex(i)
+			if !slices.Equal(proofs[i], want) {
+				t.Errorf("n=%d: proof %d = %v, want %v", n, i, proofs[i], want)
+			}
+		}
+		if _, err := tree.GetProofsByIndices([]int{0, n}); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+			t.Errorf("n=%d: out of range: err = %v", n, err)
+		}
+
+		all := make([]int, n)
+		for i := range all {
+			all[i] = (i * 37) % n
+		}
+		proofs, _ = tree.GetProofsByIndices(all)
+		for i, want := range tree.Proofs() {
+			if !slices.Equal(proofs[i], want) {
+				t.Errorf("n=%d: all: proof %d = %v, want %v", n, i, proofs[i], want)
+			}
+		}
+	}
+}
+
+func BenchmarkProofs(b *testing.B) {
+	tree, _ := gomerk.NewStandardMerkleTree(airdropData(10000), []string{"address", "uint256"}, true)
+	b.Run("GetProofByIndex", func(b *testing.B) {
+		for b.Loop() {
+			for i := range tree.Len() {
+				tree.GetProofByIndex(i)
+			}
+		}
+	})
+	b.Run("Proofs", func(b *testing.B) {
+		for b.Loop() {
+			for range tree.Proofs() {
+			}
+		}
+	})
+	page := make([]int, 500)
+	for i := range page {
+		page[i] = 4000 + i
+	}
+	b.Run("GetProofByIndexPage", func(b *testing.B) {
+		for b.Loop() {
+			for _, i := range page {
+				tree.GetProofByIndex(i)
+			}
+		}
+	})
+	b.Run("GetProofsByIndicesPage", func(b *testing.B) {
+		for b.Loop() {
+			tree.GetProofsByIndices(page)
+		}
+	})
+}
+
+func TestStandardTreeDataJSONRoundTrip(t *testing.T) {
+	enc := []string{"address", "uint256", "uint64[2]"}
+	big1, _ := new(big.Int).SetString("123456789012345678901234567", 10)
+	vals := [][]any{
+		{"0x" + padAddr(1), big1, []any{uint64(1 << 60), 3}},
+		{"0x" + padAddr(2), int64(1<<53 + 1), []any{1, 2}},
+		{"0x" + padAddr(3), 42, []any{0, 0}},
+	}
+	tree, err := gomerk.NewStandardMerkleTree(vals, enc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := json.Marshal(tree.Dump())
+	for _, want := range []string{`"123456789012345678901234567"`, `"9007199254740993"`, `"1152921504606846976"`, `,42,`} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("dump should contain %s: %s", want, raw)
+		}
+	}
+	if v, _ := tree.At(0); v[1] != big1 {
+		t.Error("Dump should not modify the tree's values")
+	}
+
+	var data gomerk.StandardTreeData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vals {
+		proof, _ := loaded.GetProof(v)
+		if ok, _ := loaded.Verify(v, proof); !ok {
+			t.Errorf("value %d: loaded tree rejected its proof", i)
+		}
+		fresh, _ := tree.GetProof(v)
+		if !slices.Equal(proof, fresh) {
+			t.Errorf("value %d: loaded proof differs", i)
+		}
+	}
+}
+
+func TestFloatAmountsRejected(t *testing.T) {
+	for _, f := range []float64{1.5, 1 << 60} {
+		if _, err := gomerk.ABIEncodePacked([]string{"uint256"}, []any{f}); !errors.Is(err, gomerk.ErrAbiEncode) {
+			t.Errorf("%v: got %v, want ErrAbiEncode", f, err)
+		}
+	}
+	if _, err := gomerk.ABIEncodePacked([]string{"uint256"}, []any{float64(1 << 40)}); err != nil {
+		t.Errorf("exact float: %v", err)
+	}
+}
+
+func TestStandardMerkleTreeSaltedLeaves(t *testing.T) {
+	vals := airdropData(4)
+	enc := []string{"address", "uint256"}
+	salts := make([]byte, 32*len(vals))
+	for i := range salts {
+		salts[i] = byte(i)
+	}
+	tree, err := gomerk.BuildStandardMerkleTree(vals, enc, gomerk.WithSaltedLeaves(bytes.NewReader(salts)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tree.LeafEncoding(); !slices.Equal(got, []string{"bytes32", "address", "uint256"}) {
+		t.Fatalf("leaf encoding = %v", got)
+	}
+	v, _ := tree.At(1)
+	if want := "0x" + hex.EncodeToString(salts[32:64]); v[0] != want || v[1] != vals[1][0] {
+		t.Errorf("value 1 = %v, want salt %s followed by %v", v, want, vals[1])
+	}
+	if _, err := tree.GetProof(vals[1]); err == nil {
+		t.Error("proof found for a value without its salt")
+	}
+
+	b, err := tree.ExportProofs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := gomerk.VerifyProofBundle(b); err != nil || !ok {
+		t.Errorf("bundle: got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	raw, _ := json.Marshal(tree.Dump())
+	var data gomerk.StandardTreeData
+	json.Unmarshal(raw, &data)
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof, err := loaded.GetProof(v); err != nil || len(proof) == 0 {
+		t.Errorf("loaded tree: got (%v, %v)", proof, err)
+	}
+
+	a, _ := gomerk.BuildStandardMerkleTree(vals, enc, gomerk.WithSaltedLeaves(nil))
+	c, _ := gomerk.BuildStandardMerkleTree(vals, enc, gomerk.WithSaltedLeaves(nil))
+	if a.Root() == c.Root() {
+		t.Error("random salts produced identical roots")
+	}
+	if _, err := gomerk.BuildStandardMerkleTree(vals, enc, gomerk.WithSaltedLeaves(bytes.NewReader(salts[:40]))); err == nil {
+		t.Error("expected error when salts run out")
+	}
+}
+
+func TestStandardMerkleTreeUpdateLeafByIndex(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	vals := airdropData(7)
+	tree, err := gomerk.BuildStandardMerkleTree(vals, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		i     int
+		value []any
+	}{
+		{3, []any{"0x" + padAddr(3), 12345}},
+		{0, vals[5]}, // duplicates value 5
+		{5, []any{"0x" + padAddr(99), 1}},
+	} {
+		root, err := tree.UpdateLeafByIndex(tc.i, tc.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vals[tc.i] = tc.value
+		if root != tree.Root() {
+			t.Errorf("returned root %s, tree root %s", root, tree.Root())
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if err := tree.SelfTest(); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := tree.RecomputeRoot(); err != nil || got != root {
+			t.Errorf("RecomputeRoot = (%s, %v), want %s", got, err, root)
+		}
+		for i, v := range vals {
+			j, err := tree.IndexOf(v)
+			if err != nil {
+				t.Fatalf("IndexOf(%d): %v", i, err)
+			}
+			if w, _ := tree.At(j); fmt.Sprint(w) != fmt.Sprint(v) {
+				t.Errorf("IndexOf(%d) = %d holding %v", i, j, w)
+			}
+		}
+	}
+
+	ref, _ := gomerk.NewStandardMerkleTree(airdropData(7), enc, true)
+	if _, err := ref.UpdateLeafByIndex(7, vals[0]); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+	if _, err := ref.UpdateLeafByIndex(0, []any{"0x" + padAddr(1)}); err == nil {
+		t.Error("expected encoding error")
+	}
+	if root, _ := ref.RecomputeRoot(); root != ref.Root() {
+		t.Error("failed update changed the tree")
+	}
+}
+
+func TestStandardMerkleTreeAppend(t *testing.T) {
+	enc := []string{"address", "uint256"}
+	all := airdropData(12)
+	for _, sorted := range []bool{true, false} {
+		tree, err := gomerk.NewStandardMerkleTree(all[:7], enc, sorted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tree.Append(all[7:]...); err != nil {
+			t.Fatal(err)
+		}
+		fresh, _ := gomerk.NewStandardMerkleTree(all, enc, sorted)
+		if tree.Root() != fresh.Root() {
+			t.Errorf("sorted=%v: appended root %s, rebuilt root %s", sorted, tree.Root(), fresh.Root())
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range all {
+			if j, err := tree.IndexOf(v); err != nil || j != i {
+				t.Errorf("sorted=%v: IndexOf(%d) = (%d, %v)", sorted, i, j, err)
+			}
+		}
+		if err := tree.SelfTest(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An unsorted tree whose leaves happen to be in hash order appends.
+	inOrder, _ := gomerk.NewStandardMerkleTree(all, enc, true)
+	ordered := make([][]any, 0, len(all))
+	for e := range inOrder.Entries() {
+		ordered = append(ordered, e.Value)
+	}
+	slices.SortFunc(ordered, func(a, b []any) int {
+		ha, _ := inOrder.LeafHash(a)
+		hb, _ := inOrder.LeafHash(b)
+		return ha.Compare(hb)
+	})
+	base := slices.Concat(ordered[:3], ordered[4:])
+	unsorted, _ := gomerk.NewStandardMerkleTree(base, enc, false)
+	raw, _ := json.Marshal(unsorted.Dump())
+	var data gomerk.StandardTreeData
+	json.Unmarshal(raw, &data)
+	loaded, err := gomerk.LoadStandardMerkleTree(data)
+	if err != nil || !data.Unsorted {
+		t.Fatalf("unsorted dump: %v, Unsorted=%v", err, data.Unsorted)
+	}
+	unsorted.Append(ordered[3])
+	loaded.Append(ordered[3])
+	fresh, _ := gomerk.NewStandardMerkleTree(append(base, ordered[3]), enc, false)
+	if unsorted.Root() != fresh.Root() || loaded.Root() != fresh.Root() {
+		t.Errorf("unsorted append: roots %s and %s, rebuilt %s", unsorted.Root(), loaded.Root(), fresh.Root())
+	}
+
+	tree, _ := gomerk.BuildStandardMerkleTree(all[:3], enc, gomerk.WithDeduplication())
+	if err := tree.Append(all[1], all[4], all[4]); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 4 {
+		t.Errorf("Len = %d after appending one new value, want 4", tree.Len())
+	}
+
+	root := tree.Root()
+	if err := tree.Append(all[5], []any{"0x" + padAddr(1)}); err == nil {
+		t.Error("expected encoding error")
+	}
+	if tree.Root() != root || tree.Len() != 4 {
+		t.Error("failed append changed the tree")
+	}
+}
+
+// gethAddress and gethHash mirror go-ethereum's common.Address and
+// common.Hash.
+type (
+	gethAddress [20]byte
+	gethHash    [32]byte
+)
+
+func TestStandardTreeByteArrayValues(t *testing.T) {
+	var addr gethAddress
+	addr[19] = 1
+	var hash gethHash
+	hash[0] = 0xab
+	hexAddr := "0x" + strings.Repeat("00", 19) + "01"
+	hexHash := "0xab" + strings.Repeat("00", 31)
+
+	typed, err := gomerk.NewStandardMerkleTree([][]any{{addr, hash, big.NewInt(5)}}, []string{"address", "bytes32", "uint256"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := gomerk.NewStandardMerkleTree([][]any{{hexAddr, hexHash, "5"}}, []string{"address", "bytes32", "uint256"}, true)
+	if typed.Root() != plain.Root() {
+		t.Errorf("root = %s, want %s", typed.Root(), plain.Root())
+	}
+	dump := typed.Dump()
+	if v := dump.Values[0].Value; v[0] != hexAddr || v[1] != hexHash {
+		t.Errorf("dumped value = %v, want hex", v)
+	}
+	if _, err := gomerk.LoadStandardMerkleTree(dump); err != nil {
+		t.Errorf("LoadStandardMerkleTree() error = %v", err)
+	}
+}
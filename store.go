@@ -0,0 +1,109 @@
+package gomerk
+
+// TreeStore holds the nodes of a tree in the heap layout of MakeTree, so that
+// proofs can be served from storage other than memory. Setting a node at or
+// beyond Len grows the store to include it.
+type TreeStore interface {
+	GetNode(i int) (Bytes32, error)
+	SetNode(i int, node Bytes32) error
+	Len() int
+}
+
+// MemoryStore is a TreeStore backed by a slice.
+type MemoryStore struct {
+	nodes []Bytes32
+}
+
+var _ TreeStore = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore { return &MemoryStore{} }
+
+func (s *MemoryStore) GetNode(i int) (Bytes32, error) {
+	if i < 0 || i >= len(s.nodes) {
+		return Bytes32{}, ErrIndexOutOfBounds
+	}
+	return s.nodes[i], nil
+}
+
+func (s *MemoryStore) SetNode(i int, node Bytes32) error {
+	if i < 0 {
+		return ErrIndexOutOfBounds
+	}
+	if i >= len(s.nodes) {
+		s.nodes = append(s.nodes, make([]Bytes32, i+1-len(s.nodes))...)
+	}
+	s.nodes[i] = node
+	return nil
+}
+
+func (s *MemoryStore) Len() int { return len(s.nodes) }
+
+// WriteStore copies the tree's nodes into s.
+func (t *StandardMerkleTree) WriteStore(s TreeStore) error { return writeStore(s, t.tree) }
+
+// WriteStore copies the tree's nodes into s.
+func (t *SimpleMerkleTree) WriteStore(s TreeStore) error { return writeStore(s, t.tree) }
+
+func writeStore(s TreeStore, tree []Bytes32) error {
+	for i, node := range tree {
+		if err := s.SetNode(i, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProofFromStore returns the proof for the leaf at tree index index,
+// reading only the nodes on its path.
+func GetProofFromStore(s TreeStore, index int) ([]string, error) {
+	if err := checkLeaf(s.Len(), index); err != nil {
+		return nil, err
+	}
+	var proof []string
+	for index > 0 {
+		node, err := s.GetNode(sibling(index))
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, node.Hex())
+		index = parent(index)
+	}
+	return proof, nil
+}
+
+// ValidateStore checks that every internal node of s is the hash of its
+// children, using the Hasher set by opts. It holds three nodes in memory at a
+// time.
+func ValidateStore(s TreeStore, opts ...Option) error {
+	h := newOptions(opts).hash()
+	n := s.Len()
+	if n == 0 {
+		return ErrEmptyTree
+	}
+	for i := range n {
+		l, r := leftChild(i), rightChild(i)
+		if r >= n {
+			if l < n {
+				return ErrInvariant
+			}
+			continue
+		}
+		node, err := s.GetNode(i)
+		if err != nil {
+			return err
+		}
+		left, err := s.GetNode(l)
+		if err != nil {
+			return err
+		}
+		right, err := s.GetNode(r)
+		if err != nil {
+			return err
+		}
+		if node != h.NodeHash(left, right) {
+			return ErrInvariant
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package gomerk_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestMemoryStore(t *testing.T) {
+	tree, err := gomerk.BuildStandardMerkleTree(airdropData(7), []string{"address", "uint256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := gomerk.NewMemoryStore()
+	if err := tree.WriteStore(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := gomerk.ValidateStore(s); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range tree.Dump().Values {
+		got, err := gomerk.GetProofFromStore(s, v.TreeIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := tree.GetProofByIndex(i)
+		if !slices.Equal(got, want) {
+			t.Errorf("proof %d = %v, want %v", i, got, want)
+		}
+	}
+	if _, err := gomerk.GetProofFromStore(s, 0); !errors.Is(err, gomerk.ErrNotALeaf) {
+		t.Errorf("got %v, want ErrNotALeaf", err)
+	}
+	if _, err := s.GetNode(s.Len()); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+
+	s.SetNode(s.Len()-1, gomerk.Bytes32{1})
+	if err := gomerk.ValidateStore(s); !errors.Is(err, gomerk.ErrInvariant) {
+		t.Errorf("tampered store: got %v, want ErrInvariant", err)
+	}
+	if err := gomerk.ValidateStore(gomerk.NewMemoryStore()); !errors.Is(err, gomerk.ErrEmptyTree) {
+		t.Errorf("empty store: got %v, want ErrEmptyTree", err)
+	}
+}
+
+func TestMemoryStoreHasher(t *testing.T) {
+	leaves := []gomerk.Bytes32{{1}, {2}, {3}}
+	tree, err := gomerk.BuildSimpleMerkleTree(leaves, gomerk.WithHasher(gomerk.SHA256Hasher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := gomerk.NewMemoryStore()
+	if err := tree.WriteStore(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := gomerk.ValidateStore(s, gomerk.WithHasher(gomerk.SHA256Hasher)); err != nil {
+		t.Error(err)
+	}
+	if err := gomerk.ValidateStore(s); !errors.Is(err, gomerk.ErrInvariant) {
+		t.Errorf("default hasher: got %v, want ErrInvariant", err)
+	}
+}
@@ -0,0 +1,184 @@
+package gomerk
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// WriteTo writes the tree's dump as JSON to w, encoding one node and one
+// value at a time instead of building the whole document in memory. The
+// output is identical to json.Marshal(t.Dump()).
+func (t *StandardMerkleTree) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	numberEncoding, encoding := t.opts.encodingSettings()
+
+	write := func(v any) {
+		b, err := json.Marshal(v)
+		if err != nil && cw.err == nil {
+			cw.err = err
+		}
+		bw.Write(b)
+	}
+
+	bw.WriteString(`{"format":`)
+	write(FormatStandardV1)
+	bw.WriteString(`,"leafEncoding":`)
+	write(t.leafEncoding)
+	bw.WriteString(`,"tree":[`)
+	for i, node := range t.tree {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteByte('"')
+		bw.WriteString(node.Hex())
+		bw.WriteByte('"')
+	}
+	bw.WriteString(`],"values":[`)
+	for i, v := range t.values {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		v.Value, _ = canonicalFields(t.leafEncoding, v.Value)
+		write(v)
+	}
+	bw.WriteByte(']')
+	for _, f := range []struct{ key, val string }{
+		{"numberEncoding", numberEncoding},
+		{"hasher", dumpHasherID(t.opts.hash())},
+		{"encoding", encoding},
+	} {
+		if f.val != "" {
+			bw.WriteString(`,"` + f.key + `":`)
+			write(f.val)
+		}
+	}
+	if t.opts.eip712 != nil {
+		bw.WriteString(`,"eip712":`)
+		write(t.opts.eip712)
+	}
+	if t.opts.unsorted {
+		bw.WriteString(`,"unsorted":true`)
+	}
+	bw.WriteByte('}')
+	if err := bw.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, cw.err
+}
+
+// LoadStandardMerkleTreeFrom loads a tree from a JSON dump read from r,
+// decoding nodes and values one at a time so that only the loaded tree is
+// held in memory. It accepts the same documents as LoadStandardMerkleTree
+// after json.Unmarshal.
+func LoadStandardMerkleTreeFrom(r io.Reader) (*StandardMerkleTree, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+	var (
+		data StandardTreeData
+		tree []Bytes32
+	)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, ErrInvalidFormat
+		}
+		switch tok {
+		case "tree":
+			err = decodeArray(dec, func() error {
+				var s string
+				if err := dec.Decode(&s); err != nil {
+					return ErrInvalidFormat
+				}
+				b, err := HexToBytes32(s)
+				if err != nil {
+					return ErrInvariant
+				}
+				tree = append(tree, b)
+				return nil
+			})
+		case "values":
+			err = decodeArray(dec, func() error {
+				var v StandardValue
+				if err := dec.Decode(&v); err != nil {
+					return ErrInvalidFormat
+				}
+				data.Values = append(data.Values, v)
+				return nil
+			})
+		case "format":
+			err = dec.Decode(&data.Format)
+		case "leafEncoding":
+			err = dec.Decode(&data.LeafEncoding)
+		case "numberEncoding":
+			err = dec.Decode(&data.NumberEncoding)
+		case "hasher":
+			err = dec.Decode(&data.Hasher)
+		case "encoding":
+			err = dec.Decode(&data.Encoding)
+		case "eip712":
+			err = dec.Decode(&data.EIP712)
+		case "unsorted":
+			err = dec.Decode(&data.Unsorted)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if errors.Is(err, ErrInvariant) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, ErrInvalidFormat
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	if data.Format != FormatStandardV1 {
+		return nil, ErrInvalidFormat
+	}
+	return loadStandard(data, tree)
+}
+
+// decodeArray reads a JSON array from dec, calling elem to decode each
+// element.
+func decodeArray(dec *json.Decoder, elem func() error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil || tok != want {
+		return ErrInvalidFormat
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written to w and records the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	return n, err
+}
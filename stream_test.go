@@ -0,0 +1,88 @@
+package gomerk_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestStandardMerkleTreeWriteTo(t *testing.T) {
+	big1, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	vals := [][]any{
+		{"0x1111111111111111111111111111111111111111", big1},
+		{"0x2222222222222222222222222222222222222222", 5},
+		{"0x3333333333333333333333333333333333333333", "7"},
+	}
+	enc := []string{"address", "uint256"}
+	for _, opts := range [][]gomerk.Option{
+		nil,
+		{gomerk.WithLittleEndianNumbers(), gomerk.WithHasher(gomerk.SHA256Hasher)},
+		{gomerk.WithPackedLeaves()},
+	} {
+		tree, err := gomerk.BuildStandardMerkleTree(vals, enc, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := json.Marshal(tree.Dump())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		n, err := tree.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(buf.Len()) || !bytes.Equal(buf.Bytes(), want) {
+			t.Fatalf("WriteTo = %s (%d bytes), want %s", buf.Bytes(), n, want)
+		}
+
+		loaded, err := gomerk.LoadStandardMerkleTreeFrom(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Root() != tree.Root() {
+			t.Fatalf("root = %s, want %s", loaded.Root(), tree.Root())
+		}
+		proof, err := loaded.GetProof(vals[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, _ := tree.Verify(vals[0], proof); !ok {
+			t.Fatal("proof from streamed tree does not verify")
+		}
+	}
+}
+
+func TestLoadStandardMerkleTreeFromInvalid(t *testing.T) {
+	tree, err := gomerk.BuildStandardMerkleTree(airdropData(4), []string{"address", "uint256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := tree.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	good := buf.String()
+	root := strings.TrimPrefix(tree.Root(), "0x")
+
+	for _, tc := range []struct {
+		name, doc string
+		want      error
+	}{
+		{"truncated", good[:len(good)/2], gomerk.ErrInvalidFormat},
+		{"not an object", `[]`, gomerk.ErrInvalidFormat},
+		{"bad format", strings.Replace(good, gomerk.FormatStandardV1, "v0", 1), gomerk.ErrInvalidFormat},
+		{"null tree", `{"format":"standard-v1","tree":null}`, gomerk.ErrInvalidFormat},
+		{"bad node", strings.Replace(good, root, "zz", 1), gomerk.ErrInvariant},
+		{"tampered root", strings.Replace(good, root, strings.Repeat("0", 64), 1), gomerk.ErrInvariant},
+	} {
+		if _, err := gomerk.LoadStandardMerkleTreeFrom(strings.NewReader(tc.doc)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package gomerk
+
+import "math/big"
+
+// SumLeaf is a SumMerkleTree leaf: an opaque commitment, such as the hash of
+// an account id and salt, and a non-negative amount below 2^256.
+type SumLeaf struct {
+	Data   Bytes32
+	Amount *big.Int
+}
+
+// SumNode is a node of a SumMerkleTree: a hash and the total of the amounts
+// below it, in decimal.
+type SumNode struct {
+	Hash string `json:"hash"`
+	Sum  string `json:"sum"`
+}
+
+// SumProofStep is a sibling on the path from a leaf to the root. Left reports
+// whether the sibling is the left child.
+type SumProofStep struct {
+	SumNode
+	Left bool `json:"left"`
+}
+
+// SumMerkleTree is a Merkle sum tree for proofs of reserves or liabilities.
+// Every node commits to the sum of the amounts below it, so a proof shows
+// both that a leaf is in the tree and that its amount is counted in the
+// root's total. Pairs are hashed in order as Keccak256(leftHash, leftSum,
+// rightHash, rightSum) with sums as 32-byte big-endian words.
+type SumMerkleTree struct {
+	hashes []Bytes32
+	sums   []*big.Int
+}
+
+// NewSumMerkleTree builds a sum tree from leaves, in the same layout as
+// MakeTree.
+func NewSumMerkleTree(leaves []SumLeaf) (*SumMerkleTree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrEmptyTree
+	}
+	n := 2*len(leaves) - 1
+	t := &SumMerkleTree{hashes: make([]Bytes32, n), sums: make([]*big.Int, n)}
+	for i, leaf := range leaves {
+		if leaf.Amount == nil || leaf.Amount.Sign() < 0 || leaf.Amount.BitLen() > 256 {
+			return nil, &EncodeError{Index: i, Field: -1, Type: "uint256", Err: ErrAbiEncode}
+		}
+		j := n - 1 - i
+		t.hashes[j] = sumLeafHash(leaf)
+		t.sums[j] = new(big.Int).Set(leaf.Amount)
+	}
+	for i := len(leaves) - 2; i >= 0; i-- {
+		l, r := leftChild(i), rightChild(i)
+		sum := new(big.Int).Add(t.sums[l], t.sums[r])
+		if sum.BitLen() > 256 {
+			return nil, ErrSumOverflow
+		}
+		t.hashes[i] = sumNodeHash(t.hashes[l], t.sums[l], t.hashes[r], t.sums[r])
+		t.sums[i] = sum
+	}
+	return t, nil
+}
+
+func (t *SumMerkleTree) Root() string { return t.hashes[0].Hex() }
+func (t *SumMerkleTree) Len() int     { return (len(t.hashes) + 1) / 2 }
+
+// Total returns the sum of all leaf amounts.
+func (t *SumMerkleTree) Total() *big.Int { return new(big.Int).Set(t.sums[0]) }
+
+// GetProof returns the proof for the i-th leaf.
+func (t *SumMerkleTree) GetProof(i int) ([]SumProofStep, error) {
+	if i < 0 || i >= t.Len() {
+		return nil, ErrIndexOutOfBounds
+	}
+	var proof []SumProofStep
+	for j := len(t.hashes) - 1 - i; j > 0; j = parent(j) {
+		s := sibling(j)
+		proof = append(proof, SumProofStep{
+			SumNode: SumNode{Hash: t.hashes[s].Hex(), Sum: t.sums[s].String()},
+			Left:    s%2 == 1,
+		})
+	}
+	return proof, nil
+}
+
+// VerifySum checks that proof links leaf to root and that the sums along the
+// path add up to total, so the leaf's amount is counted in it. A nil total
+// gives ErrInvalidProof.
+func VerifySum(root string, total *big.Int, leaf SumLeaf, proof []SumProofStep) (bool, error) {
+	if total == nil {
+		return false, ErrInvalidProof
+	}
+	if leaf.Amount == nil || leaf.Amount.Sign() < 0 || leaf.Amount.BitLen() > 256 {
+		return false, ErrAbiEncode
+	}
+	want, err := HexToBytes32(root)
+	if err != nil {
+		return false, err
+	}
+	hash, sum := sumLeafHash(leaf), new(big.Int).Set(leaf.Amount)
+	for _, step := range proof {
+		h, err := HexToBytes32(step.Hash)
+		if err != nil {
+			return false, err
+		}
+		s, ok := new(big.Int).SetString(step.Sum, 10)
+		if !ok || s.Sign() < 0 || s.BitLen() > 256 {
+			return false, ErrInvalidProof
+		}
+		if step.Left {
+			hash = sumNodeHash(h, s, hash, sum)
+		} else {
+			hash = sumNodeHash(hash, sum, h, s)
+		}
+		if sum.Add(sum, s).BitLen() > 256 {
+			return false, ErrSumOverflow
+		}
+	}
+	return hash == want && sum.Cmp(total) == 0, nil
+}
+
+func sumLeafHash(leaf SumLeaf) Bytes32 {
+	var buf [64]byte
+	copy(buf[:32], leaf.Data[:])
+	leaf.Amount.FillBytes(buf[32:])
+	return HashLeaf(buf[:])
+}
+
+func sumNodeHash(lh Bytes32, ls *big.Int, rh Bytes32, rs *big.Int) Bytes32 {
+	var buf [128]byte
+	copy(buf[:32], lh[:])
+	ls.FillBytes(buf[32:64])
+	copy(buf[64:96], rh[:])
+	rs.FillBytes(buf[96:])
+	return Keccak256(buf[:])
+}
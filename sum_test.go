@@ -0,0 +1,87 @@
+package gomerk_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func sumLeaves(n int) []gomerk.SumLeaf {
+	leaves := make([]gomerk.SumLeaf, n)
+	for i, d := range simpleLeaves(n) {
+		leaves[i] = gomerk.SumLeaf{Data: d, Amount: big.NewInt(int64(100 * (i + 1)))}
+	}
+	return leaves
+}
+
+func TestSumMerkleTreeProofs(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		leaves := sumLeaves(n)
+		tree, err := gomerk.NewSumMerkleTree(leaves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := big.NewInt(int64(50 * n * (n + 1)))
+		if tree.Total().Cmp(want) != 0 {
+			t.Errorf("n=%d: total = %s, want %s", n, tree.Total(), want)
+		}
+		for i, leaf := range leaves {
+			proof, err := tree.GetProof(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := gomerk.VerifySum(tree.Root(), tree.Total(), leaf, proof); err != nil || !ok {
+				t.Errorf("n=%d leaf %d: got (%v, %v), want (true, nil)", n, i, ok, err)
+			}
+			understated := leaf
+			understated.Amount = big.NewInt(1)
+			if ok, _ := gomerk.VerifySum(tree.Root(), tree.Total(), understated, proof); ok {
+				t.Errorf("n=%d leaf %d: altered amount accepted", n, i)
+			}
+			lower := new(big.Int).Sub(tree.Total(), big.NewInt(1))
+			if ok, _ := gomerk.VerifySum(tree.Root(), lower, leaf, proof); ok {
+				t.Errorf("n=%d leaf %d: wrong total accepted", n, i)
+			}
+		}
+	}
+}
+
+func TestSumMerkleTreeTamperedSibling(t *testing.T) {
+	leaves := sumLeaves(4)
+	tree, _ := gomerk.NewSumMerkleTree(leaves)
+	proof, _ := tree.GetProof(0)
+	proof[0].Sum = "0"
+	if ok, _ := gomerk.VerifySum(tree.Root(), tree.Total(), leaves[0], proof); ok {
+		t.Error("proof with a lowered sibling sum accepted")
+	}
+	proof[0].Sum = "-5"
+	if _, err := gomerk.VerifySum(tree.Root(), tree.Total(), leaves[0], proof); err != gomerk.ErrInvalidProof {
+		t.Errorf("got %v, want ErrInvalidProof", err)
+	}
+	if _, err := gomerk.VerifySum(tree.Root(), nil, leaves[0], proof); err != gomerk.ErrInvalidProof {
+		t.Errorf("nil total: got %v, want ErrInvalidProof", err)
+	}
+}
+
+func TestSumMerkleTreeErrors(t *testing.T) {
+	if _, err := gomerk.NewSumMerkleTree(nil); err != gomerk.ErrEmptyTree {
+		t.Errorf("got %v, want ErrEmptyTree", err)
+	}
+	neg := sumLeaves(2)
+	neg[1].Amount = big.NewInt(-1)
+	if _, err := gomerk.NewSumMerkleTree(neg); !errors.Is(err, gomerk.ErrAbiEncode) {
+		t.Errorf("got %v, want ErrAbiEncode", err)
+	}
+	maxUint := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	over := sumLeaves(2)
+	over[0].Amount = maxUint
+	if _, err := gomerk.NewSumMerkleTree(over); err != gomerk.ErrSumOverflow {
+		t.Errorf("got %v, want ErrSumOverflow", err)
+	}
+	tree, _ := gomerk.NewSumMerkleTree(sumLeaves(3))
+	if _, err := tree.GetProof(3); err != gomerk.ErrIndexOutOfBounds {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+}
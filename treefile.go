@@ -0,0 +1,151 @@
+package gomerk
+
+import (
+	"bufio"
+	"encoding/binary"
+	"os"
+	"sort"
+)
+
+// treeFileMagic starts a tree file. It is followed by the node count as a
+// big-endian uint64 and zero padding up to treeFileHeader bytes, then the
+// nodes in heap order, 32 bytes each.
+const (
+	treeFileMagic  = "GOMERK01"
+	treeFileHeader = 32
+)
+
+// WriteTreeFile writes the tree's nodes to path in the layout read by
+// OpenMmapTree.
+func (t *StandardMerkleTree) WriteTreeFile(path string) error { return writeTreeFile(path, t.tree) }
+
+// WriteTreeFile writes the tree's nodes to path in the layout read by
+// OpenMmapTree.
+func (t *SimpleMerkleTree) WriteTreeFile(path string) error { return writeTreeFile(path, t.tree) }
+
+func writeTreeFile(path string, tree []Bytes32) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	var header [treeFileHeader]byte
+	copy(header[:], treeFileMagic)
+	binary.BigEndian.PutUint64(header[len(treeFileMagic):], uint64(len(tree)))
+	w.Write(header[:])
+	for _, node := range tree {
+		w.Write(node[:])
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MmapTree is a read-only tree served from a file written by WriteTreeFile.
+// The file is memory-mapped where the platform allows, so opening it is cheap
+// and proofs are read straight from the page cache.
+type MmapTree struct {
+	data  []byte
+	nodes []byte
+	close func() error
+}
+
+var _ TreeStore = (*MmapTree)(nil)
+
+// OpenMmapTree opens a tree file written by WriteTreeFile. It returns
+// ErrInvalidFormat if the file is not a well-formed tree file. Call Close to
+// release the mapping.
+func OpenMmapTree(path string) (*MmapTree, error) {
+	data, closeFn, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseTreeFile(data)
+	if err != nil {
+		closeFn()
+		return nil, err
+	}
+	t.close = closeFn
+	return t, nil
+}
+
+func parseTreeFile(data []byte) (*MmapTree, error) {
+	if len(data) < treeFileHeader || string(data[:len(treeFileMagic)]) != treeFileMagic {
+		return nil, ErrInvalidFormat
+	}
+	n := binary.BigEndian.Uint64(data[len(treeFileMagic):])
+	nodes := data[treeFileHeader:]
+	if n == 0 || n%2 == 0 || len(nodes)%32 != 0 || uint64(len(nodes)/32) != n {
+		return nil, ErrInvalidFormat
+	}
+	return &MmapTree{data: data, nodes: nodes}, nil
+}
+
+// Close releases the file. Afterwards the tree reads as empty: accessors
+// return ErrIndexOutOfBounds and RootBytes the zero hash.
+func (t *MmapTree) Close() error {
+	if t.close == nil {
+		return nil
+	}
+	err := t.close()
+	t.close, t.data, t.nodes = nil, nil, nil
+	return err
+}
+
+// Len returns the number of nodes.
+func (t *MmapTree) Len() int { return len(t.nodes) / 32 }
+
+// Leaves returns the number of leaves.
+func (t *MmapTree) Leaves() int { return (t.Len() + 1) / 2 }
+
+func (t *MmapTree) Root() string { return t.RootBytes().Hex() }
+
+// RootBytes returns the root, or the zero hash once the tree is closed.
+func (t *MmapTree) RootBytes() Bytes32 {
+	if t.Len() == 0 {
+		return Bytes32{}
+	}
+	return t.node(0)
+}
+
+func (t *MmapTree) node(i int) Bytes32 { return Bytes32(t.nodes[i*32 : i*32+32]) }
+
+func (t *MmapTree) GetNode(i int) (Bytes32, error) {
+	if i < 0 || i >= t.Len() {
+		return Bytes32{}, ErrIndexOutOfBounds
+	}
+	return t.node(i), nil
+}
+
+// FindLeaf returns the position of the leaf with hash h, as used by
+// GetProofByIndex, by binary search. The tree's leaves must be sorted, as
+// in trees built without WithSortLeaves(false).
+func (t *MmapTree) FindLeaf(h Bytes32) (int, bool) {
+	n := t.Leaves()
+	k, found := sort.Find(n, func(k int) int { return h.Compare(t.node(t.Len() - 1 - k)) })
+	return k, found
+}
+
+// SetNode returns ErrReadOnly.
+func (t *MmapTree) SetNode(int, Bytes32) error { return ErrReadOnly }
+
+// GetProofByIndex returns the proof of the i-th leaf, counting leaves in tree
+// order from the first (tree index Len()-1). For trees built without sorting
+// this is the value index.
+func (t *MmapTree) GetProofByIndex(i int) ([]Bytes32, error) {
+	return t.AppendProof(nil, i)
+}
+
+// AppendProof appends the proof of the i-th leaf, as in GetProofByIndex, to
+// dst. It does not allocate when dst has room for the proof.
+func (t *MmapTree) AppendProof(dst []Bytes32, i int) ([]Bytes32, error) {
+	if i < 0 || i >= t.Leaves() {
+		return dst, ErrIndexOutOfBounds
+	}
+	for index := t.Len() - 1 - i; index > 0; index = parent(index) {
+		dst = append(dst, t.node(sibling(index)))
+	}
+	return dst, nil
+}
@@ -0,0 +1,88 @@
+package gomerk_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestMmapTree(t *testing.T) {
+	tree, err := gomerk.NewStandardMerkleTree(airdropData(9), []string{"address", "uint256"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "tree.bin")
+	if err := tree.WriteTreeFile(path); err != nil {
+		t.Fatal(err)
+	}
+	m, err := gomerk.OpenMmapTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if m.Root() != tree.Root() || m.Leaves() != tree.Len() {
+		t.Fatalf("root %s with %d leaves, want %s with %d", m.Root(), m.Leaves(), tree.Root(), tree.Len())
+	}
+	if err := gomerk.ValidateStore(m); err != nil {
+		t.Fatal(err)
+	}
+	for i := range tree.Len() {
+		got, err := m.GetProofByIndex(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := tree.GetProofBytes(i)
+		if !slices.Equal(got, want) {
+			t.Errorf("proof %d = %v, want %v", i, got, want)
+		}
+	}
+
+	buf := make([]gomerk.Bytes32, 0, 8)
+	if allocs := testing.AllocsPerRun(100, func() { buf, _ = m.AppendProof(buf[:0], 5) }); allocs != 0 {
+		t.Errorf("AppendProof allocates %v times per call", allocs)
+	}
+	if _, err := m.GetProofByIndex(tree.Len()); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("got %v, want ErrIndexOutOfBounds", err)
+	}
+	if err := m.SetNode(0, gomerk.Bytes32{}); !errors.Is(err, gomerk.ErrReadOnly) {
+		t.Errorf("got %v, want ErrReadOnly", err)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !m.RootBytes().IsZero() || m.Leaves() != 0 {
+		t.Errorf("closed tree has root %s and %d leaves", m.Root(), m.Leaves())
+	}
+	if _, err := m.GetProofByIndex(0); !errors.Is(err, gomerk.ErrIndexOutOfBounds) {
+		t.Errorf("closed tree: got %v, want ErrIndexOutOfBounds", err)
+	}
+}
+
+func TestOpenMmapTreeInvalid(t *testing.T) {
+	tree, _ := gomerk.BuildSimpleMerkleTree([]gomerk.Bytes32{{1}, {2}, {3}})
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.bin")
+	if err := tree.WriteTreeFile(good); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(good)
+
+	for name, b := range map[string][]byte{
+		"empty":     nil,
+		"bad magic": append([]byte("NOTMERKL"), data[8:]...),
+		"truncated": data[:len(data)-1],
+		"extra":     append(slices.Clone(data), make([]byte, 32)...),
+	} {
+		path := filepath.Join(dir, "bad.bin")
+		os.WriteFile(path, b, 0o644)
+		if _, err := gomerk.OpenMmapTree(path); !errors.Is(err, gomerk.ErrInvalidFormat) {
+			t.Errorf("%s: got %v, want ErrInvalidFormat", name, err)
+		}
+	}
+}
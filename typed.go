@@ -0,0 +1,234 @@
+package gomerk
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// TypedMerkleTree is a StandardMerkleTree over values of a struct type T,
+// whose leaf encoding is derived from the `abi` tags of T's fields:
+//
+//	type Claim struct {
+//		Account string   `abi:"address"`
+//		Amount  *big.Int `abi:"uint256"`
+//	}
+//
+// Every exported field needs a tag; `abi:"-"` leaves a field out of the leaf.
+// A struct field, or a slice or array of structs, tagged "tuple", "tuple[]"
+// and so on is encoded as a tuple of its own tagged fields.
+type TypedMerkleTree[T any] struct {
+	tree   *StandardMerkleTree
+	codec  typedCodec
+	values []T
+}
+
+// NewTypedMerkleTree creates a TypedMerkleTree configured by opts, sorting
+// leaves unless WithSortLeaves(false) is given. WithSaltedLeaves is not
+// supported, since salted values no longer match T.
+func NewTypedMerkleTree[T any](values []T, opts ...Option) (*TypedMerkleTree[T], error) {
+	o := newOptions(opts)
+	if o.salted {
+		return nil, fmt.Errorf("%w: salted leaves in a typed tree", ErrUnsupportedType)
+	}
+	c, err := typedCodecFor(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]any, len(values))
+	for i, v := range values {
+		rows[i] = c.row(v)
+	}
+	tree, err := BuildStandardMerkleTree(rows, c.encoding, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t := &TypedMerkleTree[T]{tree: tree, codec: c}
+	switch {
+	case !tree.HasValues():
+	case o.dedup:
+		// The tree keeps the first occurrence of each leaf, which is the
+		// next value whose leaf is new.
+		t.values = make([]T, 0, tree.Len())
+		for i, v := range values {
+			if j, _ := tree.IndexOf(rows[i]); j == len(t.values) {
+				t.values = append(t.values, v)
+			}
+		}
+	default:
+		t.values = slices.Clone(values)
+	}
+	return t, nil
+}
+
+// LeafEncodingOf returns the leaf encoding derived from the `abi` tags of T,
+// as used by NewTypedMerkleTree.
+func LeafEncodingOf[T any]() ([]string, error) {
+	c, err := typedCodecFor(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return c.encoding, nil
+}
+
+// Tree returns the underlying StandardMerkleTree.
+func (t *TypedMerkleTree[T]) Tree() *StandardMerkleTree { return t.tree }
+
+func (t *TypedMerkleTree[T]) Root() string           { return t.tree.Root() }
+func (t *TypedMerkleTree[T]) Len() int               { return t.tree.Len() }
+func (t *TypedMerkleTree[T]) LeafEncoding() []string { return t.codec.encoding }
+
+// At returns the value at index i. It reports false if i is out of range or
+// the tree was built WithoutValues.
+func (t *TypedMerkleTree[T]) At(i int) (T, bool) {
+	if i < 0 || i >= len(t.values) {
+		var zero T
+		return zero, false
+	}
+	return t.values[i], true
+}
+
+// IndexOf returns the index of the first value equal to v.
+func (t *TypedMerkleTree[T]) IndexOf(v T) (int, error) { return t.tree.IndexOf(t.codec.row(v)) }
+
+// GetProof returns a proof for v.
+func (t *TypedMerkleTree[T]) GetProof(v T) ([]string, error) { return t.tree.GetProof(t.codec.row(v)) }
+
+// GetProofByIndex returns a proof for the value at index i.
+func (t *TypedMerkleTree[T]) GetProofByIndex(i int) ([]string, error) {
+	return t.tree.GetProofByIndex(i)
+}
+
+// Verify checks a proof for v against the tree's root.
+func (t *TypedMerkleTree[T]) Verify(v T, proof []string) (bool, error) {
+	return t.tree.Verify(t.codec.row(v), proof)
+}
+
+// Dump serializes the tree as a StandardMerkleTree.
+func (t *TypedMerkleTree[T]) Dump() StandardTreeData { return t.tree.Dump() }
+
+// VerifyTyped checks a proof for v against root, encoding v by the `abi`
+// tags of T.
+func VerifyTyped[T any](root string, v T, proof []string) (bool, error) {
+	c, err := typedCodecFor(reflect.TypeFor[T]())
+	if err != nil {
+		return false, err
+	}
+	return VerifyStandard(root, c.encoding, c.row(v), proof)
+}
+
+// typedCodec converts struct values to the []any rows of a leaf encoding.
+type typedCodec struct {
+	encoding []string
+	fields   []int
+}
+
+var bigIntType = reflect.TypeFor[*big.Int]()
+
+func typedCodecFor(typ reflect.Type) (typedCodec, error) {
+	var c typedCodec
+	if typ.Kind() != reflect.Struct {
+		return c, fmt.Errorf("%w: %s is not a struct", ErrUnsupportedType, typ)
+	}
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("abi")
+		if !ok {
+			return c, fmt.Errorf("%w: field %s.%s has no abi tag", ErrUnsupportedType, typ.Name(), f.Name)
+		}
+		if tag == "-" {
+			continue
+		}
+		abiType, err := typedFieldType(f.Type, tag)
+		if err != nil {
+			return c, fmt.Errorf("field %s.%s: %w", typ.Name(), f.Name, err)
+		}
+		c.encoding = append(c.encoding, abiType)
+		c.fields = append(c.fields, i)
+	}
+	if len(c.fields) == 0 {
+		return c, fmt.Errorf("%w: %s has no abi fields", ErrUnsupportedType, typ)
+	}
+	return c, nil
+}
+
+// typedFieldType resolves a field's tag, replacing a leading "tuple" with the
+// tuple type derived from the field's struct type.
+func typedFieldType(typ reflect.Type, tag string) (string, error) {
+	suffix, ok := strings.CutPrefix(tag, "tuple")
+	if !ok || suffix != "" && suffix[0] != '[' {
+		return tag, nil
+	}
+	for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	inner, err := typedCodecFor(typ)
+	if err != nil {
+		return "", err
+	}
+	return "(" + strings.Join(inner.encoding, ",") + ")" + suffix, nil
+}
+
+func (c typedCodec) row(v any) []any {
+	rv := reflect.ValueOf(v)
+	row := make([]any, len(c.fields))
+	for k, i := range c.fields {
+		row[k] = typedValue(rv.Field(i))
+	}
+	return row
+}
+
+// typedValue converts a field value to a form the leaf codec accepts.
+func typedValue(rv reflect.Value) any {
+	if rv.Type() == bigIntType {
+		if rv.IsNil() {
+			return nil
+		}
+		return rv.Interface()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String:
+		return rv.String()
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return typedValue(rv.Elem())
+	case reflect.Struct:
+		c, err := typedCodecFor(rv.Type())
+		if err != nil {
+			return nil
+		}
+		return c.row(rv.Interface())
+	case reflect.Array, reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// Byte arrays such as [20]byte addresses and Bytes32 are
+			// passed as hex, which every byte-like ABI type accepts.
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			if rv.Kind() == reflect.Slice {
+				return b
+			}
+			return "0x" + hex.EncodeToString(b)
+		}
+		elems := make([]any, rv.Len())
+		for i := range elems {
+			elems[i] = typedValue(rv.Index(i))
+		}
+		return elems
+	default:
+		return rv.Interface()
+	}
+}
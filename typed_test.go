@@ -0,0 +1,134 @@
+package gomerk_test
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+type claim struct {
+	Account string   `abi:"address"`
+	Amount  *big.Int `abi:"uint256"`
+	Note    string   `abi:"-"`
+}
+
+func claims(n int) []claim {
+	out := make([]claim, n)
+	for i := range out {
+		out[i] = claim{Account: "0x" + padAddr(i+1), Amount: big.NewInt(int64(i+1) * 100), Note: "x"}
+	}
+	return out
+}
+
+func TestTypedMerkleTree(t *testing.T) {
+	vals := claims(5)
+	tree, err := gomerk.NewTypedMerkleTree(vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc := tree.LeafEncoding(); !slices.Equal(enc, []string{"address", "uint256"}) {
+		t.Fatalf("leaf encoding = %v", enc)
+	}
+	untyped, _ := gomerk.BuildStandardMerkleTree(airdropData(5), []string{"address", "uint256"})
+	if tree.Root() != untyped.Root() {
+		t.Errorf("typed root %s, untyped root %s", tree.Root(), untyped.Root())
+	}
+
+	proof, err := tree.GetProof(vals[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := tree.Verify(vals[2], proof); err != nil || !ok {
+		t.Errorf("Verify: got (%v, %v)", ok, err)
+	}
+	if ok, err := gomerk.VerifyTyped(tree.Root(), vals[2], proof); err != nil || !ok {
+		t.Errorf("VerifyTyped: got (%v, %v)", ok, err)
+	}
+	if v, ok := tree.At(2); !ok || v.Account != vals[2].Account {
+		t.Errorf("At(2) = %v, %v", v, ok)
+	}
+	if _, err := tree.GetProof(claim{Account: vals[0].Account, Amount: big.NewInt(1)}); !errors.Is(err, gomerk.ErrLeafNotInTree) {
+		t.Errorf("got %v, want ErrLeafNotInTree", err)
+	}
+}
+
+func TestTypedMerkleTreeDuplicates(t *testing.T) {
+	c := claims(3)
+	vals := []claim{c[0], c[1], c[0], c[2]}
+	tree, err := gomerk.NewTypedMerkleTree(vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range vals {
+		if v, ok := tree.At(i); !ok || v.Account != want.Account {
+			t.Errorf("At(%d) = %v, %v, want %v", i, v, ok, want)
+		}
+	}
+
+	dedup, _ := gomerk.NewTypedMerkleTree(vals, gomerk.WithDeduplication())
+	if dedup.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", dedup.Len())
+	}
+	for i, want := range []claim{c[0], c[1], c[2]} {
+		if v, ok := dedup.At(i); !ok || v.Account != want.Account {
+			t.Errorf("deduplicated At(%d) = %v, %v, want %v", i, v, ok, want)
+		}
+	}
+}
+
+func TestTypedMerkleTreeNested(t *testing.T) {
+	type order struct {
+		Maker  [20]byte `abi:"address"`
+		Amount uint64   `abi:"uint64"`
+	}
+	type batch struct {
+		ID     gomerk.Bytes32 `abi:"bytes32"`
+		Orders []order        `abi:"tuple[]"`
+		Active bool           `abi:"bool"`
+	}
+	enc, err := gomerk.LeafEncodingOf[batch]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bytes32", "(address,uint64)[]", "bool"}; !slices.Equal(enc, want) {
+		t.Fatalf("leaf encoding = %v, want %v", enc, want)
+	}
+	vals := []batch{
+		{ID: gomerk.Bytes32{1}, Orders: []order{{Maker: [20]byte{1}, Amount: 5}}, Active: true},
+		{ID: gomerk.Bytes32{2}},
+	}
+	tree, err := gomerk.NewTypedMerkleTree(vals, gomerk.WithDeduplication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vals {
+		proof, err := tree.GetProof(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, _ := tree.Verify(v, proof); !ok {
+			t.Errorf("proof for %v does not verify", v)
+		}
+	}
+}
+
+func TestTypedMerkleTreeInvalid(t *testing.T) {
+	type untagged struct {
+		Account string
+	}
+	if _, err := gomerk.NewTypedMerkleTree([]untagged{{"0x" + padAddr(1)}}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("untagged field: got %v, want ErrUnsupportedType", err)
+	}
+	if _, err := gomerk.NewTypedMerkleTree([]int{1}); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("non-struct: got %v, want ErrUnsupportedType", err)
+	}
+	if _, err := gomerk.NewTypedMerkleTree(claims(2), gomerk.WithSaltedLeaves(nil)); !errors.Is(err, gomerk.ErrUnsupportedType) {
+		t.Errorf("salted: got %v, want ErrUnsupportedType", err)
+	}
+	if _, err := gomerk.NewTypedMerkleTree([]claim{{Account: "0x" + padAddr(1)}}); !errors.Is(err, gomerk.ErrAbiEncode) {
+		t.Errorf("nil amount: got %v, want ErrAbiEncode", err)
+	}
+}
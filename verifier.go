@@ -0,0 +1,170 @@
+package gomerk
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/bits"
+	"strings"
+)
+
+// Verifier checks proofs with additional constraints set by options.
+type Verifier struct {
+	opts options
+}
+
+// NewVerifier creates a Verifier configured by opts.
+func NewVerifier(opts ...Option) *Verifier {
+	return &Verifier{opts: newOptions(opts)}
+}
+
+// MaxProofLength returns the longest proof a tree with leafCount leaves can
+// produce, or 0 for an empty tree.
+func MaxProofLength(leafCount int) int {
+	if leafCount <= 0 {
+		return 0
+	}
+	return bits.Len(uint(2*leafCount-1)) - 1
+}
+
+// ProofLengthHistogram returns, for a tree built by MakeTree from leafCount
+// leaves, how many leaves have each proof length. Unless leafCount is a power
+// of two the leaves are split between two adjacent lengths.
+func ProofLengthHistogram(leafCount int) map[int]int {
+	h := make(map[int]int)
+	if leafCount <= 0 {
+		return h
+	}
+	d := MaxProofLength(leafCount)
+	if deep := 2*leafCount - 1<<d; deep > 0 {
+		h[d] = deep
+	}
+	if shallow := 1<<d - leafCount; shallow > 0 {
+		h[d-1] = shallow
+	}
+	return h
+}
+
+// ProcessProof computes the root from a leaf and proof.
+func (v *Verifier) ProcessProof(leaf Bytes32, proof []string) (string, error) {
+	return v.processProof(v.opts.hash(), leaf, proof)
+}
+
+func (v *Verifier) processProof(h Hasher, leaf Bytes32, proof []string) (string, error) {
+	if v.opts.maxProofLength > 0 && len(proof) > v.opts.maxProofLength {
+		return "", ErrProofTooLong
+	}
+	return processProof(h, leaf, proof)
+}
+
+// Verify checks that proof links leafHash to root.
+func (v *Verifier) Verify(root string, leafHash Bytes32, proof []string) (bool, error) {
+	return v.verify(v.opts.hash(), root, leafHash, proof)
+}
+
+func (v *Verifier) verify(h Hasher, root string, leafHash Bytes32, proof []string) (bool, error) {
+	r, err := v.processProof(h, leafHash, proof)
+	if err != nil {
+		return false, err
+	}
+	return v.rootMatches(r, root), nil
+}
+
+// ProcessProofWithPath computes the root from a leaf, proof and direction
+// bits with the verifier's hasher.
+func (v *Verifier) ProcessProofWithPath(leaf Bytes32, proof []string, path []bool) (string, error) {
+	if v.opts.maxProofLength > 0 && len(proof) > v.opts.maxProofLength {
+		return "", ErrProofTooLong
+	}
+	return processProofWithPath(v.opts.hash(), leaf, proof, path)
+}
+
+// VerifyWithPath checks that proof and its direction bits link leafHash to
+// root.
+func (v *Verifier) VerifyWithPath(root string, leafHash Bytes32, proof []string, path []bool) (bool, error) {
+	r, err := v.ProcessProofWithPath(leafHash, proof, path)
+	if err != nil {
+		return false, err
+	}
+	return v.rootMatches(r, root), nil
+}
+
+// rootMatches compares a computed root with the expected one, honoring
+// WithTruncatedRoot. An expected truncated root may be given in full or as
+// just its prefix.
+func (v *Verifier) rootMatches(computed, root string) bool {
+	n := v.opts.truncateRoot
+	if n <= 0 || n >= 32 {
+		return computed == root
+	}
+	want, err := hex.DecodeString(strings.TrimPrefix(root, "0x"))
+	if err != nil || len(want) < n {
+		return false
+	}
+	got, err := HexToBytes32(computed)
+	return err == nil && bytes.Equal(got[:n], want[:n])
+}
+
+// VerifySimple checks a proof for a SimpleMerkleTree leaf.
+func (v *Verifier) VerifySimple(root string, leaf Bytes32, proof []string) (bool, error) {
+	return v.Verify(root, v.opts.hash().LeafHash(leaf[:]), proof)
+}
+
+// VerifyStandard checks a proof for a StandardMerkleTree leaf, encoding and
+// hashing it with the verifier's number encoding and hasher.
+func (v *Verifier) VerifyStandard(root string, leafEncoding []string, leaf []any, proof []string) (bool, error) {
+	h, err := v.opts.codec().encodeAndHash(leafEncoding, leaf)
+	if err != nil {
+		return false, err
+	}
+	return v.Verify(root, h, proof)
+}
+
+// VerifySimpleTree checks a proof for leaf against t with the tree's hasher,
+// applying strict proof ordering if configured.
+func (v *Verifier) VerifySimpleTree(t *SimpleMerkleTree, leaf Bytes32, proof []string) (bool, error) {
+	if v.opts.strictOrder {
+		canonical, err := t.GetProof(leaf)
+		if ok, err := checkCanonical(canonical, err, proof); !ok || err != nil {
+			return ok, err
+		}
+	}
+	h := t.opts.hash()
+	return v.verify(h, t.Root(), h.LeafHash(leaf[:]), proof)
+}
+
+// VerifyStandardTree checks a proof for leaf against t with the tree's
+// encoding and hasher, applying strict proof ordering if configured.
+func (v *Verifier) VerifyStandardTree(t *StandardMerkleTree, leaf []any, proof []string) (bool, error) {
+	if v.opts.strictOrder {
+		canonical, err := t.GetProof(leaf)
+		if ok, err := checkCanonical(canonical, err, proof); !ok || err != nil {
+			return ok, err
+		}
+	}
+	h, err := t.opts.codec().encodeAndHash(t.leafEncoding, leaf)
+	if err != nil {
+		return false, err
+	}
+	return v.verify(t.opts.hash(), t.Root(), h, proof)
+}
+
+// checkCanonical compares proof with the canonical proof for its leaf. A leaf
+// missing from the tree fails verification without an error.
+func checkCanonical(canonical []string, err error, proof []string) (bool, error) {
+	if errors.Is(err, ErrLeafNotInTree) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(proof) != len(canonical) {
+		return false, ErrNonCanonicalProof
+	}
+	for i := range proof {
+		if !RootsEqual(proof[i], canonical[i]) {
+			return false, ErrNonCanonicalProof
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,168 @@
+package gomerk_test
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pyroth/gomerk"
+)
+
+func TestMaxProofLength(t *testing.T) {
+	tests := []struct{ leaves, want int }{
+		{0, 0}, {1, 0}, {2, 1}, {3, 2}, {4, 2}, {5, 3}, {8, 3}, {9, 4},
+	}
+	for _, tc := range tests {
+		if got := gomerk.MaxProofLength(tc.leaves); got != tc.want {
+			t.Errorf("MaxProofLength(%d) = %d, want %d", tc.leaves, got, tc.want)
+		}
+	}
+
+	for n := 1; n <= 33; n++ {
+		tree, _ := gomerk.MakeTree(testLeaves(n))
+		longest := 0
+		for i := range gomerk.TreeLeaves(tree) {
+			proof, _ := gomerk.GetProof(tree, i)
+			longest = max(longest, len(proof))
+		}
+		if longest != gomerk.MaxProofLength(n) {
+			t.Errorf("n=%d: longest proof %d, MaxProofLength %d", n, longest, gomerk.MaxProofLength(n))
+		}
+	}
+}
+
+func TestVerifierMaxProofLength(t *testing.T) {
+	vals := airdropData(5)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	v := gomerk.NewVerifier(gomerk.WithMaxProofLength(gomerk.MaxProofLength(tree.Len())))
+
+	for _, val := range vals {
+		proof, _ := tree.GetProof(val)
+		ok, err := v.VerifyStandard(tree.Root(), enc, val, proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Error("verify failed")
+		}
+
+		padded := append(proof, tree.Root(), tree.Root(), tree.Root())
+		if _, err := v.VerifyStandard(tree.Root(), enc, val, padded); err != gomerk.ErrProofTooLong {
+			t.Errorf("got %v, want ErrProofTooLong", err)
+		}
+	}
+}
+
+func TestVerifierDefault(t *testing.T) {
+	leaves := simpleLeaves(4)
+	tree, _ := gomerk.NewSimpleMerkleTree(leaves, true)
+	proof, _ := tree.GetProof(leaves[0])
+
+	ok, err := gomerk.NewVerifier().VerifySimple(tree.Root(), leaves[0], proof)
+	if err != nil || !ok {
+		t.Errorf("got (%v, %v), want (true, nil)", ok, err)
+	}
+}
+
+func TestVerifierStrictProofOrder(t *testing.T) {
+	vals := airdropData(8)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	strict := gomerk.NewVerifier(gomerk.WithStrictProofOrder())
+	lenient := gomerk.NewVerifier()
+
+	proof, _ := tree.GetProof(vals[3])
+	for _, v := range []*gomerk.Verifier{strict, lenient} {
+		ok, err := v.VerifyStandardTree(tree, vals[3], proof)
+		if err != nil || !ok {
+			t.Errorf("canonical proof: got (%v, %v), want (true, nil)", ok, err)
+		}
+	}
+
+	upper := slices.Clone(proof)
+	upper[0] = "0x" + strings.ToUpper(upper[0][2:])
+	if ok, err := strict.VerifyStandardTree(tree, vals[3], upper); err != nil || !ok {
+		t.Errorf("hex case should not affect canonical check: got (%v, %v)", ok, err)
+	}
+
+	reordered := slices.Clone(proof)
+	reordered[0], reordered[1] = reordered[1], reordered[0]
+	if _, err := strict.VerifyStandardTree(tree, vals[3], reordered); err != gomerk.ErrNonCanonicalProof {
+		t.Errorf("got %v, want ErrNonCanonicalProof", err)
+	}
+	if ok, err := lenient.VerifyStandardTree(tree, vals[3], reordered); err != nil || ok {
+		t.Errorf("lenient: got (%v, %v), want (false, nil)", ok, err)
+	}
+
+	missing := []any{"0x9999999999999999999999999999999999999999", 1}
+	if ok, err := strict.VerifyStandardTree(tree, missing, proof); err != nil || ok {
+		t.Errorf("missing leaf: got (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestVerifierStrictProofOrderSimple(t *testing.T) {
+	leaves := simpleLeaves(5)
+	tree, _ := gomerk.NewSimpleMerkleTree(leaves, true)
+	strict := gomerk.NewVerifier(gomerk.WithStrictProofOrder())
+
+	proof, _ := tree.GetProof(leaves[0])
+	if ok, err := strict.VerifySimpleTree(tree, leaves[0], proof); err != nil || !ok {
+		t.Errorf("got (%v, %v), want (true, nil)", ok, err)
+	}
+	if _, err := strict.VerifySimpleTree(tree, leaves[0], append(proof, proof[0])); err != gomerk.ErrNonCanonicalProof {
+		t.Errorf("got %v, want ErrNonCanonicalProof", err)
+	}
+}
+
+func TestVerifierTruncatedRoot(t *testing.T) {
+	vals := airdropData(6)
+	enc := []string{"address", "uint256"}
+	tree, _ := gomerk.NewStandardMerkleTree(vals, enc, true)
+	proof, _ := tree.GetProof(vals[2])
+
+	short := tree.RootTruncated(20)
+	if len(short) != 42 || !strings.HasPrefix(tree.Root(), short) {
+		t.Fatalf("RootTruncated(20) = %s", short)
+	}
+	if tree.RootTruncated(99) != tree.Root() || tree.RootTruncated(-1) != "0x" {
+		t.Error("RootTruncated should clamp its argument")
+	}
+
+	v := gomerk.NewVerifier(gomerk.WithTruncatedRoot(20))
+	for _, root := range []string{short, tree.Root()} {
+		if ok, err := v.VerifyStandard(root, enc, vals[2], proof); err != nil || !ok {
+			t.Errorf("root %s: got (%v, %v), want (true, nil)", root, ok, err)
+		}
+	}
+	if ok, _ := gomerk.NewVerifier().VerifyStandard(short, enc, vals[2], proof); ok {
+		t.Error("default verifier should require the full root")
+	}
+	if ok, _ := v.VerifyStandard(short[:40], enc, vals[2], proof); ok {
+		t.Error("root shorter than the truncation length should not match")
+	}
+	other, _ := gomerk.NewStandardMerkleTree(vals[:5], enc, true)
+	if ok, _ := v.VerifyStandard(other.RootTruncated(20), enc, vals[2], proof); ok {
+		t.Error("proof accepted for another root")
+	}
+}
+
+func TestProofLengthHistogram(t *testing.T) {
+	for n := 1; n <= 70; n++ {
+		tree, _ := gomerk.MakeTree(testLeaves(n))
+		want := make(map[int]int)
+		for i := range tree {
+			if i >= n-1 {
+				proof, _ := gomerk.GetProof(tree, i)
+				want[len(proof)]++
+			}
+		}
+		if got := gomerk.ProofLengthHistogram(n); !maps.Equal(got, want) {
+			t.Errorf("n=%d: got %v, want %v", n, got, want)
+		}
+	}
+	if h := gomerk.ProofLengthHistogram(0); len(h) != 0 {
+		t.Errorf("empty tree: got %v", h)
+	}
+}